@@ -14,6 +14,7 @@ import (
 	_ "github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/docs/swagger"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/servertls"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
 )
 
@@ -25,6 +26,11 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
+// @description Bearer <access token>, or ApiKey <API key>
+// @securityDefinitions.apikey APIKeyHeader
+// @in header
+// @name X-API-Key
+// @description API key issued via /api-keys
 func main() {
 	if err := run(); err != nil {
 		os.Exit(1)
@@ -53,15 +59,24 @@ func run() error {
 		return err
 	}
 
-	router, err := InitializeRouter(cfg, logger, metricsManager)
+	// cleanup はバックグラウンドで動く依存（鍵の定期取得など）を停止します
+	router, cleanup, err := InitializeRouter(cfg, logger, metricsManager)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 	h := router.Setup()
 
+	tlsConfig, stopTLSReload, err := servertls.NewConfig(cfg, logger)
+	if err != nil {
+		logger.Error("Failed to initialize TLS", "error", err)
+		return err
+	}
+	defer stopTLSReload()
 	srv := &http.Server{
-		Addr:    cfg.ServerAddress,
-		Handler: h,
+		Addr:      cfg.ServerAddress,
+		Handler:   h,
+		TLSConfig: tlsConfig,
 	}
 
 	// シグナルを受け取るためのコンテキストを設定
@@ -70,8 +85,15 @@ func run() error {
 
 	// サーバーをゴルーチンで起動
 	go func() {
-		logger.Info("Server started", slog.String("address", cfg.ServerAddress))
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Info("Server started", slog.String("address", cfg.ServerAddress), slog.Bool("tls", tlsConfig != nil), slog.String("client_auth", cfg.TLSClientAuth))
+		var err error
+		if tlsConfig != nil {
+			// 証明書は TLSConfig から取得する
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Error("Server listen failed", slog.String("error", err.Error()))
 		}
 	}()
@@ -90,10 +112,14 @@ func run() error {
 		logger.Error("Server forced to shutdown", slog.String("error", err.Error()))
 		return err
 	}
-	// 2. tracer
+	// 2. バックグラウンドの処理
+	stopTLSReload()
+	cleanup()
+
+	// 3. tracer
 	ddTracer.Stop()
 
-	// 3. metrics
+	// 4. metrics
 	metricsManager.Stop()
 
 	logger.Info("Server exited properly")
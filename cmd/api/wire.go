@@ -9,20 +9,32 @@ import (
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/routes"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/routes/v1"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/datasource"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/mailer"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/oidc"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/policy"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/database"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
 	"github.com/google/wire"
 )
 
-func InitializeRouter(cfg *config.AppConfig, logger logger.Logger, metricsManager *datadog.MetricsManager) (*routes.Router, error) {
+func InitializeRouter(cfg *config.AppConfig, logger logger.Logger, metricsManager *datadog.MetricsManager) (*routes.Router, func(), error) {
 	wire.Build(
 		presenter.Set,
 		custommiddleware.Set,
 		piyographql.Set,
+		database.Set,
+		repository.Set,
+		datasource.Set,
+		mailer.Set,
+		oidc.Set,
+		policy.Set,
 		services.Set,
 		usecases.Set,
 		handlers.Set,
@@ -30,5 +42,5 @@ func InitializeRouter(cfg *config.AppConfig, logger logger.Logger, metricsManage
 		//telemetry.Set,
 		routes.Set,
 	)
-	return nil, nil
+	return nil, nil, nil
 }
@@ -12,10 +12,16 @@ import (
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/routes"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/routes/v1"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/datasource"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/mailer"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/oidc"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/policy"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/database"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
 )
@@ -26,23 +32,150 @@ import (
 
 // Injectors from wire.go:
 
-func InitializeRouter(cfg *config.AppConfig, logger2 logger.Logger, metricsManager *datadog.MetricsManager) (*routes.Router, error) {
+func InitializeRouter(cfg *config.AppConfig, logger2 logger.Logger, metricsManager *datadog.MetricsManager) (*routes.Router, func(), error) {
 	ddTracer := custommiddleware.NewDDTracer(logger2)
 	ddMetrics := custommiddleware.NewMetrics(logger2, metricsManager)
 	jsonWriter := presenter.NewJSONWriter(logger2)
 	errorHandling := custommiddleware.NewErrorHandling(logger2, jsonWriter)
 	timeout := custommiddleware.NewTimeout(logger2, cfg)
-	tokenService := services.NewTokenService(cfg)
-	authUsecase := usecases.NewAuthUsecase(tokenService)
-	authentication := custommiddleware.NewAuthentication(logger2, jsonWriter, authUsecase)
-	healthcheckHandler := handlers.NewHealthcheckHandler(logger2, jsonWriter)
+	realIP, err := custommiddleware.NewRealIP(cfg, logger2)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyRing, err := services.NewKeyRing(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokenService := services.NewTokenService(cfg, keyRing)
+	passwordHasher := services.NewPasswordHasher()
+	db, cleanup, err := database.NewDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	userRepository, err := repository.NewUserRepository(cfg, db, passwordHasher)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	refreshTokenService := services.NewRefreshTokenService(cfg)
+	refreshTokenRepository, err := repository.NewRefreshTokenRepository(cfg, db)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	tokenRevocationRepository, err := repository.NewTokenRevocationRepository(cfg, db)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	verifier, cleanup2, err := oidc.NewVerifier(cfg, logger2)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	loginAttemptRepository, err := repository.NewLoginAttemptRepository(cfg, db)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	loginThrottle := usecases.NewLoginThrottle(cfg, logger2, loginAttemptRepository)
+	mfaRepository, err := repository.NewMFARepository(cfg, db)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	totpService := services.NewTOTPService(cfg)
+	secretBox, err := services.NewSecretBox(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	authUsecase, err := usecases.NewAuthUsecase(cfg, logger2, tokenService, keyRing, passwordHasher, userRepository, refreshTokenService, refreshTokenRepository, tokenRevocationRepository, verifier, loginThrottle, mfaRepository, totpService, secretBox)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	apiKeyService := services.NewAPIKeyService()
+	authorizer := services.NewAuthorizer(cfg)
+	apiKeyRepository, err := repository.NewAPIKeyRepository(cfg, db)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	apiKeyUsecase := usecases.NewAPIKeyUsecase(logger2, apiKeyService, authorizer, apiKeyRepository, userRepository)
+	authentication := custommiddleware.NewAuthentication(logger2, jsonWriter, authUsecase, apiKeyUsecase)
+	clientCertificateMapper, err := services.NewClientCertificateMapper(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	clientCertificate := custommiddleware.NewClientCertificate(logger2, clientCertificateMapper)
+	circuitBreaker := piyographql.NewCircuitBreaker(cfg, logger2, metricsManager)
+	client := piyographql.NewClient(logger2, cfg, metricsManager, circuitBreaker)
+	loaderFactory := piyographql.NewLoaderFactory(cfg, client)
+	sampleLoader := custommiddleware.NewSampleLoader(loaderFactory)
+	healthcheckUsecase := usecases.NewHealthcheckUsecase(circuitBreaker)
+	healthcheckHandler := handlers.NewHealthcheckHandler(logger2, jsonWriter, healthcheckUsecase)
 	healthcheckRouter := v1.NewHealthcheckRouter(healthcheckHandler)
 	authHandler := handlers.NewAuthHandler(logger2, jsonWriter, authUsecase)
-	authRouter := v1.NewAuthRouter(authHandler)
-	client := piyographql.NewClient(logger2)
-	sampleUsecase := usecases.NewSampleUsecase(logger2, client)
+	accountTokenService := services.NewAccountTokenService(cfg)
+	accountTokenRepository, err := repository.NewAccountTokenRepository(cfg, db)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	mailerMailer, err := mailer.NewMailer(cfg, logger2)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	accountUsecase := usecases.NewAccountUsecase(cfg, logger2, passwordHasher, accountTokenService, userRepository, accountTokenRepository, refreshTokenRepository, tokenRevocationRepository, loginThrottle, mailerMailer)
+	accountHandler := handlers.NewAccountHandler(logger2, jsonWriter, accountUsecase)
+	authRouter := v1.NewAuthRouter(authHandler, accountHandler)
+	sampleRepository := repository.NewSampleRepository()
+	sampleDataSource, err := datasource.NewSampleDataSource(cfg, logger2, client, sampleRepository)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	engine, cleanup3, err := policy.NewEngine(cfg, logger2)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	sampleUsecase := usecases.NewSampleUsecase(logger2, sampleDataSource, engine)
 	sampleHandler := handlers.NewSampleHandler(logger2, jsonWriter, sampleUsecase)
-	sampleRouter := v1.NewSampleRouter(sampleHandler)
-	router := routes.NewRouter(cfg, ddTracer, ddMetrics, errorHandling, timeout, authentication, healthcheckRouter, authRouter, sampleRouter)
-	return router, nil
+	authorization := custommiddleware.NewAuthorization(logger2, authorizer)
+	sampleRouter := v1.NewSampleRouter(sampleHandler, authorization)
+	adminRouter := v1.NewAdminRouter(authHandler, authorization)
+	apiKeyHandler := handlers.NewAPIKeyHandler(logger2, jsonWriter, apiKeyUsecase)
+	apiKeyRouter := v1.NewAPIKeyRouter(apiKeyHandler, authorization)
+	mfaRouter := v1.NewMFARouter(authHandler)
+	wellKnownRouter := v1.NewWellKnownRouter(authHandler)
+	oAuthClientRepository, err := repository.NewOAuthClientRepository(cfg, db, passwordHasher)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	oAuthUsecase := usecases.NewOAuthUsecase(cfg, logger2, tokenService, passwordHasher, oAuthClientRepository)
+	oAuthHandler := handlers.NewOAuthHandler(logger2, jsonWriter, oAuthUsecase)
+	oAuthRouter := v1.NewOAuthRouter(oAuthHandler)
+	router := routes.NewRouter(cfg, ddTracer, ddMetrics, errorHandling, timeout, realIP, authentication, clientCertificate, sampleLoader, healthcheckRouter, authRouter, sampleRouter, adminRouter, apiKeyRouter, mfaRouter, wellKnownRouter, oAuthRouter)
+	return router, func() {
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
 }
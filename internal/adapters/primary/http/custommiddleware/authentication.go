@@ -6,9 +6,13 @@ import (
 	"strings"
 
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/common/contextkeys"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 var UserKey = struct{}{}
@@ -20,21 +24,26 @@ var excludedPaths = []string{
 	"/docs/swagger/",
 }
 
+// Authentication は Bearer トークンまたは API キー（X-API-Key ヘッダーか Authorization: ApiKey）で認証します
+// どちらもない場合は、ClientCertificate がクライアント証明書から対応付けたプリンシパルを受け付けます
 type Authentication struct {
-	logger      logger.Logger
-	JSONWriter  *presenter.JSONWriter
-	authUsecase usecases.AuthUsecase
+	logger        logger.Logger
+	JSONWriter    *presenter.JSONWriter
+	authUsecase   usecases.AuthUsecase
+	apiKeyUsecase usecases.APIKeyUsecase
 }
 
 func NewAuthentication(
 	logger logger.Logger,
 	JSONWriter *presenter.JSONWriter,
 	authUsecase usecases.AuthUsecase,
+	apiKeyUsecase usecases.APIKeyUsecase,
 ) *Authentication {
 	return &Authentication{
-		logger:      logger,
-		JSONWriter:  JSONWriter,
-		authUsecase: authUsecase,
+		logger:        logger,
+		JSONWriter:    JSONWriter,
+		authUsecase:   authUsecase,
+		apiKeyUsecase: apiKeyUsecase,
 	}
 }
 
@@ -51,9 +60,27 @@ func (h *Authentication) Handle() Middleware {
 				}
 			}
 
+			// API キー
+			if apiKey, ok := apiKeyFromRequest(r); ok {
+				user, err := h.apiKeyUsecase.Authenticate(r.Context(), apiKey)
+				if err != nil {
+					h.logger.WarnContext(r.Context(), "API key authentication failed", "error", err)
+					rw.WriteError(apperrors.NewUnauthorizedError("Invalid API key", nil))
+					return
+				}
+				h.serveAuthenticated(rw, r, next, user)
+				return
+			}
+
 			// 認証方法は適宜変更する
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
+				// mTLS のクライアント証明書
+				if principal, ok := r.Context().Value(contextkeys.ClientCertificatePrincipalKey).(*models.User); ok {
+					h.serveAuthenticated(rw, r, next, principal)
+					return
+				}
+
 				h.logger.ErrorContext(r.Context(), "Missing authorization header")
 				rw.WriteError(apperrors.NewUnauthorizedError("Missing authorization header", nil))
 				return
@@ -68,15 +95,32 @@ func (h *Authentication) Handle() Middleware {
 			tokenString := authHeader[7:]
 			user, err := h.authUsecase.Authenticate(r.Context(), tokenString)
 			if err != nil {
-				h.logger.ErrorContext(r.Context(), "Token validation failed", "error", err)
+				// 失敗理由はログと span にのみ残し、クライアントには区別できない 401 を返す
+				reason := services.TokenFailureReason(err)
+				if span, ok := tracer.SpanFromContext(r.Context()); ok {
+					span.SetTag("auth.failure_reason", reason)
+				}
+				h.logger.WarnContext(r.Context(), "Token validation failed", "reason", reason, "error", err)
 				rw.WriteError(apperrors.NewUnauthorizedError("Invalid or expired token", nil))
 				return
 			}
 
-			// nolint:staticcheck
-			ctx := context.WithValue(r.Context(), UserKey, user)
-			h.logger.InfoContext(r.Context(), "User authenticated")
-			next.ServeHTTP(rw, r.WithContext(ctx))
+			h.serveAuthenticated(rw, r, next, user)
 		})
 	}
 }
+
+func (h *Authentication) serveAuthenticated(w http.ResponseWriter, r *http.Request, next http.Handler, user *models.User) {
+	// nolint:staticcheck
+	ctx := context.WithValue(r.Context(), UserKey, user)
+	h.logger.InfoContext(r.Context(), "User authenticated")
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// apiKeyFromRequest は X-API-Key ヘッダーまたは Authorization: ApiKey <key> から API キーを取り出します
+func apiKeyFromRequest(r *http.Request) (string, bool) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key, true
+	}
+	return strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey ")
+}
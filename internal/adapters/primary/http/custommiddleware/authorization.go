@@ -0,0 +1,74 @@
+package custommiddleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// Authorization はルートごとに必要な操作の許可を検証します
+// Authentication の後に置き、拒否した場合は監査ログを残して 403 を返します
+type Authorization struct {
+	logger     logger.Logger
+	authorizer services.Authorizer
+}
+
+func NewAuthorization(
+	logger logger.Logger,
+	authorizer services.Authorizer,
+) *Authorization {
+	return &Authorization{
+		logger:     logger.With("log_type", "audit"),
+		authorizer: authorizer,
+	}
+}
+
+// RequirePermission は permission（samples:write など）を持たないユーザーのリクエストを拒否します
+func (h *Authorization) RequirePermission(permission string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := presenter.GetWrapResponseWriter(w)
+
+			user, _ := r.Context().Value(UserKey).(*models.User)
+			if err := h.authorizer.Authorize(user, permission); err != nil {
+				args := []any{"permission", permission}
+				if user != nil {
+					args = append(args, "subject", user.ID, "roles", user.Roles)
+				}
+				h.logger.WarnContext(r.Context(), "Authorization denied", args...)
+				rw.WriteError(err)
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// RequireScope はスコープで制限されたトークンや API キーのうち、scope を含まないもののリクエストを拒否します
+// ロールの権限は検証しないため、必要に応じて RequirePermission と組み合わせます
+func (h *Authorization) RequireScope(scope string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := presenter.GetWrapResponseWriter(w)
+
+			user, _ := r.Context().Value(UserKey).(*models.User)
+			if !h.authorizer.HasScope(user, scope) {
+				args := []any{"scope", scope}
+				if user != nil {
+					args = append(args, "subject", user.ID, "scopes", user.Scopes)
+				}
+				h.logger.WarnContext(r.Context(), "Insufficient scope", args...)
+				rw.WriteError(apperrors.NewForbiddenError(fmt.Sprintf("Scope %s required", scope), nil))
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
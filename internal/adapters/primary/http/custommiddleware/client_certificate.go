@@ -0,0 +1,52 @@
+package custommiddleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/common/contextkeys"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// ClientCertificate は mTLS で検証済みのクライアント証明書をプリンシパルに対応付けてコンテキストに設定します
+// 認証は行わず、Authentication がトークンや API キーのないリクエストの資格情報として扱います
+type ClientCertificate struct {
+	logger logger.Logger
+	mapper services.ClientCertificateMapper
+}
+
+func NewClientCertificate(
+	logger logger.Logger,
+	mapper services.ClientCertificateMapper,
+) *ClientCertificate {
+	return &ClientCertificate{
+		logger: logger.With("log_type", "audit"),
+		mapper: mapper,
+	}
+}
+
+func (h *ClientCertificate) Handle() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// VerifiedChains は CA バンドルで検証できた場合のみ設定される
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cert := r.TLS.VerifiedChains[0][0]
+			principal, err := h.mapper.Map(cert)
+			if err != nil {
+				h.logger.WarnContext(r.Context(), "Client certificate not mapped to a principal",
+					"subject", cert.Subject.String(), "serial", cert.SerialNumber.String())
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// nolint:staticcheck
+			ctx := context.WithValue(r.Context(), contextkeys.ClientCertificatePrincipalKey, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"runtime/debug"
+	"strconv"
 
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/response"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
@@ -105,6 +107,9 @@ func (h *ErrorHandling) handleError(ctx context.Context, rw *presenter.WrapRespo
 			RequestID:  requestID,
 			Message:    e.Message,
 		}
+		if e.RetryAfter > 0 {
+			rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+		}
 
 	default:
 		statusCode = http.StatusInternalServerError
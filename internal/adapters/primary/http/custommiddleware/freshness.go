@@ -0,0 +1,17 @@
+package custommiddleware
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/common/freshness"
+)
+
+// Freshness はレスポンスに含まれるデータの鮮度を記録するためのレコーダーをコンテキストに設定します
+// Loader などリクエストのコンテキストを保持するミドルウェアより前に設定してください
+func Freshness() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(freshness.WithRecorder(r.Context())))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package custommiddleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// RealIP はプロキシを経由したリクエストの RemoteAddr をクライアントの IP アドレスに置き換えます
+// X-Forwarded-For はクライアントが自由に設定できるため、接続元が trusted_proxies のプロキシである場合のみ参照します
+// 右から順に信頼するプロキシのアドレスを除き、最初に現れたアドレスをクライアントとします
+type RealIP struct {
+	trustedProxies []netip.Prefix
+}
+
+func NewRealIP(cfg *config.AppConfig, logger logger.Logger) (*RealIP, error) {
+	prefixes := make([]netip.Prefix, 0, len(cfg.TrustedProxies))
+	for _, proxy := range cfg.TrustedProxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted_proxies entry %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	if len(prefixes) == 0 {
+		// ロードバランサーの背後では全リクエストの接続元が同じになり、IP アドレスごとのログイン制限を全員で共有してしまう
+		logger.Warn("No trusted proxies configured; X-Forwarded-For is ignored and the connecting peer is used as the client IP",
+			"hint", "set TRUSTED_PROXIES when running behind a load balancer or reverse proxy")
+	}
+	return &RealIP{trustedProxies: prefixes}, nil
+}
+
+func (h *RealIP) Handle() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := h.clientIP(r); ok {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP は信頼するプロキシから転送されたリクエストのクライアントの IP アドレスを返します
+func (h *RealIP) clientIP(r *http.Request) (netip.Addr, bool) {
+	peer, ok := parseIP(r.RemoteAddr)
+	if !ok || !h.trusted(peer) {
+		return netip.Addr{}, false
+	}
+
+	// 複数のヘッダーに分かれている場合も 1 つのリストとして扱う
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseIP(strings.TrimSpace(hops[i]))
+		if !ok {
+			// 形式が不正なアドレスより左はクライアントが設定した値として扱わない
+			return netip.Addr{}, false
+		}
+		if !h.trusted(ip) {
+			return ip, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+func (h *RealIP) trusted(ip netip.Addr) bool {
+	for _, prefix := range h.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP は "host:port" と "host" のどちらの形式も受け付けます
+func parseIP(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
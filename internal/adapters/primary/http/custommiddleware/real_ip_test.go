@@ -0,0 +1,83 @@
+package custommiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	target, err := NewRealIP(&config.AppConfig{TrustedProxies: []string{"10.0.0.0/8", "2001:db8::/32"}}, logger.NewLogger(&config.AppConfig{}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		xForwardedFor []string
+		want          string
+	}{
+		{
+			name:          "正常系: 信頼するプロキシからのリクエストは X-Forwarded-For のクライアントを使う",
+			remoteAddr:    "10.0.0.1:443",
+			xForwardedFor: []string{"192.0.2.1"},
+			want:          "192.0.2.1",
+		},
+		{
+			name:          "正常系: クライアントが付けた値より右にある、信頼しない最初のアドレスを使う",
+			remoteAddr:    "10.0.0.1:443",
+			xForwardedFor: []string{"198.51.100.1, 192.0.2.1", "10.0.0.2"},
+			want:          "192.0.2.1",
+		},
+		{
+			name:          "正常系: IPv6 のプロキシも信頼する",
+			remoteAddr:    "[2001:db8::1]:443",
+			xForwardedFor: []string{"192.0.2.1"},
+			want:          "192.0.2.1",
+		},
+		{
+			name:          "異常系: 信頼しない接続元の X-Forwarded-For は無視する",
+			remoteAddr:    "192.0.2.1:1234",
+			xForwardedFor: []string{"198.51.100.1"},
+			want:          "192.0.2.1:1234",
+		},
+		{
+			name:          "異常系: 形式が不正なアドレスがある場合は接続元を使う",
+			remoteAddr:    "10.0.0.1:443",
+			xForwardedFor: []string{"192.0.2.1, unknown"},
+			want:          "10.0.0.1:443",
+		},
+		{
+			name:          "異常系: すべて信頼するプロキシの場合は接続元を使う",
+			remoteAddr:    "10.0.0.1:443",
+			xForwardedFor: []string{"10.0.0.2"},
+			want:          "10.0.0.1:443",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xForwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			var got string
+			handler := target.Handle()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("異常系: CIDR の形式が不正な場合はエラー", func(t *testing.T) {
+		_, err := NewRealIP(&config.AppConfig{TrustedProxies: []string{"10.0.0.1"}}, logger.NewLogger(&config.AppConfig{}))
+		assert.Error(t, err)
+	})
+}
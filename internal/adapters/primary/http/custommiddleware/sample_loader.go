@@ -0,0 +1,27 @@
+package custommiddleware
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+)
+
+// SampleLoader はリクエストごとに sample の Loader をコンテキストに設定します
+type SampleLoader struct {
+	loaderFactory *piyographql.LoaderFactory
+}
+
+func NewSampleLoader(loaderFactory *piyographql.LoaderFactory) *SampleLoader {
+	return &SampleLoader{
+		loaderFactory: loaderFactory,
+	}
+}
+
+func (h *SampleLoader) Handle() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := h.loaderFactory.WithLoader(r.Context())
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
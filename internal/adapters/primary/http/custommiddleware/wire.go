@@ -9,5 +9,9 @@ var Set = wire.NewSet(
 	NewMetrics,
 	NewErrorHandling,
 	NewTimeout,
+	NewRealIP,
 	NewAuthentication,
+	NewAuthorization,
+	NewClientCertificate,
+	NewSampleLoader,
 )
@@ -0,0 +1,29 @@
+package request
+
+// RegisterRequest
+// @Description RegisterRequest is a struct that represents the request of user registration
+type RegisterRequest struct {
+	UserID   string `json:"user_id" validate:"required,min=3,max=64,alphanum"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email" example:"test@example.com"`
+	Password string `json:"password" validate:"required,min=8,max=128"`
+}
+
+// VerifyEmailRequest
+// @Description VerifyEmailRequest is a struct that represents the request of email verification
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ForgotPasswordRequest
+// @Description ForgotPasswordRequest is a struct that represents the request of password reset mail
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"test@example.com"`
+}
+
+// ResetPasswordRequest
+// @Description ResetPasswordRequest is a struct that represents the request of password reset
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=128"`
+}
@@ -0,0 +1,11 @@
+package request
+
+import "time"
+
+// CreateAPIKeyRequest
+// @Description CreateAPIKeyRequest is a struct that represents the request of API key issuance
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100" example:"nightly batch"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required,max=100" example:"samples:read"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+}
@@ -3,6 +3,19 @@ package request
 // LoginRequest
 // @Description LoginRequest is a struct that represents the request of login
 type LoginRequest struct {
-	UserID   string `json:"user_id"`
-	Password string `json:"password"`
+	UserID   string `json:"user_id" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// RefreshRequest
+// @Description RefreshRequest is a struct that represents the request of token refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest
+// @Description LogoutRequest is a struct that represents the request of logout
+type LogoutRequest struct {
+	// RefreshToken を指定した場合は、同じログインから発行されたリフレッシュトークンも失効させます
+	RefreshToken string `json:"refresh_token"`
 }
@@ -0,0 +1,22 @@
+package request
+
+// MFAVerifyRequest
+// @Description MFAVerifyRequest is a struct that represents the second step of login
+type MFAVerifyRequest struct {
+	// MFAToken はログインで得た mfa_token です
+	MFAToken string `json:"mfa_token" validate:"required"`
+	// Code は認証アプリの 6 桁のコード、またはリカバリーコードです
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAEnrollRequest
+// @Description MFAEnrollRequest is a struct that represents TOTP enrollment during login
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// MFACodeRequest
+// @Description MFACodeRequest is a struct that represents a TOTP code to confirm or disable MFA
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
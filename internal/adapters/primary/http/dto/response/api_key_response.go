@@ -0,0 +1,54 @@
+package response
+
+import (
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+)
+
+// APIKeyResponse は API キーのレスポンスを表す構造体です。キーそのものは含みません
+// @Description API key information
+type APIKeyResponse struct {
+	ID         string     `json:"id" example:"gk_1a2b3c4d5e6f"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse は発行した API キーのレスポンスです。キーはこのレスポンスでしか得られません
+// @Description Issued API key
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"gk_1a2b3c4d5e6f_..."`
+}
+
+// ListAPIKeyResponse は API キーの一覧のレスポンスです
+// @Description API key list
+type ListAPIKeyResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
+
+// ToAPIKeyResponse はドメインモデルからレスポンスモデルへの変換を行います
+func ToAPIKeyResponse(k *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// ToListAPIKeyResponse は複数の API キーを変換します
+func ToListAPIKeyResponse(keys []models.APIKey) ListAPIKeyResponse {
+	res := ListAPIKeyResponse{APIKeys: make([]APIKeyResponse, len(keys))}
+	for i := range keys {
+		res.APIKeys[i] = ToAPIKeyResponse(&keys[i])
+	}
+	return res
+}
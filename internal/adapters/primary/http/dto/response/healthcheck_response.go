@@ -0,0 +1,8 @@
+package response
+
+// ReadinessResponse
+// @Description ReadinessResponse is a struct that represents the readiness of the API
+type ReadinessResponse struct {
+	Status     string            `json:"status"`
+	Components map[string]string `json:"components"`
+}
@@ -0,0 +1,42 @@
+package response
+
+import "github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+
+// JWKResponse
+// @Description Public key used to verify access tokens (RFC 7517)
+type JWKResponse struct {
+	Kty string `json:"kty" example:"RSA"`
+	Kid string `json:"kid"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSResponse
+// @Description JSON Web Key Set
+type JWKSResponse struct {
+	Keys []JWKResponse `json:"keys"`
+}
+
+// ToJWKSResponse はドメインモデルからレスポンスモデルへの変換を行います
+func ToJWKSResponse(jwks models.JWKS) JWKSResponse {
+	keys := make([]JWKResponse, len(jwks.Keys))
+	for i, k := range jwks.Keys {
+		keys[i] = JWKResponse{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Use: k.Use,
+			Alg: k.Alg,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+			Y:   k.Y,
+		}
+	}
+	return JWKSResponse{Keys: keys}
+}
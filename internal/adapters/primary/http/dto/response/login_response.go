@@ -1,7 +1,45 @@
 package response
 
+import "github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+
 // LoginResponse
-// @Description LoginResponse is a struct that represents the response of login
+// @Description LoginResponse is a struct that represents the response of login and token refresh.
+// @Description When MFA is required, login returns mfa_token instead of the tokens
 type LoginResponse struct {
-	Token string `json:"token"`
+	// Token はアクセストークンです
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty" example:"Bearer"`
+	// ExpiresIn はアクセストークンの有効期間（秒）です
+	ExpiresIn int `json:"expires_in,omitempty" example:"900"`
+	// MFARequired の場合は MFAToken と TOTP のコードを /auth/mfa/verify に送るとトークンを得られます
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAExpiresIn は MFAToken の有効期間（秒）です
+	MFAExpiresIn int `json:"mfa_expires_in,omitempty" example:"300"`
+	// MFAEnrollmentRequired の場合は先に /auth/mfa/enroll で TOTP を登録します
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+}
+
+// ToLoginResponse はトークンの組からレスポンスモデルへの変換を行います
+func ToLoginResponse(p *models.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:        p.AccessToken,
+		RefreshToken: p.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(p.ExpiresIn.Seconds()),
+	}
+}
+
+// ToLoginResultResponse はログインの結果からレスポンスモデルへの変換を行います
+func ToLoginResultResponse(r *models.LoginResult) LoginResponse {
+	if r.Tokens != nil {
+		return ToLoginResponse(r.Tokens)
+	}
+	return LoginResponse{
+		MFARequired:           true,
+		MFAToken:              r.MFAChallenge,
+		MFAExpiresIn:          int(r.MFAChallengeExpiresIn.Seconds()),
+		MFAEnrollmentRequired: r.MFAEnrollmentRequired,
+	}
 }
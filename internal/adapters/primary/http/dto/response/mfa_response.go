@@ -0,0 +1,20 @@
+package response
+
+import "github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+
+// MFASetupResponse は TOTP の登録のレスポンスです。シークレットとリカバリーコードはこのレスポンスでしか得られません
+// @Description TOTP enrollment. Register otpauth_uri with an authenticator app and store the recovery codes safely
+type MFASetupResponse struct {
+	Secret        string   `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI    string   `json:"otpauth_uri" example:"otpauth://totp/go-rest-clean-plane-chi:user123?secret=..."`
+	RecoveryCodes []string `json:"recovery_codes" example:"abcd-efgh-ijkl-mnop"`
+}
+
+// ToMFASetupResponse はドメインモデルからレスポンスモデルへの変換を行います
+func ToMFASetupResponse(s *models.MFASetup) MFASetupResponse {
+	return MFASetupResponse{
+		Secret:        s.Secret,
+		OTPAuthURI:    s.OTPAuthURI,
+		RecoveryCodes: s.RecoveryCodes,
+	}
+}
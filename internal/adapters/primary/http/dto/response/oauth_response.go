@@ -0,0 +1,33 @@
+package response
+
+import (
+	"strings"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+)
+
+// OAuthTokenResponse は RFC 6749 5.1 のトークンエンドポイントの成功レスポンスです
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// ExpiresIn はアクセストークンの有効期間（秒）です
+	ExpiresIn int `json:"expires_in"`
+	// Scope は付与したスコープを空白区切りで並べたものです
+	Scope string `json:"scope,omitempty"`
+}
+
+// ToOAuthTokenResponse はクライアントのトークンからレスポンスモデルへの変換を行います
+func ToOAuthTokenResponse(t *models.ClientToken) OAuthTokenResponse {
+	return OAuthTokenResponse{
+		AccessToken: t.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(t.ExpiresIn.Seconds()),
+		Scope:       strings.Join(t.Scopes, " "),
+	}
+}
+
+// OAuthErrorResponse は RFC 6749 5.2 のトークンエンドポイントのエラーレスポンスです
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
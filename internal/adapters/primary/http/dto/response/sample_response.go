@@ -14,6 +14,7 @@ type SampleResponse struct {
 	IntVal    int       `json:"int_val"`
 	ArrayVal  []string  `json:"array_val"`
 	Email     string    `json:"email"`
+	Team      string    `json:"team"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -26,6 +27,7 @@ func ToSampleResponse(s *models.Sample) SampleResponse {
 		IntVal:    s.IntVal,
 		ArrayVal:  s.ArrayVal,
 		Email:     s.Email,
+		Team:      s.Team,
 		CreatedAt: s.CreatedAt,
 		UpdatedAt: s.UpdatedAt,
 	}
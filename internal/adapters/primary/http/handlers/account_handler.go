@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/request"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/validator"
+)
+
+type AccountHandler struct {
+	logger         logger.Logger
+	JSONWriter     *presenter.JSONWriter
+	accountUsecase usecases.AccountUsecase
+}
+
+func NewAccountHandler(logger logger.Logger, JSONWriter *presenter.JSONWriter, accountUsecase usecases.AccountUsecase) *AccountHandler {
+	return &AccountHandler{
+		logger:         logger,
+		JSONWriter:     JSONWriter,
+		accountUsecase: accountUsecase,
+	}
+}
+
+// Register godoc
+// @Summary Register a user
+// @Description Create a user account and send an email verification token. If the email is already registered, its owner is notified instead; if the user ID is taken, nothing is created. The response is the same in every case
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.RegisterRequest true "Account information"
+// @Success 202
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse "Too many registration attempts from the client IP"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/register [post]
+func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode register request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	err := h.accountUsecase.Register(ctx, &models.User{
+		ID:    req.UserID,
+		Name:  req.Name,
+		Email: req.Email,
+	}, req.Password, clientIP(r))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to register user", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Consume an email verification token
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.VerifyEmailRequest true "Verification token"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode verify email request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	if err := h.accountUsecase.VerifyEmail(ctx, req.Token); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to verify email", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a password reset token. Succeeds whether or not the email is registered
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.ForgotPasswordRequest true "Email address"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode forgot password request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	if err := h.accountUsecase.ForgotPassword(ctx, req.Email); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to request password reset", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Consume a password reset token and set a new password. Tokens issued before the reset are revoked and any login lockout is cleared
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.ResetPasswordRequest true "Reset token and new password"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode reset password request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	if err := h.accountUsecase.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to reset password", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
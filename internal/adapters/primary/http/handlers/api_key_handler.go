@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/custommiddleware"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/request"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/response"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	logger        logger.Logger
+	JSONWriter    *presenter.JSONWriter
+	apiKeyUsecase usecases.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(logger logger.Logger, JSONWriter *presenter.JSONWriter, apiKeyUsecase usecases.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{
+		logger:        logger,
+		JSONWriter:    JSONWriter,
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
+
+// Create godoc
+// @Summary Issue an API key
+// @Description Issue an API key for machine clients. The key is returned only once; scopes must be within your permissions. Only users can create keys; OAuth clients and certificate principals are rejected
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body request.CreateAPIKeyRequest true "API key information"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Success 200 {object} response.CreateAPIKeyResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+	var req request.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode API key request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	key, record, err := h.apiKeyUsecase.Create(ctx, actor, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to create API key", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	h.JSONWriter.Write(ctx, w, response.CreateAPIKeyResponse{
+		APIKeyResponse: response.ToAPIKeyResponse(record),
+		Key:            key,
+	})
+}
+
+// List godoc
+// @Summary List API keys
+// @Description List your API keys including revoked ones
+// @Tags api-keys
+// @Produce json
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Success 200 {object} response.ListAPIKeyResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+	keys, err := h.apiKeyUsecase.List(ctx, actor)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to list API keys", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	h.JSONWriter.Write(ctx, w, response.ToListAPIKeyResponse(keys))
+}
+
+// Revoke godoc
+// @Summary Revoke an API key
+// @Description Revoke one of your API keys
+// @Tags api-keys
+// @Param id path string true "API key ID"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+	if err := h.apiKeyUsecase.Revoke(ctx, actor, chi.URLParam(r, "id")); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to revoke API key", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
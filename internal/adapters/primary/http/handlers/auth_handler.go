@@ -2,14 +2,22 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"strings"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/custommiddleware"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/request"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/response"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/validator"
+	"github.com/go-chi/chi/v5"
 )
 
 type AuthHandler struct {
@@ -28,13 +36,16 @@ func NewAuthHandler(logger logger.Logger, JSONWriter *presenter.JSONWriter, auth
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate a user and return a JWT token
+// @Description Authenticate a user and return a JWT token. If MFA is enabled or required by the user's role, mfa_token is returned instead and the tokens are issued by /auth/mfa/verify
 // @Tags authentication
 // @Accept json
 // @Produce json
 // @Param request body request.LoginRequest true "Login credentials"
 // @Success 200 {object} response.LoginResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse "Too many failed attempts or the account is temporarily locked"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
 // @Failure 500 {object} response.ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -47,19 +58,19 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: ユーザー認証のロジックを実装する
-	// この例では、単純化のためにユーザー名とパスワードのチェックを省略しています
-	userID := req.UserID
-	roles := []string{"role:teamA:editor", "role:teamB:viewer"} // 実際のアプリケーションでは、データベースからユーザーのロールを取得する必要があります
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
 
-	token, err := h.authUsecase.Login(r.Context(), userID, roles)
+	result, err := h.authUsecase.Login(ctx, req.UserID, req.Password, clientIP(r))
 	if err != nil {
-		h.logger.ErrorContext(ctx, "Failed to generate token", "error", err)
-		h.JSONWriter.WriteError(w, apperrors.NewInternalError("Failed to generate token", err))
+		h.logger.ErrorContext(ctx, "Login failed", "error", err)
+		h.JSONWriter.WriteError(w, err)
 		return
 	}
 
-	res := response.LoginResponse{Token: token}
+	res := response.ToLoginResultResponse(result)
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(res)
 	if err != nil {
@@ -67,5 +78,357 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if result.Tokens == nil {
+		h.logger.InfoContext(ctx, "Password accepted; MFA required")
+		return
+	}
 	h.logger.InfoContext(ctx, "Login successful")
 }
+
+// VerifyMFA godoc
+// @Summary Complete login with MFA
+// @Description Exchange the mfa_token returned by login and a TOTP code (or a recovery code) for tokens. A pending enrollment is enabled by the first valid code
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.MFAVerifyRequest true "MFA token and code"
+// @Success 200 {object} response.LoginResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "MFA enrollment required"
+// @Failure 429 {object} response.ErrorResponse "Too many failed attempts or the account is temporarily locked"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode MFA verify request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	tokens, err := h.authUsecase.VerifyMFA(ctx, req.MFAToken, req.Code, clientIP(r))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "MFA verification failed", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	h.JSONWriter.Write(ctx, w, response.ToLoginResponse(tokens))
+}
+
+// EnrollMFAWithChallenge godoc
+// @Summary Enroll TOTP during login
+// @Description Start TOTP enrollment with the mfa_token returned by login, for users whose role requires MFA but who have not enrolled yet. Complete the login with /auth/mfa/verify
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.MFAEnrollRequest true "MFA token"
+// @Success 200 {object} response.MFASetupResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "MFA is already enabled"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFAWithChallenge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.MFAEnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode MFA enroll request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	setup, err := h.authUsecase.EnrollMFAWithChallenge(ctx, req.MFAToken)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to enroll MFA", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.JSONWriter.Write(ctx, w, response.ToMFASetupResponse(setup))
+}
+
+// EnrollMFA godoc
+// @Summary Enroll TOTP
+// @Description Start TOTP enrollment for the current user. Enrollment is enabled after confirming a code with /mfa/confirm. Replaces a pending enrollment
+// @Tags mfa
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.MFASetupResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "MFA is already enabled"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /mfa/enroll [post]
+func (h *AuthHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+
+	setup, err := h.authUsecase.EnrollMFA(ctx, actor)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to enroll MFA", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.JSONWriter.Write(ctx, w, response.ToMFASetupResponse(setup))
+}
+
+// ConfirmMFA godoc
+// @Summary Confirm TOTP enrollment
+// @Description Enable the pending TOTP enrollment of the current user with a code from the authenticator app
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body request.MFACodeRequest true "TOTP code"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "MFA is already enabled"
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /mfa/confirm [post]
+func (h *AuthHandler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+
+	var req request.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode MFA confirm request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	if err := h.authUsecase.ConfirmMFA(ctx, actor, req.Code); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to confirm MFA", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DisableMFA godoc
+// @Summary Disable TOTP
+// @Description Remove the TOTP enrollment of the current user. An enabled enrollment requires a TOTP code or a recovery code. Not allowed for roles that require MFA
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body request.MFACodeRequest true "TOTP code or recovery code"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /mfa [delete]
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+
+	var req request.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode MFA disable request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	if err := h.authUsecase.DisableMFA(ctx, actor, req.Code); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to disable MFA", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a rotated refresh token. Reusing a refresh token revokes all tokens issued from the same login
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.RefreshRequest true "Refresh token"
+// @Success 200 {object} response.LoginResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode refresh request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	tokens, err := h.authUsecase.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Token refresh failed", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	h.JSONWriter.Write(ctx, w, response.ToLoginResponse(tokens))
+}
+
+// Logout godoc
+// @Summary User logout
+// @Description Revoke the access token in the Authorization header, and the refresh token family if a refresh token is given
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.LogoutRequest false "Refresh token to revoke"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || accessToken == "" {
+		h.JSONWriter.WriteError(w, apperrors.NewUnauthorizedError("Missing authorization header", nil))
+		return
+	}
+
+	// ボディは省略できる
+	var req request.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.ErrorContext(ctx, "Failed to decode logout request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+
+	if err := h.authUsecase.Logout(ctx, accessToken, req.RefreshToken); err != nil {
+		h.logger.ErrorContext(ctx, "Logout failed", "error", err)
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeInternal {
+			h.JSONWriter.WriteError(w, err)
+			return
+		}
+		h.JSONWriter.WriteError(w, apperrors.NewUnauthorizedError("Invalid or expired token", nil))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserTokens godoc
+// @Summary Revoke all tokens of a user
+// @Description Revoke every access token and refresh token issued to the user. Requires an admin role on a user session; scoped tokens and API keys are rejected
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/users/{id}/revoke-tokens [post]
+func (h *AuthHandler) RevokeUserTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+	userID := chi.URLParam(r, "id")
+
+	if err := h.authUsecase.RevokeUserTokens(ctx, actor, userID); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to revoke user tokens", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user
+// @Description Clear the temporary lockout and failed login attempts of the user. Requires an admin role on a user session; scoped tokens and API keys are rejected
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+	userID := chi.URLParam(r, "id")
+
+	if err := h.authUsecase.UnlockUser(ctx, actor, userID); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to unlock user", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS はアクセストークンの検証に使う公開鍵を /.well-known/jwks.json で返します
+// API のベースパスの外にあるため swagger には含めません。共有鍵で署名している場合は空です
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// 鍵のローテーションは overlap の前から公開するため、短時間のキャッシュで十分
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	h.JSONWriter.Write(ctx, w, response.ToJWKSResponse(h.authUsecase.JWKS(ctx)))
+}
+
+// clientIP はクライアントの IP アドレスを返します。信頼するプロキシを経由した場合は RealIP ミドルウェアが RemoteAddr を設定します
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,20 +3,28 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/response"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
 )
 
 type HealthcheckHandler struct {
-	logger     logger.Logger
-	JSONWriter *presenter.JSONWriter
+	logger             logger.Logger
+	JSONWriter         *presenter.JSONWriter
+	healthcheckUsecase usecases.HealthcheckUsecase
 }
 
-func NewHealthcheckHandler(logger logger.Logger, JSONWriter *presenter.JSONWriter) *HealthcheckHandler {
+func NewHealthcheckHandler(
+	logger logger.Logger,
+	JSONWriter *presenter.JSONWriter,
+	healthcheckUsecase usecases.HealthcheckUsecase,
+) *HealthcheckHandler {
 	return &HealthcheckHandler{
-		logger:     logger,
-		JSONWriter: JSONWriter,
+		logger:             logger,
+		JSONWriter:         JSONWriter,
+		healthcheckUsecase: healthcheckUsecase,
 	}
 }
 
@@ -43,3 +51,29 @@ func (h *HealthcheckHandler) Get(w http.ResponseWriter, r *http.Request) {
 	res := map[string]string{"message": "healthcheck ok"}
 	h.JSONWriter.Write(ctx, w, res)
 }
+
+// Ready godoc
+// @Summary Readiness check endpoint
+// @Description Get the readiness of the API and its upstream dependencies
+// @Tags healthcheck
+// @Produce json
+// @Success 200 {object} response.ReadinessResponse
+// @Failure 503 {object} response.ReadinessResponse
+// @Router /healthcheck/ready [get]
+func (h *HealthcheckHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	components, ready := h.healthcheckUsecase.Readiness(ctx)
+	res := response.ReadinessResponse{
+		Status:     "ready",
+		Components: components,
+	}
+	if !ready {
+		h.logger.WarnContext(ctx, "Readiness check failed", "components", components)
+		res.Status = "not_ready"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	h.JSONWriter.Write(ctx, w, res)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/response"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// maxTokenRequestBytes はトークンリクエストのボディの上限です
+const maxTokenRequestBytes = 64 << 10
+
+// OAuthHandler は OAuth 2.0 のトークンエンドポイントです
+// エラーはアプリケーションのエラー形式ではなく RFC 6749 の形式で返します
+type OAuthHandler struct {
+	logger       logger.Logger
+	JSONWriter   *presenter.JSONWriter
+	oauthUsecase usecases.OAuthUsecase
+}
+
+func NewOAuthHandler(logger logger.Logger, JSONWriter *presenter.JSONWriter, oauthUsecase usecases.OAuthUsecase) *OAuthHandler {
+	return &OAuthHandler{
+		logger:       logger,
+		JSONWriter:   JSONWriter,
+		oauthUsecase: oauthUsecase,
+	}
+}
+
+// Token は application/x-www-form-urlencoded のトークンリクエストを処理します。対応するグラントは client_credentials のみです
+// クライアント認証は HTTP Basic 認証（client_secret_basic）かボディの client_id / client_secret（client_secret_post）で行います
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTokenRequestBytes)
+	if err := r.ParseForm(); err != nil {
+		h.writeError(w, r, services.NewOAuthError(services.OAuthErrorInvalidRequest, "request body must be application/x-www-form-urlencoded"))
+		return
+	}
+	form := r.PostForm
+
+	// RFC 6749 3.2 によりパラメーターの重複は拒否する
+	for _, name := range []string{"grant_type", "scope", "client_id", "client_secret"} {
+		if len(form[name]) > 1 {
+			h.writeError(w, r, services.NewOAuthError(services.OAuthErrorInvalidRequest, name+" must not be repeated"))
+			return
+		}
+	}
+
+	switch grantType := form.Get("grant_type"); grantType {
+	case "":
+		h.writeError(w, r, services.NewOAuthError(services.OAuthErrorInvalidRequest, "grant_type is required"))
+		return
+	case "client_credentials":
+	default:
+		h.writeError(w, r, services.NewOAuthError(services.OAuthErrorUnsupportedGrantType, "grant_type "+grantType+" is not supported"))
+		return
+	}
+
+	clientID, clientSecret, basic := r.BasicAuth()
+	if basic && (form.Has("client_id") || form.Has("client_secret")) {
+		h.writeError(w, r, services.NewOAuthError(services.OAuthErrorInvalidRequest, "use only one client authentication method"))
+		return
+	}
+	if !basic {
+		clientID, clientSecret = form.Get("client_id"), form.Get("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		h.writeError(w, r, services.NewOAuthError(services.OAuthErrorInvalidClient, "client authentication is required"))
+		return
+	}
+
+	token, err := h.oauthUsecase.ClientCredentials(ctx, clientID, clientSecret, form.Get("scope"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	h.JSONWriter.Write(ctx, w, response.ToOAuthTokenResponse(token))
+}
+
+// writeError は OAuthError を RFC 6749 5.2 の形式で書き込みます。それ以外のエラーは通常のエラーとして返します
+func (h *OAuthHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		h.logger.ErrorContext(r.Context(), "Failed to issue client token", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	h.logger.WarnContext(r.Context(), "Token request rejected", "error", oauthErr.Code, "description", oauthErr.Description)
+	if oauthErr.StatusCode() == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(oauthErr.StatusCode())
+	if err := json.NewEncoder(w).Encode(response.OAuthErrorResponse{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	}); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to encode oauth error response", "error", err)
+	}
+}
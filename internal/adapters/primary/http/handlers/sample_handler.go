@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/custommiddleware"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/request"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/dto/response"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers/queryparameter"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/presenter"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/usecases"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
@@ -43,12 +45,15 @@ func NewSampleHandler(
 // @Param offset query int false "Offset for pagination" default(0) minimum(0)
 // @Param limit query int false "Limit for pagination" default(100) minimum(1) maximum(100)
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Success 200 {object} response.ListSampleResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /samples [get]
 func (h *SampleHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
 
 	p := queryparameter.NewOffsetLimitParams(r)
 	if err := validator.Validate(p); err != nil {
@@ -58,7 +63,7 @@ func (h *SampleHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// サンプルリストの取得
-	samples, err := h.sampleUsecase.List(ctx, p.Offset, p.Limit)
+	samples, err := h.sampleUsecase.List(ctx, actor, p.Offset, p.Limit)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to get sample list", "error", err)
 		h.JSONWriter.WriteError(w, err)
@@ -78,9 +83,11 @@ func (h *SampleHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body request.SampleRequest true "Sample information"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Success 200 {object} response.SampleResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /samples [post]
 func (h *SampleHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -120,14 +127,19 @@ func (h *SampleHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param id path string true "Sample ID"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Success 200 {object} response.SampleResponse
+// @Header 200 {string} Warning "Set when a stale copy is served (110 while revalidating, 111 when upstream failed)"
+// @Header 200 {integer} Age "Seconds since the stale copy was fetched"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /samples/{id} [get]
 func (h *SampleHandler) Get(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
 
 	ID := chi.URLParam(r, "id")
 	if ID == "" {
@@ -141,7 +153,7 @@ func (h *SampleHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sample, err := h.sampleUsecase.Get(ctx, ID)
+	sample, err := h.sampleUsecase.Get(ctx, actor, ID)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "Failed to get sample", "error", err)
 		h.JSONWriter.WriteError(w, err)
@@ -150,19 +162,104 @@ func (h *SampleHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 	res := response.ToSampleResponse(sample)
 
+	// upstream 障害時にキャッシュの古いデータを返した場合はヘッダーで示す
+	presenter.WriteFreshnessHeaders(ctx, w)
 	h.JSONWriter.Write(ctx, w, res)
 }
 
-func (h *SampleHandler) Update(w http.ResponseWriter, _ *http.Request) {
-	// 更新処理
-	// nolint:errcheck
-	json.NewEncoder(w).Encode(map[string]string{"message": "Update sample"})
+// Update godoc
+// @Summary Update a sample
+// @Description Update a sample by ID. Only the local data source supports writes; the GraphQL upstream returns 501
+// @Tags samples
+// @Accept json
+// @Produce json
+// @Param id path string true "Sample ID"
+// @Param request body request.SampleRequest true "Sample information"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Success 200 {object} response.SampleResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 501 {object} response.ErrorResponse
+// @Router /samples/{id} [put]
+func (h *SampleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+
+	ID := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(ID, "sampleId", "path parameter"); err != nil {
+		h.logger.ErrorContext(ctx, "Invalid sample ID format", "id", ID)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	var req request.SampleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode sample request", "error", err)
+		h.JSONWriter.WriteError(w, apperrors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	req.ID = ID
+
+	if validationErrors := validator.Validate(req); validationErrors != nil {
+		h.JSONWriter.WriteError(w, validationErrors)
+		return
+	}
+
+	sample, err := h.sampleUsecase.Update(ctx, actor, &models.Sample{
+		ID:        req.ID,
+		StringVal: req.StringVal,
+		IntVal:    req.IntVal,
+		ArrayVal:  req.ArrayVal,
+		Email:     req.Email,
+	})
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to update sample", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	res := response.ToSampleResponse(sample)
+
+	h.JSONWriter.Write(ctx, w, res)
 }
 
-func (h *SampleHandler) Delete(w http.ResponseWriter, _ *http.Request) {
-	// 削除処理
-	// nolint:errcheck
-	json.NewEncoder(w).Encode(map[string]string{"message": "Delete sample"})
+// Delete godoc
+// @Summary Delete a sample
+// @Description Delete a sample by ID. Only the local data source supports writes; the GraphQL upstream returns 501
+// @Tags samples
+// @Param id path string true "Sample ID"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 501 {object} response.ErrorResponse
+// @Router /samples/{id} [delete]
+func (h *SampleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	actor, _ := ctx.Value(custommiddleware.UserKey).(*models.User)
+
+	ID := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(ID, "sampleId", "path parameter"); err != nil {
+		h.logger.ErrorContext(ctx, "Invalid sample ID format", "id", ID)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	if err := h.sampleUsecase.Delete(ctx, actor, ID); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to delete sample", "error", err)
+		h.JSONWriter.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *SampleHandler) GetSampleProfile(_ http.ResponseWriter, _ *http.Request) {
@@ -5,5 +5,8 @@ import "github.com/google/wire"
 var Set = wire.NewSet(
 	NewHealthcheckHandler,
 	NewAuthHandler,
+	NewAccountHandler,
 	NewSampleHandler,
+	NewAPIKeyHandler,
+	NewOAuthHandler,
 )
@@ -0,0 +1,31 @@
+package presenter
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/common/freshness"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// WriteFreshnessHeaders は古いデータを返す場合に Warning / Age ヘッダーと span の stale タグを設定します
+// ヘッダーはボディの書き込み前に設定する必要があります
+func WriteFreshnessHeaders(ctx context.Context, w http.ResponseWriter) {
+	info := freshness.FromContext(ctx)
+	if !info.Stale {
+		return
+	}
+
+	warning := `110 - "Response is Stale"`
+	if !info.Revalidating {
+		warning = `111 - "Revalidation Failed"`
+	}
+	w.Header().Set("Warning", warning)
+	w.Header().Set("Age", strconv.Itoa(int(math.Floor(info.Age.Seconds()))))
+
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		span.SetTag("stale", true)
+	}
+}
@@ -21,11 +21,19 @@ type Router struct {
 	DDMetrics      *custommiddleware.DDMetrics
 	errorHandler   *custommiddleware.ErrorHandling
 	timeout        *custommiddleware.Timeout
+	realIP         *custommiddleware.RealIP
 	authentication *custommiddleware.Authentication
+	clientCert     *custommiddleware.ClientCertificate
+	sampleLoader   *custommiddleware.SampleLoader
 	// router
 	healthcheckRouter *v1.HealthcheckRouter
 	authRouter        *v1.AuthRouter
 	sampleRouter      *v1.SampleRouter
+	adminRouter       *v1.AdminRouter
+	apiKeyRouter      *v1.APIKeyRouter
+	mfaRouter         *v1.MFARouter
+	wellKnownRouter   *v1.WellKnownRouter
+	oauthRouter       *v1.OAuthRouter
 }
 
 func NewRouter(
@@ -37,10 +45,18 @@ func NewRouter(
 
 	errorHandler *custommiddleware.ErrorHandling,
 	Timeout *custommiddleware.Timeout,
+	realIP *custommiddleware.RealIP,
 	authentication *custommiddleware.Authentication,
+	clientCert *custommiddleware.ClientCertificate,
+	sampleLoader *custommiddleware.SampleLoader,
 	healthcheckRouter *v1.HealthcheckRouter,
 	authRouter *v1.AuthRouter,
 	sampleRouter *v1.SampleRouter,
+	adminRouter *v1.AdminRouter,
+	apiKeyRouter *v1.APIKeyRouter,
+	mfaRouter *v1.MFARouter,
+	wellKnownRouter *v1.WellKnownRouter,
+	oauthRouter *v1.OAuthRouter,
 ) *Router {
 	return &Router{
 		cfg: cfg,
@@ -51,10 +67,18 @@ func NewRouter(
 
 		errorHandler:      errorHandler,
 		timeout:           Timeout,
+		realIP:            realIP,
 		authentication:    authentication,
+		clientCert:        clientCert,
+		sampleLoader:      sampleLoader,
 		healthcheckRouter: healthcheckRouter,
 		authRouter:        authRouter,
 		sampleRouter:      sampleRouter,
+		adminRouter:       adminRouter,
+		apiKeyRouter:      apiKeyRouter,
+		mfaRouter:         mfaRouter,
+		wellKnownRouter:   wellKnownRouter,
+		oauthRouter:       oauthRouter,
 	}
 }
 
@@ -62,15 +86,18 @@ func (ro *Router) Setup() http.Handler {
 	r := chi.NewRouter()
 	ro.setupGlobalMiddleware(r)
 	ro.setupSwagger(r)
+	r.Mount("/.well-known", ro.wellKnownRouter.Handler)
+	r.Mount("/oauth", ro.oauthRouter.Handler)
 	ro.setupAPIRoutes(r)
 	return r
 }
 
 func (ro *Router) setupGlobalMiddleware(r *chi.Mux) { // case: datadog SDK
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(ro.realIP.Handle())
 	r.Use(middleware.Recoverer)
 	r.Use(custommiddleware.Context())
+	r.Use(custommiddleware.Freshness())
 	//r.Use(custommiddleware.OTELTracer()) // fixme choose one of OTELTracer or DDTracer
 	//r.Use(custommiddleware.OTELMetrics(appMetrics)) // case: open telemetry
 	r.Use(ro.DDTracer.Handle())
@@ -80,7 +107,7 @@ func (ro *Router) setupGlobalMiddleware(r *chi.Mux) { // case: datadog SDK
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   ro.cfg.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
 		ExposedHeaders:   []string{},
 		AllowCredentials: false,
 		MaxAge:           300, // 5 minutes
@@ -115,8 +142,13 @@ func (ro *Router) setupAPIRoutes(r *chi.Mux) {
 
 			r.Group(func(r chi.Router) {
 				// 認証必要のプライベートルート
+				r.Use(ro.clientCert.Handle())
 				r.Use(ro.authentication.Handle())
+				r.Use(ro.sampleLoader.Handle())
 				r.Mount("/samples", ro.sampleRouter.Handler)
+				r.Mount("/admin", ro.adminRouter.Handler)
+				r.Mount("/api-keys", ro.apiKeyRouter.Handler)
+				r.Mount("/mfa", ro.mfaRouter.Handler)
 			})
 		})
 	})
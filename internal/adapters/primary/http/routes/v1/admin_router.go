@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/custommiddleware"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminRouter struct {
+	Handler http.Handler
+}
+
+func NewAdminRouter(authHandler *handlers.AuthHandler, authorization *custommiddleware.Authorization) *AdminRouter {
+	r := chi.NewRouter()
+	// スコープで制限されたトークンや API キーは users:admin を含まない限りここで拒否する
+	// 含む場合も管理操作はユーザーのセッションに限るため、ユースケースで拒否する
+	r.Use(authorization.RequireScope("users:admin"))
+	r.Post("/users/{id}/revoke-tokens", authHandler.RevokeUserTokens)
+	r.Post("/users/{id}/unlock", authHandler.UnlockUser)
+
+	return &AdminRouter{Handler: r}
+}
@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/custommiddleware"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyRouter struct {
+	Handler http.Handler
+}
+
+func NewAPIKeyRouter(apiKeyHandler *handlers.APIKeyHandler, authorization *custommiddleware.Authorization) *APIKeyRouter {
+	r := chi.NewRouter()
+	// スコープで制限されたトークンや API キーは api-keys:manage を含む場合のみキーを管理できる
+	r.Use(authorization.RequireScope("api-keys:manage"))
+	r.Get("/", apiKeyHandler.List)
+	r.Post("/", apiKeyHandler.Create)
+	r.Delete("/{id}", apiKeyHandler.Revoke)
+
+	return &APIKeyRouter{Handler: r}
+}
@@ -11,9 +11,17 @@ type AuthRouter struct {
 	Handler http.Handler
 }
 
-func NewAuthRouter(authHandler *handlers.AuthHandler) *AuthRouter {
+func NewAuthRouter(authHandler *handlers.AuthHandler, accountHandler *handlers.AccountHandler) *AuthRouter {
 	r := chi.NewRouter()
 	r.Post("/login", authHandler.Login)
+	r.Post("/refresh", authHandler.Refresh)
+	r.Post("/logout", authHandler.Logout)
+	r.Post("/mfa/verify", authHandler.VerifyMFA)
+	r.Post("/mfa/enroll", authHandler.EnrollMFAWithChallenge)
+	r.Post("/register", accountHandler.Register)
+	r.Post("/verify-email", accountHandler.VerifyEmail)
+	r.Post("/password/forgot", accountHandler.ForgotPassword)
+	r.Post("/password/reset", accountHandler.ResetPassword)
 
 	return &AuthRouter{Handler: r}
 }
@@ -14,6 +14,7 @@ type HealthcheckRouter struct {
 func NewHealthcheckRouter(healthcheckHandler *handlers.HealthcheckHandler) *HealthcheckRouter {
 	r := chi.NewRouter()
 	r.Get("/", healthcheckHandler.Get)
+	r.Get("/ready", healthcheckHandler.Ready)
 
 	return &HealthcheckRouter{Handler: r}
 }
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+type MFARouter struct {
+	Handler http.Handler
+}
+
+func NewMFARouter(authHandler *handlers.AuthHandler) *MFARouter {
+	r := chi.NewRouter()
+	r.Post("/enroll", authHandler.EnrollMFA)
+	r.Post("/confirm", authHandler.ConfirmMFA)
+	r.Delete("/", authHandler.DisableMFA)
+
+	return &MFARouter{Handler: r}
+}
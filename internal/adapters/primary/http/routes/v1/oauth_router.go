@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+// OAuthRouter は /oauth 以下のルートです。クライアントが標準のパスで利用できるよう API のバージョンに依存しません
+type OAuthRouter struct {
+	Handler http.Handler
+}
+
+func NewOAuthRouter(oauthHandler *handlers.OAuthHandler) *OAuthRouter {
+	r := chi.NewRouter()
+	r.Post("/token", oauthHandler.Token)
+
+	return &OAuthRouter{Handler: r}
+}
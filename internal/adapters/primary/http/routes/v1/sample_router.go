@@ -3,6 +3,7 @@ package v1
 import (
 	"net/http"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/custommiddleware"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers"
 	"github.com/go-chi/chi/v5"
 )
@@ -11,22 +12,24 @@ type SampleRouter struct {
 	Handler http.Handler
 }
 
-func NewSampleRouter(sampleHandler *handlers.SampleHandler) *SampleRouter {
+func NewSampleRouter(sampleHandler *handlers.SampleHandler, authorization *custommiddleware.Authorization) *SampleRouter {
 	r := chi.NewRouter()
+	read := authorization.RequirePermission("samples:read")
+	write := authorization.RequirePermission("samples:write")
 
-	r.Get("/", sampleHandler.List)
-	r.Post("/", sampleHandler.Create)
+	r.With(read).Get("/", sampleHandler.List)
+	r.With(write).Post("/", sampleHandler.Create)
 
 	// ID指定の操作をグループ化
 	r.Route("/{id}", func(r chi.Router) {
-		r.Get("/", sampleHandler.Get)
-		r.Put("/", sampleHandler.Update)
-		r.Delete("/", sampleHandler.Delete)
+		r.With(read).Get("/", sampleHandler.Get)
+		r.With(write).Put("/", sampleHandler.Update)
+		r.With(write).Delete("/", sampleHandler.Delete)
 
 		// ネストされたリソース
 		r.Route("/profile", func(r chi.Router) {
-			r.Get("/", sampleHandler.GetSampleProfile)
-			r.Put("/", sampleHandler.UpdateSampleProfile)
+			r.With(read).Get("/", sampleHandler.GetSampleProfile)
+			r.With(write).Put("/", sampleHandler.UpdateSampleProfile)
 		})
 
 	})
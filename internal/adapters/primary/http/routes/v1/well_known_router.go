@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/primary/http/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+// WellKnownRouter は /.well-known 以下のルートです。API のバージョンに依存しません
+type WellKnownRouter struct {
+	Handler http.Handler
+}
+
+func NewWellKnownRouter(authHandler *handlers.AuthHandler) *WellKnownRouter {
+	r := chi.NewRouter()
+	r.Get("/jwks.json", authHandler.JWKS)
+
+	return &WellKnownRouter{Handler: r}
+}
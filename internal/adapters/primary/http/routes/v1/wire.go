@@ -6,4 +6,9 @@ var Set = wire.NewSet(
 	NewHealthcheckRouter,
 	NewAuthRouter,
 	NewSampleRouter,
+	NewAdminRouter,
+	NewAPIKeyRouter,
+	NewMFARouter,
+	NewWellKnownRouter,
+	NewOAuthRouter,
 )
@@ -0,0 +1,39 @@
+package datasource
+
+import (
+	"context"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+)
+
+// graphqlSource は upstream の GraphQL を SampleDataSource として扱うアダプターです
+// upstream は書き込みの API を公開していないため、更新と削除は 501 を返します
+type graphqlSource struct {
+	client piyographql.Client
+}
+
+func newGraphQLSource(client piyographql.Client) *graphqlSource {
+	return &graphqlSource{client: client}
+}
+
+func (s *graphqlSource) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	return s.client.GetSample(ctx, ID)
+}
+
+func (s *graphqlSource) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
+	return s.client.ListSample(ctx, offset, limit)
+}
+
+func (s *graphqlSource) UpdateSample(context.Context, *models.Sample) (*models.Sample, error) {
+	return nil, writeNotSupportedError()
+}
+
+func (s *graphqlSource) DeleteSample(context.Context, string) error {
+	return writeNotSupportedError()
+}
+
+func writeNotSupportedError() error {
+	return apperrors.NewNotImplementedError("Sample writes are not supported by the upstream GraphQL service", nil)
+}
@@ -0,0 +1,105 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/cache"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// readThroughSource はローカルリポジトリを優先し、存在しない場合は upstream から取得してローカルに書き戻します
+// 書き戻した結果は件数と有効期限を制限したキャッシュに保持し、upstream の変更を有効期限の経過後に反映します
+// 書き込みは upstream を正とし、成功した結果をキャッシュに反映します
+type readThroughSource struct {
+	logger   logger.Logger
+	local    repository.SampleRepository
+	upstream SampleDataSource
+	backfill *cache.LRU[string, *models.Sample]
+	ttl      time.Duration
+}
+
+func newReadThroughSource(cfg *config.AppConfig, logger logger.Logger, local repository.SampleRepository, upstream SampleDataSource) *readThroughSource {
+	return &readThroughSource{
+		logger:   logger,
+		local:    local,
+		upstream: upstream,
+		backfill: cache.NewLRU[string, *models.Sample](cfg.SampleReadThroughCacheSize),
+		ttl:      cfg.SampleReadThroughCacheTTL,
+	}
+}
+
+func (s *readThroughSource) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	sample, err := s.local.Get(ctx, ID)
+	if err == nil {
+		return sample, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+	if cached, ok := s.backfill.Get(ID); ok {
+		return cloneSample(cached), nil
+	}
+
+	sample, err = s.upstream.GetSample(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	s.store(sample)
+	return sample, nil
+}
+
+// ListSample はローカルでは全件の有無を判断できないため upstream から取得し、結果をローカルに書き戻します
+func (s *readThroughSource) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
+	samples, err := s.upstream.ListSample(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range samples {
+		s.store(&samples[i])
+	}
+	return samples, nil
+}
+
+func (s *readThroughSource) UpdateSample(ctx context.Context, sample *models.Sample) (*models.Sample, error) {
+	updated, err := s.upstream.UpdateSample(ctx, sample)
+	if err != nil {
+		return nil, err
+	}
+	s.store(updated)
+	return updated, nil
+}
+
+func (s *readThroughSource) DeleteSample(ctx context.Context, ID string) error {
+	if err := s.upstream.DeleteSample(ctx, ID); err != nil {
+		return err
+	}
+	s.backfill.Delete(ID)
+	if err := s.local.Delete(ctx, ID); err != nil && !isNotFound(err) {
+		s.logger.WarnContext(ctx, "Failed to delete sample from local repository", "id", ID, "error", err)
+	}
+	return nil
+}
+
+func (s *readThroughSource) store(sample *models.Sample) {
+	s.backfill.Set(sample.ID, cloneSample(sample), s.ttl)
+}
+
+// cloneSample は呼び出し元の変更がキャッシュに及ばないようコピーを返します
+func cloneSample(sample *models.Sample) *models.Sample {
+	cloned := *sample
+	if sample.ArrayVal != nil {
+		cloned.ArrayVal = append([]string(nil), sample.ArrayVal...)
+	}
+	return &cloned
+}
+
+func isNotFound(err error) bool {
+	var appErr *apperrors.AppError
+	return errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound
+}
@@ -0,0 +1,136 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/mocks/mockdatasource"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/mocks/mockpiyographql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadThroughSource(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.AppConfig{SampleReadThroughCacheSize: 10, SampleReadThroughCacheTTL: time.Minute}
+	newTargetWithConfig := func(t *testing.T, cfg *config.AppConfig) (*readThroughSource, repository.SampleRepository, *mockdatasource.MockSampleDataSource) {
+		ctrl := gomock.NewController(t)
+		upstream := mockdatasource.NewMockSampleDataSource(ctrl)
+		local := repository.NewSampleRepository()
+		return newReadThroughSource(cfg, logger.NewLogger(&config.AppConfig{}), local, upstream), local, upstream
+	}
+	newTarget := func(t *testing.T) (*readThroughSource, repository.SampleRepository, *mockdatasource.MockSampleDataSource) {
+		return newTargetWithConfig(t, cfg)
+	}
+
+	t.Run("正常系: ローカルにある場合は upstream を呼ばない", func(t *testing.T) {
+		target, local, _ := newTarget(t)
+		require.NoError(t, local.Save(ctx, &models.Sample{ID: "1", StringVal: "local"}))
+
+		sample, err := target.GetSample(ctx, "1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "local", sample.StringVal)
+	})
+
+	t.Run("正常系: ローカルにない場合は upstream から取得して書き戻す", func(t *testing.T) {
+		target, _, upstream := newTarget(t)
+		upstream.EXPECT().GetSample(ctx, "1").Return(&models.Sample{ID: "1", StringVal: "upstream"}, nil).Times(1)
+
+		sample, err := target.GetSample(ctx, "1")
+		require.NoError(t, err)
+		sample.StringVal = "changed"
+
+		stored, err := target.GetSample(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "upstream", stored.StringVal, "2 回目は書き戻した結果を返し、呼び出し元の変更は反映しない")
+	})
+
+	t.Run("正常系: 書き戻した結果は有効期限を過ぎると upstream から取り直す", func(t *testing.T) {
+		target, _, upstream := newTargetWithConfig(t, &config.AppConfig{SampleReadThroughCacheSize: 10, SampleReadThroughCacheTTL: 10 * time.Millisecond})
+		gomock.InOrder(
+			upstream.EXPECT().GetSample(ctx, "1").Return(&models.Sample{ID: "1", StringVal: "v1"}, nil),
+			upstream.EXPECT().GetSample(ctx, "1").Return(&models.Sample{ID: "1", StringVal: "v2"}, nil),
+		)
+		_, err := target.GetSample(ctx, "1")
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+		sample, err := target.GetSample(ctx, "1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "v2", sample.StringVal)
+	})
+
+	t.Run("正常系: 書き戻す件数は上限を超えない", func(t *testing.T) {
+		target, _, upstream := newTargetWithConfig(t, &config.AppConfig{SampleReadThroughCacheSize: 2, SampleReadThroughCacheTTL: time.Minute})
+		upstream.EXPECT().ListSample(ctx, nil, nil).Return([]models.Sample{{ID: "1"}, {ID: "2"}, {ID: "3"}}, nil)
+
+		_, err := target.ListSample(ctx, nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, target.backfill.Len())
+	})
+
+	t.Run("異常系: upstream の NotFound はそのまま返す", func(t *testing.T) {
+		target, _, upstream := newTarget(t)
+		upstream.EXPECT().GetSample(ctx, "1").Return(nil, apperrors.NewNotFoundError("Sample not found", nil))
+
+		_, err := target.GetSample(ctx, "1")
+
+		assert.True(t, isNotFound(err))
+	})
+
+	t.Run("正常系: 削除は upstream の成功後にローカルからも削除する", func(t *testing.T) {
+		target, local, upstream := newTarget(t)
+		require.NoError(t, local.Save(ctx, &models.Sample{ID: "1"}))
+		upstream.EXPECT().DeleteSample(ctx, "1").Return(nil)
+
+		require.NoError(t, target.DeleteSample(ctx, "1"))
+
+		_, err := local.Get(ctx, "1")
+		assert.True(t, isNotFound(err))
+	})
+}
+
+func TestNewSampleDataSource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mockpiyographql.NewMockClient(ctrl)
+	repo := repository.NewSampleRepository()
+	log := logger.NewLogger(&config.AppConfig{})
+
+	t.Run("正常系: 設定に応じた取得元を返す", func(t *testing.T) {
+		for mode, want := range map[string]any{
+			SampleDataSourceGraphQL:     &graphqlSource{},
+			SampleDataSourceLocal:       &repositorySource{},
+			SampleDataSourceReadThrough: &readThroughSource{},
+		} {
+			got, err := NewSampleDataSource(&config.AppConfig{SampleDataSource: mode}, log, client, repo)
+			require.NoError(t, err)
+			assert.IsType(t, want, got, mode)
+		}
+	})
+
+	t.Run("異常系: upstream は書き込みに対応していない", func(t *testing.T) {
+		got, err := NewSampleDataSource(&config.AppConfig{SampleDataSource: SampleDataSourceGraphQL}, log, client, repo)
+		require.NoError(t, err)
+
+		err = got.DeleteSample(context.Background(), "1")
+
+		var appErr *apperrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, apperrors.ErrorTypeNotImplemented, appErr.Type)
+	})
+
+	t.Run("異常系: 未知の取得元はエラーになる", func(t *testing.T) {
+		_, err := NewSampleDataSource(&config.AppConfig{SampleDataSource: "unknown"}, log, client, repo)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+package datasource
+
+import (
+	"context"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+)
+
+// repositorySource はローカルリポジトリを SampleDataSource として扱うアダプターです
+type repositorySource struct {
+	repo repository.SampleRepository
+	now  func() time.Time
+}
+
+func newRepositorySource(repo repository.SampleRepository) *repositorySource {
+	return &repositorySource{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (s *repositorySource) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	return s.repo.Get(ctx, ID)
+}
+
+func (s *repositorySource) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
+	samples, err := s.repo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Sample, 0, len(samples))
+	for _, sample := range samples {
+		result = append(result, *sample)
+	}
+	return result, nil
+}
+
+// UpdateSample は upstream と同様に、存在しないサンプルの更新を NotFound とします
+func (s *repositorySource) UpdateSample(ctx context.Context, sample *models.Sample) (*models.Sample, error) {
+	current, err := s.repo.Get(ctx, sample.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *sample
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = s.now()
+	if err := s.repo.Save(ctx, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *repositorySource) DeleteSample(ctx context.Context, ID string) error {
+	return s.repo.Delete(ctx, ID)
+}
@@ -0,0 +1,45 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+const (
+	SampleDataSourceGraphQL     = "graphql"
+	SampleDataSourceLocal       = "local"
+	SampleDataSourceReadThrough = "read_through"
+)
+
+// SampleDataSource は SampleUsecase が参照するサンプルの取得元です
+type SampleDataSource interface {
+	GetSample(ctx context.Context, id string) (*models.Sample, error)
+	ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error)
+	UpdateSample(ctx context.Context, sample *models.Sample) (*models.Sample, error)
+	DeleteSample(ctx context.Context, id string) error
+}
+
+// NewSampleDataSource は設定に応じた SampleDataSource を返します
+func NewSampleDataSource(
+	cfg *config.AppConfig,
+	logger logger.Logger,
+	client piyographql.Client,
+	repo repository.SampleRepository,
+) (SampleDataSource, error) {
+	switch cfg.SampleDataSource {
+	case SampleDataSourceGraphQL:
+		return newGraphQLSource(client), nil
+	case SampleDataSourceLocal:
+		return newRepositorySource(repo), nil
+	case SampleDataSourceReadThrough:
+		return newReadThroughSource(cfg, logger, repo, newGraphQLSource(client)), nil
+	default:
+		return nil, fmt.Errorf("unknown sample data source: %q", cfg.SampleDataSource)
+	}
+}
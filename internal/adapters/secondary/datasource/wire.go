@@ -0,0 +1,7 @@
+package datasource
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(
+	NewSampleDataSource,
+)
@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// Message は送信するメールです
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer はメール送信のポートです
+// SMTP や外部の配信サービスを使う場合はこのインターフェースを実装して差し替えます
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer は設定に応じた Mailer を返します
+func NewMailer(cfg *config.AppConfig, logger logger.Logger) (Mailer, error) {
+	switch cfg.MailerType {
+	case "file":
+		return NewFileMailer(cfg.MailerFilePath, cfg.MailerFrom), nil
+	case "log":
+		return NewLogMailer(logger, cfg.MailerFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer type: %q", cfg.MailerType)
+	}
+}
+
+// logMailer はメールを送らずにログへ出力します。ローカル開発用です
+// 本文にはトークンが含まれるため、本番環境では利用しないでください
+type logMailer struct {
+	logger logger.Logger
+	from   string
+}
+
+func NewLogMailer(logger logger.Logger, from string) Mailer {
+	return &logMailer{
+		logger: logger,
+		from:   from,
+	}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.InfoContext(ctx, "Mail sent", "from", m.from, "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// fileMailer はメールをローカルファイルに追記します
+type fileMailer struct {
+	path string
+	from string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path, from string) Mailer {
+	return &fileMailer{
+		path: path,
+		from: from,
+		now:  time.Now,
+	}
+}
+
+func (m *fileMailer) Send(_ context.Context, msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Date: %s\n", m.now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "From: %s\n", m.from)
+	fmt.Fprintf(&b, "To: %s\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\n\n", msg.Subject)
+	b.WriteString(msg.Body)
+	b.WriteString("\n\n")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("mailer: failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("mailer: failed to open %s: %w", m.path, err)
+	}
+	// nolint:errcheck
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("mailer: failed to write %s: %w", m.path, err)
+	}
+	return nil
+}
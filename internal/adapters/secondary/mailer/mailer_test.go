@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	t.Run("正常系: メールをファイルに追記する", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mail", "mail.log")
+		m := NewFileMailer(path, "no-reply@example.com")
+
+		require.NoError(t, m.Send(context.Background(), Message{To: "a@example.com", Subject: "first", Body: "hello"}))
+		require.NoError(t, m.Send(context.Background(), Message{To: "b@example.com", Subject: "second", Body: "world"}))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		content := string(data)
+		assert.Contains(t, content, "From: no-reply@example.com\nTo: a@example.com\nSubject: first\n\nhello")
+		assert.Contains(t, content, "To: b@example.com\nSubject: second\n\nworld")
+	})
+}
@@ -0,0 +1,7 @@
+package mailer
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(
+	NewMailer,
+)
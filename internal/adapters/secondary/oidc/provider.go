@@ -0,0 +1,222 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxDocumentSize はディスカバリードキュメントと JWKS の最大サイズです
+const maxDocumentSize = 1 << 20
+
+// ProviderSettings は oidc_providers_file に記述する IdP の定義です
+type ProviderSettings struct {
+	// Issuer は IdP の発行者で、トークンの iss と一致する必要があります
+	Issuer string `json:"issuer"`
+	// Audience はトークンの aud に含まれる必要がある値（通常はクライアント ID）です
+	Audience string `json:"audience"`
+	// UserIDClaim はユーザー ID に使うクレームです。デフォルトは sub です
+	UserIDClaim string `json:"user_id_claim"`
+	// RoleClaim はロールの元になるクレーム（文字列または文字列の配列）です。デフォルトは groups です
+	RoleClaim string `json:"role_claim"`
+	// RoleMappings は RoleClaim の値から付与するロールへの対応です。対応のない値は無視します
+	RoleMappings map[string][]string `json:"role_mappings"`
+	// DefaultRoles はこの IdP で認証したユーザーに常に付与するロールです
+	DefaultRoles []string `json:"default_roles"`
+}
+
+// discoveryDocument は OpenID Connect Discovery 1.0 のうち利用する項目です
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type publicKey struct {
+	alg string
+	key any
+}
+
+// provider は 1 つの IdP のディスカバリードキュメントと公開鍵をキャッシュします
+type provider struct {
+	settings   ProviderSettings
+	httpClient *http.Client
+	logger     logger.Logger
+	// minRefreshInterval は未知の kid による再取得の最短間隔です
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	attemptedAt time.Time // 失敗した取得も含めた直近の取得時刻
+	group       singleflight.Group
+}
+
+// key は kid に一致する公開鍵を返します
+// 見つからない場合は IdP の鍵のローテーションに追従するため、最短間隔を空けて再取得します
+func (p *provider) key(ctx context.Context, kid string) (publicKey, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	stale := p.now().Sub(p.attemptedAt) >= p.minRefreshInterval
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return publicKey{}, fmt.Errorf("unknown key %q for issuer %s", kid, p.settings.Issuer)
+	}
+
+	// 呼び出し元のキャンセルで他の待機者まで失敗しないよう、取得はリクエストのコンテキストから切り離す
+	if err := p.refresh(ctx, context.WithoutCancel(ctx)); err != nil {
+		return publicKey{}, err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return publicKey{}, fmt.Errorf("unknown key %q for issuer %s", kid, p.settings.Issuer)
+}
+
+// refresh はディスカバリードキュメントと JWKS を fetchCtx で取得し直し、ctx が終了するまで完了を待ちます
+// 同時の呼び出しは 1 回にまとめ、最初の呼び出しの fetchCtx で取得します
+func (p *provider) refresh(ctx, fetchCtx context.Context) error {
+	ch := p.group.DoChan("refresh", func() (any, error) {
+		p.mu.Lock()
+		p.attemptedAt = p.now()
+		p.mu.Unlock()
+
+		var doc discoveryDocument
+		if err := p.getJSON(fetchCtx, strings.TrimSuffix(p.settings.Issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+			return nil, err
+		}
+		if doc.Issuer != p.settings.Issuer {
+			return nil, fmt.Errorf("discovery document issuer %q does not match %q", doc.Issuer, p.settings.Issuer)
+		}
+		if doc.JWKSURI == "" {
+			return nil, fmt.Errorf("discovery document for %s has no jwks_uri", p.settings.Issuer)
+		}
+
+		var jwks models.JWKS
+		if err := p.getJSON(fetchCtx, doc.JWKSURI, &jwks); err != nil {
+			return nil, err
+		}
+		keys := make(map[string]publicKey, len(jwks.Keys))
+		for _, jwk := range jwks.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+			key, err := parseJWK(jwk)
+			if err != nil {
+				p.logger.Warn("Skipping unsupported OIDC key", "issuer", p.settings.Issuer, "kid", jwk.Kid, "error", err)
+				continue
+			}
+			keys[jwk.Kid] = key
+		}
+
+		p.mu.Lock()
+		p.keys = keys
+		p.mu.Unlock()
+		return nil, nil
+	})
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	// nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+// parseJWK は RSA と EC（P-256/P-384）の公開鍵を読み込みます
+func parseJWK(jwk models.JWK) (publicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !e.IsInt64() || n.BitLen() < 2048 {
+			return publicKey{}, errors.New("unsupported RSA key size or exponent")
+		}
+		return publicKey{alg: defaultAlg(jwk.Alg, "RS256"), key: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var alg string
+		switch jwk.Crv {
+		case "P-256":
+			curve, alg = elliptic.P256(), "ES256"
+		case "P-384":
+			curve, alg = elliptic.P384(), "ES384"
+		default:
+			return publicKey{}, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return publicKey{}, errors.New("EC point is not on the curve")
+		}
+		return publicKey{alg: defaultAlg(jwk.Alg, alg), key: &ecdsa.PublicKey{Curve: curve, X: x, Y: y}}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func defaultAlg(alg, fallback string) string {
+	if alg == "" {
+		return fallback
+	}
+	return alg
+}
@@ -0,0 +1,255 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownIssuer はトークンの発行者が設定された IdP のいずれでもないことを表します
+var ErrUnknownIssuer = errors.New("oidc: unknown issuer")
+
+// signingAlgorithms は IdP のトークンで受け付ける署名アルゴリズムです。共有鍵の HS 系は受け付けません
+var signingAlgorithms = []string{"RS256", "ES256", "ES384"}
+
+// Verifier は外部の IdP が発行したトークンを検証します
+type Verifier interface {
+	// Verify はトークンを検証し、クレームの対応に従ってロールを付与したユーザーを返します
+	// 発行者が設定された IdP でない場合は ErrUnknownIssuer を返します
+	Verify(ctx context.Context, tokenString string) (*Identity, error)
+}
+
+// Identity は検証した IdP のトークンが表すユーザーです
+type Identity struct {
+	// User.ID は IdP ごとに名前空間を分けた UserID です
+	User *models.User
+	// TokenID と IssuedAt はトークンの jti と iat で、失効の確認に使います
+	TokenID  string
+	IssuedAt time.Time
+}
+
+const userIDPrefix = "oidc:"
+
+// UserID は IdP のユーザーの識別子を、自身のユーザーや他の IdP のユーザーと衝突しない UserID にします
+func UserID(issuer, subject string) string {
+	return userIDPrefix + issuer + ":" + subject
+}
+
+// IsUserID は IdP のユーザーの UserID かを返します。IdP のユーザーはユーザーストアにはいません
+func IsUserID(userID string) bool {
+	return strings.HasPrefix(userID, userIDPrefix)
+}
+
+type verifier struct {
+	cfg       *config.AppConfig
+	logger    logger.Logger
+	providers map[string]*provider
+}
+
+// NewVerifier は oidc_providers_file の IdP を検証する Verifier を返します
+// 各 IdP の鍵はバックグラウンドで oidc_refresh_interval ごとに取得し直します。返す関数は取得を停止し、終了を待ちます
+func NewVerifier(cfg *config.AppConfig, logger logger.Logger) (Verifier, func(), error) {
+	settings, err := loadProviderSettings(cfg.OIDCProvidersFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpClient := &http.Client{
+		Timeout:   cfg.OIDCHTTPTimeout,
+		Transport: datadog.NewTransport(nil, "oidc"),
+	}
+	v := newVerifier(cfg, logger, settings, httpClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, p := range v.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v.refreshLoop(ctx, p)
+		}()
+	}
+	return v, func() {
+		cancel()
+		wg.Wait()
+	}, nil
+}
+
+func newVerifier(cfg *config.AppConfig, logger logger.Logger, settings []ProviderSettings, httpClient *http.Client) *verifier {
+	providers := make(map[string]*provider, len(settings))
+	for _, s := range settings {
+		if s.UserIDClaim == "" {
+			s.UserIDClaim = "sub"
+		}
+		if s.RoleClaim == "" {
+			s.RoleClaim = "groups"
+		}
+		providers[s.Issuer] = &provider{
+			settings:           s,
+			httpClient:         httpClient,
+			logger:             logger,
+			minRefreshInterval: cfg.OIDCMinRefreshInterval,
+			now:                time.Now,
+		}
+	}
+	return &verifier{
+		cfg:       cfg,
+		logger:    logger,
+		providers: providers,
+	}
+}
+
+func loadProviderSettings(path string) ([]ProviderSettings, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oidc providers file: %w", err)
+	}
+	var settings []ProviderSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode oidc providers file: %w", err)
+	}
+	for _, s := range settings {
+		if s.Issuer == "" || s.Audience == "" {
+			return nil, fmt.Errorf("oidc providers file: issuer and audience are required: %q", s.Issuer)
+		}
+	}
+	return settings, nil
+}
+
+// refreshLoop は起動直後と oidc_refresh_interval ごとに鍵を取得し直し、ctx が終了すると戻ります
+// 取得に失敗しても直前の鍵を使い続けます
+func (v *verifier) refreshLoop(ctx context.Context, p *provider) {
+	ticker := time.NewTicker(v.cfg.OIDCRefreshInterval)
+	defer ticker.Stop()
+	for {
+		// 停止時に取得の完了を待たないよう、ctx のキャンセルを取得に伝える
+		if err := p.refresh(ctx, ctx); err != nil && ctx.Err() == nil {
+			v.logger.Error("Failed to refresh OIDC provider keys", "issuer", p.settings.Issuer, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (v *verifier) Verify(ctx context.Context, tokenString string) (*Identity, error) {
+	p, err := v.providerFor(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(signingAlgorithms),
+		jwt.WithIssuer(p.settings.Issuer),
+		jwt.WithAudience(p.settings.Audience),
+		jwt.WithLeeway(v.cfg.JWTLeeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	claims := jwt.MapClaims{}
+	_, err = parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(ctx, kid)
+		if err != nil {
+			return nil, &services.TokenValidationError{Reason: services.TokenFailureUnknownKey, Err: err}
+		}
+		if token.Method.Alg() != key.alg {
+			return nil, &services.TokenValidationError{
+				Reason: services.TokenFailureAlgorithmNotAllowed,
+				Err:    fmt.Errorf("key %q uses %s, token uses %s", kid, key.alg, token.Method.Alg()),
+			}
+		}
+		return key.key, nil
+	})
+	if err != nil {
+		var validationErr *services.TokenValidationError
+		if errors.As(err, &validationErr) {
+			return nil, validationErr
+		}
+		return nil, services.NewTokenValidationError(err)
+	}
+
+	subject, _ := claims[p.settings.UserIDClaim].(string)
+	if subject == "" {
+		return nil, &services.TokenValidationError{
+			Reason: services.TokenFailureMissingClaim,
+			Err:    fmt.Errorf("claim %q is missing", p.settings.UserIDClaim),
+		}
+	}
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+	identity := &Identity{
+		User: &models.User{
+			ID:    UserID(p.settings.Issuer, subject),
+			Name:  name,
+			Email: email,
+			Roles: mapRoles(p.settings, claims[p.settings.RoleClaim]),
+		},
+	}
+	identity.TokenID, _ = claims["jti"].(string)
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		identity.IssuedAt = iat.Time
+	}
+	return identity, nil
+}
+
+// providerFor は署名を検証する前の iss から IdP を選びます。iss は検証時にも照合します
+func (v *verifier) providerFor(tokenString string) (*provider, error) {
+	if len(v.providers) == 0 {
+		return nil, ErrUnknownIssuer
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return nil, ErrUnknownIssuer
+	}
+	iss, _ := claims["iss"].(string)
+	p, ok := v.providers[iss]
+	if !ok {
+		return nil, ErrUnknownIssuer
+	}
+	return p, nil
+}
+
+// mapRoles は IdP のクレームの値を RoleMappings に従ってロールに変換します
+func mapRoles(settings ProviderSettings, claim any) []string {
+	var values []string
+	switch c := claim.(type) {
+	case string:
+		values = []string{c}
+	case []any:
+		for _, v := range c {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	roles := append([]string{}, settings.DefaultRoles...)
+	for _, value := range values {
+		for _, role := range settings.RoleMappings[value] {
+			if !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
@@ -0,0 +1,238 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIdP はディスカバリードキュメントと JWKS を返す IdP の代役です
+type testIdP struct {
+	server       *httptest.Server
+	mu           sync.Mutex
+	keys         map[string]*rsa.PrivateKey
+	jwksRequests atomic.Int32
+	// block が nil でない場合、JWKS の応答は block が閉じられるかリクエストがキャンセルされるまで返さない
+	block chan struct{}
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	idp := &testIdP{keys: map[string]*rsa.PrivateKey{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(discoveryDocument{Issuer: idp.server.URL, JWKSURI: idp.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		idp.jwksRequests.Add(1)
+		if idp.block != nil {
+			select {
+			case <-idp.block:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		var jwks models.JWKS
+		for kid, key := range idp.keys {
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(jwks)
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *testIdP) addKey(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.keys[kid] = key
+}
+
+func (idp *testIdP) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	idp.mu.Lock()
+	key := idp.keys[kid]
+	idp.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (idp *testIdP) claims(overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":    idp.server.URL,
+		"aud":    "api-client",
+		"sub":    "alice",
+		"email":  "alice@example.com",
+		"groups": []string{"sso-editors", "unmapped"},
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+	return claims
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	ctx := context.Background()
+	idp := newTestIdP(t)
+	idp.addKey(t, "key-1")
+
+	cfg := &config.AppConfig{JWTLeeway: 30 * time.Second}
+	settings := []ProviderSettings{{
+		Issuer:       idp.server.URL,
+		Audience:     "api-client",
+		RoleMappings: map[string][]string{"sso-editors": {"role:teamA:editor"}},
+		DefaultRoles: []string{"role:default:viewer"},
+	}}
+	target := newVerifier(cfg, logger.NewLogger(&config.AppConfig{}), settings, idp.server.Client())
+
+	t.Run("正常系: IdP のトークンをクレームの対応に従ったロールのユーザーにする", func(t *testing.T) {
+		issuedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+		identity, err := target.Verify(ctx, idp.sign(t, "key-1", idp.claims(jwt.MapClaims{"jti": "token-1", "iat": issuedAt.Unix()})))
+
+		require.NoError(t, err)
+		assert.Equal(t, "oidc:"+idp.server.URL+":alice", identity.User.ID, "IdP の sub は発行者で名前空間を分ける")
+		assert.Equal(t, "alice@example.com", identity.User.Email)
+		assert.Equal(t, []string{"role:default:viewer", "role:teamA:editor"}, identity.User.Roles)
+		assert.Equal(t, "token-1", identity.TokenID)
+		assert.True(t, issuedAt.Equal(identity.IssuedAt))
+	})
+
+	t.Run("正常系: IdP の鍵のローテーションに未知の kid で追従する", func(t *testing.T) {
+		_, err := target.Verify(ctx, idp.sign(t, "key-1", idp.claims(nil)))
+		require.NoError(t, err)
+		idp.addKey(t, "key-2")
+
+		identity, err := target.Verify(ctx, idp.sign(t, "key-2", idp.claims(nil)))
+
+		require.NoError(t, err)
+		assert.Equal(t, UserID(idp.server.URL, "alice"), identity.User.ID)
+	})
+
+	t.Run("異常系: audience が異なるトークンは拒否する", func(t *testing.T) {
+		_, err := target.Verify(ctx, idp.sign(t, "key-1", idp.claims(jwt.MapClaims{"aud": "other-client"})))
+
+		assert.Equal(t, services.TokenFailureInvalidAudience, services.TokenFailureReason(err))
+	})
+
+	t.Run("異常系: 期限切れのトークンは拒否する", func(t *testing.T) {
+		_, err := target.Verify(ctx, idp.sign(t, "key-1", idp.claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})))
+
+		assert.Equal(t, services.TokenFailureExpired, services.TokenFailureReason(err))
+	})
+
+	t.Run("異常系: 設定されていない発行者は ErrUnknownIssuer", func(t *testing.T) {
+		_, err := target.Verify(ctx, idp.sign(t, "key-1", idp.claims(jwt.MapClaims{"iss": "https://unknown.example.com"})))
+
+		assert.ErrorIs(t, err, ErrUnknownIssuer)
+	})
+}
+
+func TestVerifier_UnknownKeyRefreshInterval(t *testing.T) {
+	ctx := context.Background()
+	idp := newTestIdP(t)
+	idp.addKey(t, "key-1")
+
+	cfg := &config.AppConfig{OIDCMinRefreshInterval: time.Hour}
+	settings := []ProviderSettings{{Issuer: idp.server.URL, Audience: "api-client"}}
+	target := newVerifier(cfg, logger.NewLogger(&config.AppConfig{}), settings, idp.server.Client())
+
+	t.Run("異常系: 未知の kid による再取得は最短間隔まで行わない", func(t *testing.T) {
+		_, err := target.Verify(ctx, idp.sign(t, "key-1", idp.claims(nil)))
+		require.NoError(t, err)
+		idp.addKey(t, "key-2")
+
+		for range 3 {
+			_, err = target.Verify(ctx, idp.sign(t, "key-2", idp.claims(nil)))
+			assert.Equal(t, services.TokenFailureUnknownKey, services.TokenFailureReason(err))
+		}
+		assert.Equal(t, int32(1), idp.jwksRequests.Load())
+	})
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Run("正常系: 返された関数を呼ぶと鍵の定期的な取得を停止する", func(t *testing.T) {
+		idp := newTestIdP(t)
+		idp.addKey(t, "key-1")
+		path := filepath.Join(t.TempDir(), "providers.json")
+		data, err := json.Marshal([]ProviderSettings{{Issuer: idp.server.URL, Audience: "api-client"}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		cfg := &config.AppConfig{OIDCProvidersFile: path, OIDCRefreshInterval: 10 * time.Millisecond, OIDCHTTPTimeout: time.Second}
+		_, cleanup, err := NewVerifier(cfg, logger.NewLogger(&config.AppConfig{}))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return idp.jwksRequests.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+		cleanup()
+		stopped := idp.jwksRequests.Load()
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(t, stopped, idp.jwksRequests.Load())
+	})
+
+	t.Run("正常系: 鍵の取得中でも返された関数は取得の完了を待たずに戻る", func(t *testing.T) {
+		idp := newTestIdP(t)
+		idp.addKey(t, "key-1")
+		idp.block = make(chan struct{})
+		t.Cleanup(func() { close(idp.block) })
+		path := filepath.Join(t.TempDir(), "providers.json")
+		data, err := json.Marshal([]ProviderSettings{{Issuer: idp.server.URL, Audience: "api-client"}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		cfg := &config.AppConfig{OIDCProvidersFile: path, OIDCRefreshInterval: time.Hour, OIDCHTTPTimeout: time.Minute}
+		_, cleanup, err := NewVerifier(cfg, logger.NewLogger(&config.AppConfig{}))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return idp.jwksRequests.Load() >= 1 }, time.Second, 5*time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			cleanup()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("cleanup waited for the in-flight JWKS fetch")
+		}
+	})
+}
@@ -0,0 +1,7 @@
+package oidc
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(
+	NewVerifier,
+)
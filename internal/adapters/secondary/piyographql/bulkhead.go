@@ -0,0 +1,83 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/resilience"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+)
+
+// bulkheadRetryAfter は満杯で拒否した場合にクライアントへ返す待機時間です
+const bulkheadRetryAfter = time.Second
+
+// bulkheadClient は upstream への同時呼び出し数を制限し、あふれた呼び出しを拒否します
+type bulkheadClient struct {
+	next     Client
+	bulkhead *resilience.Bulkhead
+}
+
+func newBulkheadClient(next Client, cfg *config.AppConfig, metricsManager *datadog.MetricsManager) Client {
+	return &bulkheadClient{
+		next: next,
+		bulkhead: resilience.NewBulkhead(resilience.BulkheadSettings{
+			Name:          breakerName,
+			MaxConcurrent: cfg.PiyoGraphQLBulkheadMaxConcurrent,
+			MaxQueue:      cfg.PiyoGraphQLBulkheadMaxQueue,
+			MaxWait:       cfg.PiyoGraphQLBulkheadMaxWait,
+			OnChange: func(name string, inFlight, queued int) {
+				tags := []string{fmt.Sprintf("bulkhead:%s", name)}
+				metricsManager.Gauge("bulkhead.in_flight", float64(inFlight), tags)
+				metricsManager.Gauge("bulkhead.queued", float64(queued), tags)
+			},
+		}),
+	}
+}
+
+func (c *bulkheadClient) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	release, err := c.bulkhead.Acquire(ctx)
+	if err != nil {
+		return nil, toSaturatedError(err)
+	}
+	defer release()
+	return c.next.GetSample(ctx, ID)
+}
+
+func (c *bulkheadClient) GetSamples(ctx context.Context, IDs []string) ([]*models.Sample, []error) {
+	release, err := c.bulkhead.Acquire(ctx)
+	if err != nil {
+		errs := make([]error, len(IDs))
+		for i := range errs {
+			errs[i] = toSaturatedError(err)
+		}
+		return make([]*models.Sample, len(IDs)), errs
+	}
+	defer release()
+	return c.next.GetSamples(ctx, IDs)
+}
+
+func (c *bulkheadClient) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
+	release, err := c.bulkhead.Acquire(ctx)
+	if err != nil {
+		return nil, toSaturatedError(err)
+	}
+	defer release()
+	return c.next.ListSample(ctx, offset, limit)
+}
+
+func (c *bulkheadClient) InvalidateSample(ID string) {
+	c.next.InvalidateSample(ID)
+}
+
+func toSaturatedError(err error) error {
+	var fullErr *resilience.BulkheadFullError
+	if errors.As(err, &fullErr) && fullErr.Queued && errors.Is(err, context.DeadlineExceeded) {
+		return apperrors.NewTimeoutError("Upstream GraphQL request timed out", err)
+	}
+	return apperrors.NewServiceUnavailableError("Upstream GraphQL service is saturated", err).WithRetryAfter(bulkheadRetryAfter)
+}
@@ -0,0 +1,226 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/common/freshness"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/cache"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"golang.org/x/sync/singleflight"
+)
+
+// sampleCacheEntry は GetSample の結果を表します
+// sample が nil の場合は NotFound をキャッシュしたネガティブエントリです
+type sampleCacheEntry struct {
+	sample    *models.Sample
+	fetchedAt time.Time
+}
+
+// cachingClient は GetSample の同時呼び出しを 1 回の upstream 呼び出しにまとめ、結果をキャッシュします
+// 有効期限切れのエントリも staleMaxAge の間は保持し、次の場合に古いデータとして返します
+//   - 期限切れから staleGrace 以内: 古いデータを即座に返し、バックグラウンドで再取得する
+//   - upstream が失敗した場合: 再取得に失敗した旨を記録して古いデータを返す
+type cachingClient struct {
+	next           Client
+	metricsManager *datadog.MetricsManager
+	cache          *cache.LRU[string, sampleCacheEntry]
+	ttl            time.Duration
+	negativeTTL    time.Duration
+	staleGrace     time.Duration
+	staleMaxAge    time.Duration
+	now            func() time.Time
+	group          singleflight.Group
+	// epoch は書き込みによる無効化のたびに進み、無効化前に開始した取得結果の保存を防ぎます
+	epoch atomic.Uint64
+}
+
+func newCachingClient(next Client, cfg *config.AppConfig, metricsManager *datadog.MetricsManager) Client {
+	return &cachingClient{
+		next:           next,
+		metricsManager: metricsManager,
+		cache:          cache.NewLRU[string, sampleCacheEntry](cfg.PiyoGraphQLCacheSize),
+		ttl:            cfg.PiyoGraphQLCacheTTL,
+		negativeTTL:    cfg.PiyoGraphQLCacheNegativeTTL,
+		staleGrace:     cfg.PiyoGraphQLCacheStaleGrace,
+		staleMaxAge:    cfg.PiyoGraphQLCacheStaleMaxAge,
+		now:            time.Now,
+	}
+}
+
+func (c *cachingClient) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	entry, ok := c.cache.Get(ID)
+	if ok {
+		if entry.sample == nil {
+			c.recordCache("negative_hit")
+			return nil, apperrors.NewNotFoundError("Sample not found", nil)
+		}
+		age := c.now().Sub(entry.fetchedAt)
+		if age < c.ttl {
+			c.recordCache("hit")
+			return cloneSample(entry.sample), nil
+		}
+		if age < c.ttl+c.staleGrace {
+			c.recordCache("stale")
+			c.revalidate(ctx, ID)
+			freshness.MarkStale(ctx, age, true)
+			return cloneSample(entry.sample), nil
+		}
+	}
+	c.recordCache("miss")
+
+	select {
+	case <-ctx.Done():
+		err := contextError(ctx.Err())
+		if stale, ok := c.staleOnError(ctx, entry, err); ok {
+			return stale, nil
+		}
+		return nil, err
+	case res := <-c.fetch(ctx, ID):
+		if res.Shared {
+			c.recordCache("coalesced")
+		}
+		if res.Err != nil {
+			if stale, ok := c.staleOnError(ctx, entry, res.Err); ok {
+				return stale, nil
+			}
+			return nil, res.Err
+		}
+		return cloneSample(res.Val.(*models.Sample)), nil
+	}
+}
+
+func (c *cachingClient) GetSamples(ctx context.Context, IDs []string) ([]*models.Sample, []error) {
+	samples := make([]*models.Sample, len(IDs))
+	errs := make([]error, len(IDs))
+
+	now := c.now()
+	var missIDs []string
+	var missIndexes []int
+	var missEntries []sampleCacheEntry
+	for i, ID := range IDs {
+		entry, ok := c.cache.Get(ID)
+		switch {
+		case !ok:
+			c.recordCache("miss")
+		case entry.sample == nil:
+			c.recordCache("negative_hit")
+			errs[i] = apperrors.NewNotFoundError("Sample not found", nil)
+			continue
+		case now.Sub(entry.fetchedAt) < c.ttl:
+			c.recordCache("hit")
+			samples[i] = cloneSample(entry.sample)
+			continue
+		case now.Sub(entry.fetchedAt) < c.ttl+c.staleGrace:
+			c.recordCache("stale")
+			c.revalidate(ctx, ID)
+			freshness.MarkStale(ctx, now.Sub(entry.fetchedAt), true)
+			samples[i] = cloneSample(entry.sample)
+			continue
+		default:
+			c.recordCache("miss")
+		}
+		missIDs = append(missIDs, ID)
+		missIndexes = append(missIndexes, i)
+		missEntries = append(missEntries, entry)
+	}
+	if len(missIDs) == 0 {
+		return samples, errs
+	}
+
+	epoch := c.epoch.Load()
+	fetched, fetchErrs := c.next.GetSamples(ctx, missIDs)
+	for j, i := range missIndexes {
+		if fetchErrs[j] != nil {
+			if stale, ok := c.staleOnError(ctx, missEntries[j], fetchErrs[j]); ok {
+				samples[i] = stale
+				continue
+			}
+		}
+		c.store(missIDs[j], epoch, fetched[j], fetchErrs[j])
+		samples[i] = fetched[j]
+		errs[i] = fetchErrs[j]
+	}
+	return samples, errs
+}
+
+func (c *cachingClient) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
+	return c.next.ListSample(ctx, offset, limit)
+}
+
+// InvalidateSample はエントリを削除し、無効化の前に開始した取得の結果も保存しないようにします
+func (c *cachingClient) InvalidateSample(ID string) {
+	c.epoch.Add(1)
+	c.group.Forget(ID)
+	c.cache.Delete(ID)
+	c.next.InvalidateSample(ID)
+}
+
+// fetch は同じ ID の取得を 1 回の upstream 呼び出しにまとめ、結果をキャッシュに保存します
+func (c *cachingClient) fetch(ctx context.Context, ID string) <-chan singleflight.Result {
+	// 呼び出し元ごとにキャンセルできるよう、共有する取得処理は呼び出し元のキャンセルから切り離す
+	fetchCtx := context.WithoutCancel(ctx)
+	return c.group.DoChan(ID, func() (any, error) {
+		epoch := c.epoch.Load()
+		sample, err := c.next.GetSample(fetchCtx, ID)
+		c.store(ID, epoch, sample, err)
+		return sample, err
+	})
+}
+
+// revalidate はバックグラウンドで再取得します。取得中の場合は新たに呼び出しません
+func (c *cachingClient) revalidate(ctx context.Context, ID string) {
+	c.fetch(ctx, ID)
+}
+
+// staleOnError は upstream の失敗時に、保持している古いデータを返せるか判定します
+func (c *cachingClient) staleOnError(ctx context.Context, entry sampleCacheEntry, err error) (*models.Sample, bool) {
+	if entry.sample == nil || !isUpstreamFailure(err) {
+		return nil, false
+	}
+	age := c.now().Sub(entry.fetchedAt)
+	if age >= c.ttl+c.staleMaxAge {
+		return nil, false
+	}
+	c.recordCache("stale_if_error")
+	freshness.MarkStale(ctx, age, false)
+	return cloneSample(entry.sample), true
+}
+
+func (c *cachingClient) store(ID string, epoch uint64, sample *models.Sample, err error) {
+	if c.epoch.Load() != epoch {
+		return
+	}
+	var appErr *apperrors.AppError
+	switch {
+	case err == nil && c.ttl > 0:
+		// 期限切れ後も upstream 障害時に返せるよう staleMaxAge の間は保持する
+		c.cache.Set(ID, sampleCacheEntry{sample: cloneSample(sample), fetchedAt: c.now()}, c.ttl+c.staleMaxAge)
+	case errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound:
+		c.cache.Set(ID, sampleCacheEntry{fetchedAt: c.now()}, c.negativeTTL)
+	}
+}
+
+func (c *cachingClient) recordCache(result string) {
+	c.metricsManager.Count("piyographql.cache", 1, []string{
+		"operation:GetSample",
+		"result:" + result,
+	})
+}
+
+// cloneSample はキャッシュ内のデータが呼び出し元から変更されないようにコピーを返します
+func cloneSample(s *models.Sample) *models.Sample {
+	if s == nil {
+		return nil
+	}
+	cloned := *s
+	if s.ArrayVal != nil {
+		cloned.ArrayVal = append([]string(nil), s.ArrayVal...)
+	}
+	return &cloned
+}
@@ -0,0 +1,241 @@
+package piyographql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/common/freshness"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubClient は GetSample の呼び出し回数を数えるテスト用の Client です
+type stubClient struct {
+	Client
+	calls   atomic.Int32
+	release chan struct{}
+	get     func(id string) (*models.Sample, error)
+}
+
+func (s *stubClient) GetSample(_ context.Context, ID string) (*models.Sample, error) {
+	s.calls.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	return s.get(ID)
+}
+
+func (s *stubClient) InvalidateSample(string) {}
+
+func newTestCachingClient(next Client) Client {
+	return newCachingClient(next, &config.AppConfig{
+		PiyoGraphQLCacheSize:        10,
+		PiyoGraphQLCacheTTL:         time.Minute,
+		PiyoGraphQLCacheNegativeTTL: time.Minute,
+	}, &datadog.MetricsManager{})
+}
+
+func TestCachingClient(t *testing.T) {
+	found := func(id string) (*models.Sample, error) {
+		return &models.Sample{ID: id, StringVal: "v1"}, nil
+	}
+
+	t.Run("2回目以降はキャッシュから返す", func(t *testing.T) {
+		stub := &stubClient{get: found}
+		target := newTestCachingClient(stub)
+
+		for range 3 {
+			sample, err := target.GetSample(context.Background(), "abc123")
+			assert.NoError(t, err)
+			assert.Equal(t, "abc123", sample.ID)
+		}
+
+		assert.Equal(t, int32(1), stub.calls.Load())
+	})
+
+	t.Run("NotFound はネガティブキャッシュされる", func(t *testing.T) {
+		stub := &stubClient{get: func(string) (*models.Sample, error) {
+			return nil, apperrors.NewNotFoundError("Sample not found", nil)
+		}}
+		target := newTestCachingClient(stub)
+
+		for range 2 {
+			_, err := target.GetSample(context.Background(), "missing")
+			assertAppError(t, err, apperrors.ErrorTypeNotFound)
+		}
+
+		assert.Equal(t, int32(1), stub.calls.Load())
+	})
+
+	t.Run("upstream エラーはキャッシュされない", func(t *testing.T) {
+		stub := &stubClient{get: func(string) (*models.Sample, error) {
+			return nil, apperrors.NewExternalServiceError("failed", nil)
+		}}
+		target := newTestCachingClient(stub)
+
+		for range 2 {
+			_, err := target.GetSample(context.Background(), "abc123")
+			assertAppError(t, err, apperrors.ErrorTypeExternalService)
+		}
+
+		assert.Equal(t, int32(2), stub.calls.Load())
+	})
+
+	t.Run("同時の呼び出しは 1 回にまとめられる", func(t *testing.T) {
+		stub := &stubClient{get: found, release: make(chan struct{})}
+		target := newTestCachingClient(stub)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sample, err := target.GetSample(context.Background(), "abc123")
+				assert.NoError(t, err)
+				assert.Equal(t, "abc123", sample.ID)
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(stub.release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), stub.calls.Load())
+	})
+
+	t.Run("呼び出し元のキャンセルはタイムアウトとして扱わない", func(t *testing.T) {
+		stub := &stubClient{get: found, release: make(chan struct{})}
+		defer close(stub.release)
+		target := newTestCachingClient(stub)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := target.GetSample(ctx, "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeCanceled)
+		assert.False(t, isUpstreamFailure(err))
+	})
+
+	t.Run("デッドラインを超えた場合はタイムアウト", func(t *testing.T) {
+		stub := &stubClient{get: found, release: make(chan struct{})}
+		defer close(stub.release)
+		target := newTestCachingClient(stub)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := target.GetSample(ctx, "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeTimeout)
+	})
+
+	t.Run("無効化するとエントリが削除される", func(t *testing.T) {
+		stub := &stubClient{get: found}
+		target := newTestCachingClient(stub)
+		_, _ = target.GetSample(context.Background(), "abc123")
+
+		target.InvalidateSample("abc123")
+		_, _ = target.GetSample(context.Background(), "abc123")
+
+		assert.Equal(t, int32(2), stub.calls.Load())
+	})
+}
+
+func TestCachingClient_Stale(t *testing.T) {
+	newTarget := func(stub *stubClient) (*cachingClient, *time.Time) {
+		target := newCachingClient(stub, &config.AppConfig{
+			PiyoGraphQLCacheSize:        10,
+			PiyoGraphQLCacheTTL:         time.Minute,
+			PiyoGraphQLCacheNegativeTTL: time.Minute,
+			PiyoGraphQLCacheStaleGrace:  time.Minute,
+			PiyoGraphQLCacheStaleMaxAge: time.Hour,
+		}, &datadog.MetricsManager{}).(*cachingClient)
+		now := time.Now()
+		target.now = func() time.Time { return now }
+		return target, &now
+	}
+
+	t.Run("猶予期間内は古いデータを返しバックグラウンドで再取得する", func(t *testing.T) {
+		var version atomic.Int32
+		stub := &stubClient{get: func(id string) (*models.Sample, error) {
+			return &models.Sample{ID: id, IntVal: int(version.Add(1))}, nil
+		}}
+		target, now := newTarget(stub)
+		_, _ = target.GetSample(context.Background(), "abc123")
+		*now = now.Add(90 * time.Second)
+
+		ctx := freshness.WithRecorder(context.Background())
+		sample, err := target.GetSample(ctx, "abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sample.IntVal)
+		info := freshness.FromContext(ctx)
+		assert.True(t, info.Stale)
+		assert.True(t, info.Revalidating)
+		assert.Equal(t, 90*time.Second, info.Age)
+		assert.Eventually(t, func() bool { return stub.calls.Load() == 2 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("upstream の失敗時は猶予期間を過ぎても古いデータを返す", func(t *testing.T) {
+		var fail atomic.Bool
+		stub := &stubClient{get: func(id string) (*models.Sample, error) {
+			if fail.Load() {
+				return nil, apperrors.NewExternalServiceError("failed", nil)
+			}
+			return &models.Sample{ID: id}, nil
+		}}
+		target, now := newTarget(stub)
+		_, _ = target.GetSample(context.Background(), "abc123")
+		fail.Store(true)
+		*now = now.Add(10 * time.Minute)
+
+		ctx := freshness.WithRecorder(context.Background())
+		sample, err := target.GetSample(ctx, "abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", sample.ID)
+		info := freshness.FromContext(ctx)
+		assert.True(t, info.Stale)
+		assert.False(t, info.Revalidating)
+	})
+
+	t.Run("保持期間を過ぎたデータは返さない", func(t *testing.T) {
+		var fail atomic.Bool
+		stub := &stubClient{get: func(id string) (*models.Sample, error) {
+			if fail.Load() {
+				return nil, apperrors.NewExternalServiceError("failed", nil)
+			}
+			return &models.Sample{ID: id}, nil
+		}}
+		target, now := newTarget(stub)
+		_, _ = target.GetSample(context.Background(), "abc123")
+		fail.Store(true)
+		*now = now.Add(2 * time.Hour)
+
+		_, err := target.GetSample(context.Background(), "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+	})
+
+	t.Run("NotFound は古いデータで置き換えない", func(t *testing.T) {
+		var gone atomic.Bool
+		stub := &stubClient{get: func(id string) (*models.Sample, error) {
+			if gone.Load() {
+				return nil, apperrors.NewNotFoundError("Sample not found", nil)
+			}
+			return &models.Sample{ID: id}, nil
+		}}
+		target, now := newTarget(stub)
+		_, _ = target.GetSample(context.Background(), "abc123")
+		gone.Store(true)
+		*now = now.Add(10 * time.Minute)
+
+		_, err := target.GetSample(context.Background(), "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeNotFound)
+	})
+}
@@ -0,0 +1,261 @@
+package piyographql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+const (
+	cassetteModeOff    = "off"
+	cassetteModeRecord = "record"
+	cassetteModeReplay = "replay"
+
+	redacted = "[REDACTED]"
+)
+
+// sensitiveVariableKeys を名前に含む変数の値は記録前に伏せ字にします
+var sensitiveVariableKeys = []string{"password", "secret", "token", "credential", "apikey", "api_key"}
+
+// cassette はリクエストとレスポンスの 1 組を表すファイルの内容です
+type cassette struct {
+	OperationName string           `json:"operationName"`
+	Variables     map[string]any   `json:"variables,omitempty"`
+	Response      cassetteResponse `json:"response"`
+}
+
+type cassetteResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       json.RawMessage   `json:"body"`
+}
+
+// CassetteMissError は replay モードで一致する記録が見つからなかったことを表します
+type CassetteMissError struct {
+	OperationName string
+	Variables     string
+	Path          string
+}
+
+func (e *CassetteMissError) Error() string {
+	return fmt.Sprintf("no cassette recording matches operation %q with variables %s (expected %s); record it with piyo_graphql_cassette_mode=record",
+		e.OperationName, e.Variables, e.Path)
+}
+
+// cassetteTransport は GraphQL リクエストをオペレーション名と変数で照合し、
+// record モードでは upstream のレスポンスをファイルに保存し、replay モードでは保存済みのレスポンスを返します
+type cassetteTransport struct {
+	mode string
+	dir  string
+	// secrets は記録するレスポンスに含まれていた場合に伏せ字にする値です
+	secrets []string
+	next    http.RoundTripper
+	mu      sync.Mutex
+}
+
+// newCassetteTransport は設定されたモードに応じて next をラップします。off の場合は next をそのまま返します
+func newCassetteTransport(cfg *config.AppConfig, next http.RoundTripper) http.RoundTripper {
+	switch cfg.PiyoGraphQLCassetteMode {
+	case cassetteModeRecord, cassetteModeReplay:
+		t := &cassetteTransport{
+			mode: cfg.PiyoGraphQLCassetteMode,
+			dir:  cfg.PiyoGraphQLCassetteDir,
+			next: next,
+		}
+		if cfg.PiyoGraphQLAuthToken != "" {
+			t.secrets = append(t.secrets, cfg.PiyoGraphQLAuthToken)
+		}
+		return t
+	default:
+		return next
+	}
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var gqlReq graphqlRequest
+	if err := json.Unmarshal(reqBody, &gqlReq); err != nil {
+		return nil, fmt.Errorf("cassette: failed to decode graphql request: %w", err)
+	}
+	variables := scrubVariables(gqlReq.Variables)
+	path, canonical, err := t.path(gqlReq.OperationName, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == cassetteModeReplay {
+		return t.replay(req, path, gqlReq.OperationName, canonical)
+	}
+	return t.record(req, reqBody, path, gqlReq.OperationName, variables)
+}
+
+func (t *cassetteTransport) replay(req *http.Request, path, operationName, canonical string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &CassetteMissError{OperationName: operationName, Variables: canonical, Path: path}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read %s: %w", path, err)
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cassette: failed to decode %s: %w", path, err)
+	}
+
+	header := make(http.Header, len(c.Response.Headers))
+	for k, v := range c.Response.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		StatusCode:    c.Response.StatusCode,
+		Status:        fmt.Sprintf("%d %s", c.Response.StatusCode, http.StatusText(c.Response.StatusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Response.Body)),
+		ContentLength: int64(len(c.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *cassetteTransport) record(req *http.Request, reqBody []byte, path, operationName string, variables map[string]any) (*http.Response, error) {
+	outReq := req.Clone(req.Context())
+	outReq.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	// nolint:errcheck
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// 5xx などの一時的な失敗は記録しない
+	if resp.StatusCode >= 500 {
+		return resp, nil
+	}
+
+	// リクエストヘッダーは保存せず、レスポンス中の認証情報も伏せ字にする
+	scrubbedBody := respBody
+	for _, secret := range t.secrets {
+		scrubbedBody = bytes.ReplaceAll(scrubbedBody, []byte(secret), []byte(redacted))
+	}
+	body := json.RawMessage(scrubbedBody)
+	if !json.Valid(scrubbedBody) {
+		body, _ = json.Marshal(string(scrubbedBody))
+	}
+	c := cassette{
+		OperationName: operationName,
+		Variables:     variables,
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    map[string]string{"Content-Type": resp.Header.Get("Content-Type")},
+			Body:       body,
+		},
+	}
+	if err := t.write(path, c); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *cassetteTransport) write(path string, c cassette) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: failed to encode: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cassette: failed to create directory: %w", err)
+	}
+	// 書き込み途中のファイルを replay が読まないよう、一時ファイル経由で置き換える
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cassette: failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("cassette: failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// path はオペレーション名と正規化した変数からファイルパスを決めます
+// encoding/json は map のキーをソートして出力するため、変数の順序に依存しません
+func (t *cassetteTransport) path(operationName string, variables map[string]any) (string, string, error) {
+	canonical, err := json.Marshal(variables)
+	if err != nil {
+		return "", "", fmt.Errorf("cassette: failed to encode variables: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(operationName+"\n"), canonical...))
+	name := fmt.Sprintf("%s-%s.json", operationName, hex.EncodeToString(sum[:8]))
+	return filepath.Join(t.dir, name), string(canonical), nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	// nolint:errcheck
+	defer req.Body.Close()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read request: %w", err)
+	}
+	return body, nil
+}
+
+// scrubVariables は秘匿情報らしき変数の値を伏せ字にしたコピーを返します
+// 照合にも伏せ字後の値を使うため、record と replay で同じファイルに解決されます
+func scrubVariables(variables map[string]any) map[string]any {
+	if variables == nil {
+		return nil
+	}
+	scrubbed := make(map[string]any, len(variables))
+	for k, v := range variables {
+		switch {
+		case isSensitiveKey(k):
+			scrubbed[k] = redacted
+		default:
+			if nested, ok := v.(map[string]any); ok {
+				scrubbed[k] = scrubVariables(nested)
+			} else {
+				scrubbed[k] = v
+			}
+		}
+	}
+	return scrubbed
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, s := range sensitiveVariableKeys {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
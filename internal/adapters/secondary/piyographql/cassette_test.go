@@ -0,0 +1,90 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Cassette(t *testing.T) {
+	withCassette := func(mode, dir string) func(cfg *config.AppConfig) {
+		return func(cfg *config.AppConfig) {
+			cfg.PiyoGraphQLCassetteMode = mode
+			cfg.PiyoGraphQLCassetteDir = dir
+		}
+	}
+
+	t.Run("正常系: record したレスポンスを replay で upstream なしに返す", func(t *testing.T) {
+		dir := t.TempDir()
+		var calls atomic.Int32
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"sample":{"id":"1","stringVal":"echo test-token"}}}`))
+		}
+
+		recorder := newTestClient(t, handler, withCassette(cassetteModeRecord, dir))
+		recorded, err := recorder.GetSample(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "echo test-token", recorded.StringVal)
+
+		files, err := filepath.Glob(filepath.Join(dir, "GetSample-*.json"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		data, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "test-token")
+		assert.Contains(t, string(data), redacted)
+
+		replayer := newTestClient(t, handler, withCassette(cassetteModeReplay, dir))
+		replayed, err := replayer.GetSample(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "1", replayed.ID)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("異常系: 一致する記録がない場合は記録の場所を示して失敗する", func(t *testing.T) {
+		dir := t.TempDir()
+		var calls atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+		}, withCassette(cassetteModeReplay, dir))
+
+		_, err := c.GetSample(context.Background(), "missing")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+		assert.Equal(t, "Upstream GraphQL GetSample has no cassette recording", err.Error())
+		assert.NotContains(t, err.Error(), dir, "記録の場所はクライアントに返さない")
+		assert.False(t, isUpstreamFailure(err), "記録がないことはサーキットブレーカーの失敗に数えない")
+		var missErr *CassetteMissError
+		if assert.True(t, errors.As(err.(*apperrors.AppError).RawError, &missErr)) {
+			assert.Equal(t, "GetSample", missErr.OperationName)
+			assert.Equal(t, `{"id":"missing"}`, missErr.Variables)
+			assert.Equal(t, dir, filepath.Dir(missErr.Path))
+		}
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("正常系: 秘匿情報らしき変数は伏せ字にして照合する", func(t *testing.T) {
+		scrubbed := scrubVariables(map[string]any{
+			"id":    "1",
+			"input": map[string]any{"password": "p@ss", "email": "a@example.com"},
+			"token": "abc",
+		})
+
+		assert.Equal(t, map[string]any{
+			"id":    "1",
+			"input": map[string]any{"password": redacted, "email": "a@example.com"},
+			"token": redacted,
+		}, scrubbed)
+	})
+}
@@ -0,0 +1,142 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/resilience"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+)
+
+const breakerName = "piyographql"
+
+// CircuitBreaker は piyographql 用のサーキットブレーカーです
+// readiness の判定にも利用するため、クライアントとは別に提供します
+type CircuitBreaker struct {
+	*resilience.CircuitBreaker
+}
+
+func NewCircuitBreaker(cfg *config.AppConfig, logger logger.Logger, metricsManager *datadog.MetricsManager) *CircuitBreaker {
+	return &CircuitBreaker{
+		CircuitBreaker: resilience.NewCircuitBreaker(resilience.CircuitBreakerSettings{
+			Name:                 breakerName,
+			FailureRateThreshold: cfg.PiyoGraphQLBreakerFailureRate,
+			MinimumRequests:      cfg.PiyoGraphQLBreakerMinRequests,
+			Window:               cfg.PiyoGraphQLBreakerWindow,
+			OpenTimeout:          cfg.PiyoGraphQLBreakerOpenTimeout,
+			HalfOpenMaxRequests:  cfg.PiyoGraphQLBreakerHalfOpenRequests,
+			OnStateChange: func(name string, from, to resilience.State) {
+				logger.Warn("Circuit breaker state changed",
+					"breaker", name,
+					"from", from.String(),
+					"to", to.String(),
+				)
+				tags := []string{
+					fmt.Sprintf("breaker:%s", name),
+					fmt.Sprintf("from:%s", from),
+					fmt.Sprintf("to:%s", to),
+				}
+				metricsManager.Count("circuit_breaker.state_change", 1, tags)
+				metricsManager.Gauge("circuit_breaker.state", float64(to), []string{fmt.Sprintf("breaker:%s", name)})
+			},
+		}),
+	}
+}
+
+// circuitBreakerClient はブレーカーが open の間、upstream を呼ばずに即座に失敗させます
+type circuitBreakerClient struct {
+	next    Client
+	breaker *CircuitBreaker
+}
+
+func newCircuitBreakerClient(next Client, breaker *CircuitBreaker) Client {
+	return &circuitBreakerClient{
+		next:    next,
+		breaker: breaker,
+	}
+}
+
+func (c *circuitBreakerClient) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, toUnavailableError(err)
+	}
+
+	sample, err := c.next.GetSample(ctx, ID)
+	done(!isUpstreamFailure(err))
+	return sample, err
+}
+
+func (c *circuitBreakerClient) GetSamples(ctx context.Context, IDs []string) ([]*models.Sample, []error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		errs := make([]error, len(IDs))
+		for i := range errs {
+			errs[i] = toUnavailableError(err)
+		}
+		return make([]*models.Sample, len(IDs)), errs
+	}
+
+	samples, errs := c.next.GetSamples(ctx, IDs)
+	success := true
+	for _, err := range errs {
+		if isUpstreamFailure(err) {
+			success = false
+			break
+		}
+	}
+	done(success)
+	return samples, errs
+}
+
+func (c *circuitBreakerClient) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, toUnavailableError(err)
+	}
+
+	samples, err := c.next.ListSample(ctx, offset, limit)
+	done(!isUpstreamFailure(err))
+	return samples, err
+}
+
+func (c *circuitBreakerClient) InvalidateSample(ID string) {
+	c.next.InvalidateSample(ID)
+}
+
+// isUpstreamFailure はブレーカーの失敗として数えるべきエラーかを返します
+// NotFound などの業務的なエラーは upstream の健全性とは無関係のため成功として扱います
+func isUpstreamFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return true
+	}
+	// cassette に記録がないのは upstream の障害ではない
+	var missErr *CassetteMissError
+	if errors.As(appErr.RawError, &missErr) {
+		return false
+	}
+	switch appErr.Type {
+	case apperrors.ErrorTypeExternalService, apperrors.ErrorTypeTimeout, apperrors.ErrorTypeServiceUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+func toUnavailableError(err error) error {
+	appErr := apperrors.NewServiceUnavailableError("Upstream GraphQL service is unavailable", err)
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		appErr.WithRetryAfter(openErr.RetryAfter)
+	}
+	return appErr
+}
@@ -0,0 +1,68 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/resilience"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerClient(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	base := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if code := int(status.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+		_, _ = w.Write([]byte(`{"errors":[{"message":"not found","extensions":{"code":"NOT_FOUND"}}]}`))
+	}, func(cfg *config.AppConfig) {
+		cfg.PiyoGraphQLRetryMaxAttempts = 1
+	})
+	cfg := &config.AppConfig{
+		PiyoGraphQLBreakerFailureRate:      0.5,
+		PiyoGraphQLBreakerMinRequests:      2,
+		PiyoGraphQLBreakerWindow:           time.Minute,
+		PiyoGraphQLBreakerOpenTimeout:      time.Minute,
+		PiyoGraphQLBreakerHalfOpenRequests: 1,
+	}
+	breaker := NewCircuitBreaker(cfg, logger.NewLogger(cfg), &datadog.MetricsManager{})
+	target := newCircuitBreakerClient(base, breaker)
+
+	t.Run("NotFound はブレーカーの失敗として数えない", func(t *testing.T) {
+		status.Store(http.StatusOK)
+		for range 3 {
+			_, err := target.GetSample(context.Background(), "abc123")
+			assertAppError(t, err, apperrors.ErrorTypeNotFound)
+		}
+		assert.Equal(t, resilience.StateClosed, breaker.State())
+	})
+
+	t.Run("upstream 障害が続くと 503 で即座に失敗する", func(t *testing.T) {
+		status.Store(http.StatusServiceUnavailable)
+		for range 3 {
+			_, _ = target.GetSample(context.Background(), "abc123")
+		}
+		before := calls.Load()
+
+		_, err := target.ListSample(context.Background(), nil, nil)
+
+		var appErr *apperrors.AppError
+		if assert.True(t, errors.As(err, &appErr)) {
+			assert.Equal(t, apperrors.ErrorTypeServiceUnavailable, appErr.Type)
+			assert.Greater(t, appErr.RetryAfter, time.Duration(0))
+		}
+		assert.Equal(t, before, calls.Load(), "open の間は upstream を呼び出さない")
+	})
+}
@@ -1,58 +1,232 @@
 package piyographql
 
 import (
+	"bytes"
 	"context"
-	"time"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
 
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 type Client interface {
 	GetSample(ctx context.Context, id string) (*models.Sample, error)
+	// GetSamples は ids と同じ順序で結果を返します
+	// 取得できなかったキーは errs の同じ位置にエラーが入ります
+	GetSamples(ctx context.Context, ids []string) (samples []*models.Sample, errs []error)
 	ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error)
+	// InvalidateSample はサンプルへの書き込み後に呼び出し、キャッシュしている結果を破棄します
+	InvalidateSample(id string)
 }
 
 type client struct {
-	logger logger.Logger
-	// クライアントの設定など
+	logger     logger.Logger
+	endpoint   string
+	authHeader string
+	authToken  string
+	httpClient *http.Client
+	retry      *retryPolicy
 }
 
-func NewClient(logger logger.Logger) Client {
+// NewClient は upstream GraphQL クライアントにブレーカーなどのデコレーターを重ねて返します
+func NewClient(
+	logger logger.Logger,
+	cfg *config.AppConfig,
+	metricsManager *datadog.MetricsManager,
+	breaker *CircuitBreaker,
+) Client {
+	var c Client = newGraphQLClient(logger, cfg, metricsManager)
+	c = newCircuitBreakerClient(c, breaker)
+	c = newBulkheadClient(c, cfg, metricsManager)
+	if cfg.PiyoGraphQLHedgeEnabled {
+		c = newHedgingClient(c, cfg, metricsManager)
+	}
+	c = newCachingClient(c, cfg, metricsManager)
+	c = newBatchingClient(c)
+	return c
+}
+
+func newGraphQLClient(logger logger.Logger, cfg *config.AppConfig, metricsManager *datadog.MetricsManager) *client {
 	return &client{
-		logger: logger,
+		logger:     logger,
+		endpoint:   cfg.PiyoGraphQLEndpoint,
+		authHeader: cfg.PiyoGraphQLAuthHeader,
+		authToken:  cfg.PiyoGraphQLAuthToken,
+		httpClient: &http.Client{
+			Timeout:   cfg.PiyoGraphQLTimeout,
+			Transport: datadog.NewTransport(newCassetteTransport(cfg, newTransport(cfg)), "piyographql"),
+		},
+		retry: newRetryPolicy(cfg, metricsManager),
+	}
+}
+
+func newTransport(cfg *config.AppConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout: cfg.PiyoGraphQLConnectTimeout,
+	}).DialContext
+	transport.TLSHandshakeTimeout = cfg.PiyoGraphQLConnectTimeout
+	transport.ResponseHeaderTimeout = cfg.PiyoGraphQLTimeout
+	return transport
+}
+
+func (c *client) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	var data struct {
+		Sample *sampleNode `json:"sample"`
 	}
+	if err := c.do(ctx, getSampleOperation, map[string]any{"id": ID}, &data); err != nil {
+		return nil, err
+	}
+	if data.Sample == nil {
+		return nil, apperrors.NewNotFoundError("Sample not found", nil)
+	}
+
+	return data.Sample.toModel(), nil
 }
 
-func (c *client) GetSample(_ context.Context, ID string) (*models.Sample, error) {
-	// ここでは簡易的に固定値を返していますが、
-	// 実際には取得する処理を実装します
+func (c *client) GetSamples(ctx context.Context, IDs []string) ([]*models.Sample, []error) {
+	samples := make([]*models.Sample, len(IDs))
+	errs := make([]error, len(IDs))
 
-	return &models.Sample{
-		ID:        ID,
-		StringVal: "example1",
-		IntVal:    123,
-		ArrayVal:  []string{"aaa", "bbb", "ccc"},
-		Email:     "user@example.com",
-		CreatedAt: time.Now().Add(-24 * time.Hour),
-		UpdatedAt: time.Now(),
-	}, nil
+	var data struct {
+		Samples []*sampleNode `json:"samplesByIds"`
+	}
+	if err := c.do(ctx, getSamplesOperation, map[string]any{"ids": IDs}, &data); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return samples, errs
+	}
+
+	byID := make(map[string]*sampleNode, len(data.Samples))
+	for _, node := range data.Samples {
+		if node != nil {
+			byID[node.ID] = node
+		}
+	}
+	for i, ID := range IDs {
+		if node, ok := byID[ID]; ok {
+			samples[i] = node.toModel()
+		} else {
+			errs[i] = apperrors.NewNotFoundError("Sample not found", nil)
+		}
+	}
+	return samples, errs
 }
 
 func (c *client) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
 	c.logger.InfoContext(ctx, "client ListSample", "offset", offset, "limit", limit)
-	return []models.Sample{
-		{
-			ID:        "1",
-			StringVal: "example1",
-			IntVal:    123,
-			ArrayVal:  []string{"aaa", "bbb", "ccc"},
-		},
-		{
-			ID:        "2",
-			StringVal: "example2",
-			IntVal:    124,
-			ArrayVal:  []string{"ddd", "eee", "fff"},
-		},
-	}, nil
+
+	variables := map[string]any{}
+	if offset != nil {
+		variables["offset"] = *offset
+	}
+	if limit != nil {
+		variables["limit"] = *limit
+	}
+
+	var data struct {
+		Samples []sampleNode `json:"samples"`
+	}
+	if err := c.do(ctx, listSampleOperation, variables, &data); err != nil {
+		return nil, err
+	}
+
+	samples := make([]models.Sample, 0, len(data.Samples))
+	for _, node := range data.Samples {
+		samples = append(samples, *node.toModel())
+	}
+	return samples, nil
+}
+
+// InvalidateSample は何もしません。キャッシュはデコレーターが保持します
+func (c *client) InvalidateSample(string) {}
+
+// do はオペレーションを再試行方針に従って実行し、失敗した場合はアプリケーションエラーに変換して返します
+func (c *client) do(ctx context.Context, op operation, variables map[string]any, out any) error {
+	err := c.retry.run(ctx, op, func(ctx context.Context) error {
+		return c.execute(ctx, op, variables, out)
+	})
+	if err != nil {
+		appErr := toAppError(op, err)
+		if errors.Is(err, context.Canceled) {
+			c.logger.InfoContext(ctx, "GraphQL request canceled", "operation", op.name)
+			return appErr
+		}
+		c.logger.ErrorContext(ctx, "GraphQL request failed", "operation", op.name, "error", err)
+		return appErr
+	}
+	return nil
+}
+
+// execute は GraphQL リクエストを 1 回送信し、data を out にデコードします
+func (c *client) execute(ctx context.Context, op operation, variables map[string]any, out any) error {
+	body, err := json.Marshal(graphqlRequest{
+		Query:         op.query,
+		OperationName: op.name,
+		Variables:     variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal graphql request: %w", err)
+	}
+
+	// クライアント span にオペレーション名を載せる
+	ctx = datadog.WithOutboundSpanOptions(ctx,
+		tracer.ResourceName(op.name),
+		tracer.Tag("graphql.operation", op.name),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create graphql request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.authToken != "" {
+		// Authorization ヘッダーの場合のみ Bearer スキームを付与する
+		if http.CanonicalHeaderKey(c.authHeader) == "Authorization" {
+			req.Header.Set(c.authHeader, "Bearer "+c.authToken)
+		} else {
+			req.Header.Set(c.authHeader, c.authToken)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	// nolint:errcheck
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read graphql response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{statusCode: resp.StatusCode, body: string(respBody)}
+	}
+
+	var gqlResp graphqlResponse
+	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
+		return fmt.Errorf("failed to decode graphql response: %w", err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		return gqlResp.Errors
+	}
+	if len(gqlResp.Data) == 0 || out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(gqlResp.Data, out); err != nil {
+		return fmt.Errorf("failed to decode graphql data: %w", err)
+	}
+	return nil
 }
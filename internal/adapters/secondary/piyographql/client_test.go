@@ -0,0 +1,289 @@
+package piyographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func newTestConfig(endpoint string) *config.AppConfig {
+	return &config.AppConfig{
+		PiyoGraphQLEndpoint:       endpoint,
+		PiyoGraphQLAuthHeader:     "Authorization",
+		PiyoGraphQLAuthToken:      "test-token",
+		PiyoGraphQLTimeout:        time.Second,
+		PiyoGraphQLConnectTimeout: time.Second,
+
+		PiyoGraphQLRetryMaxAttempts: 3,
+		PiyoGraphQLRetryBaseDelay:   time.Millisecond,
+		PiyoGraphQLRetryMaxDelay:    5 * time.Millisecond,
+	}
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...func(cfg *config.AppConfig)) Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cfg := newTestConfig(srv.URL)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return newGraphQLClient(logger.NewLogger(cfg), cfg, &datadog.MetricsManager{})
+}
+
+func decodeRequest(t *testing.T, r *http.Request) graphqlRequest {
+	t.Helper()
+	var req graphqlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	return req
+}
+
+func assertAppError(t *testing.T, err error, want apperrors.ErrorType) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	if assert.True(t, errors.As(err, &appErr), "expected *apperrors.AppError, got %T", err) {
+		assert.Equal(t, want, appErr.Type)
+	}
+}
+
+func TestClient_GetSample(t *testing.T) {
+	t.Run("正常系: サンプルが取得できる", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			req := decodeRequest(t, r)
+			assert.Equal(t, "GetSample", req.OperationName)
+			assert.Equal(t, getSampleQuery, req.Query)
+			assert.Equal(t, "abc123", req.Variables["id"])
+
+			_, _ = w.Write([]byte(`{"data":{"sample":{"id":"abc123","stringVal":"example","intVal":1,"arrayVal":["a"],"email":"user@example.com","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-02T00:00:00Z"}}}`))
+		})
+
+		sample, err := target.GetSample(context.Background(), "abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", sample.ID)
+		assert.Equal(t, "example", sample.StringVal)
+		assert.Equal(t, []string{"a"}, sample.ArrayVal)
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), sample.UpdatedAt)
+	})
+
+	t.Run("異常系: NOT_FOUND は 404 に変換される", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"data":{"sample":null},"errors":[{"message":"sample not found","extensions":{"code":"NOT_FOUND"}}]}`))
+		})
+
+		_, err := target.GetSample(context.Background(), "missing")
+
+		assertAppError(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("異常系: data が null の場合は 404 に変換される", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"data":{"sample":null}}`))
+		})
+
+		_, err := target.GetSample(context.Background(), "missing")
+
+		assertAppError(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("異常系: GraphQL エラーは 502 に変換される", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"internal","extensions":{"code":"INTERNAL_SERVER_ERROR"}}]}`))
+		})
+
+		_, err := target.GetSample(context.Background(), "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+	})
+
+	t.Run("異常系: 5xx は 502 に変換される", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		_, err := target.GetSample(context.Background(), "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+	})
+
+	t.Run("異常系: タイムアウトは 504 に変換される", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := target.GetSample(ctx, "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeTimeout)
+	})
+
+	t.Run("異常系: キャンセルされた呼び出しは upstream の障害に数えない", func(t *testing.T) {
+		target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := target.GetSample(ctx, "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeCanceled)
+		assert.False(t, isUpstreamFailure(err))
+	})
+}
+
+func TestClient_ListSample(t *testing.T) {
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		assert.Equal(t, "ListSample", req.OperationName)
+		assert.Equal(t, float64(10), req.Variables["offset"])
+		assert.NotContains(t, req.Variables, "limit")
+
+		_, _ = w.Write([]byte(`{"data":{"samples":[{"id":"1","stringVal":"example1"},{"id":"2","stringVal":"example2"}]}}`))
+	})
+	offset := 10
+
+	samples, err := target.ListSample(context.Background(), &offset, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, samples, 2)
+	assert.Equal(t, "2", samples[1].ID)
+}
+
+func TestClient_Retry(t *testing.T) {
+	t.Run("正常系: 一時的な 5xx は再試行される", func(t *testing.T) {
+		var calls atomic.Int32
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"sample":{"id":"abc123"}}}`))
+		})
+
+		sample, err := target.GetSample(context.Background(), "abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", sample.ID)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("正常系: 各試行は呼び出し元の span のイベントとして記録される", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		var calls atomic.Int32
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"sample":{"id":"abc123"}}}`))
+		})
+		parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+
+		_, err := target.GetSample(ctx, "abc123")
+		parent.Finish()
+
+		assert.NoError(t, err)
+		var got mocktracer.Span
+		for _, span := range mt.FinishedSpans() {
+			assert.NotEqual(t, "piyographql.attempt", span.OperationName(), "試行ごとの子 span は作らない")
+			if span.OperationName() == "parent" {
+				got = span
+			}
+		}
+		if assert.NotNil(t, got) {
+			assert.Equal(t, 2, got.Tag("retry.attempts"))
+			assert.Equal(t, "error", got.Tag("retry.attempt.1.outcome"))
+			assert.Equal(t, true, got.Tag("retry.attempt.1.retryable"))
+			assert.Equal(t, "success", got.Tag("retry.attempt.2.outcome"))
+		}
+	})
+
+	t.Run("異常系: 最大試行回数で打ち切られる", func(t *testing.T) {
+		var calls atomic.Int32
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		})
+
+		_, err := target.GetSample(context.Background(), "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("異常系: 4xx は再試行されない", func(t *testing.T) {
+		var calls atomic.Int32
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		_, err := target.GetSample(context.Background(), "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("異常系: デッドラインを超える待機は行わない", func(t *testing.T) {
+		var calls atomic.Int32
+		target := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, func(cfg *config.AppConfig) {
+			cfg.PiyoGraphQLRetryBaseDelay = time.Hour
+			cfg.PiyoGraphQLRetryMaxDelay = time.Hour
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := target.GetSample(ctx, "abc123")
+
+		assertAppError(t, err, apperrors.ErrorTypeExternalService)
+		assert.LessOrEqual(t, calls.Load(), int32(2))
+	})
+}
+
+func TestClient_GetSamples(t *testing.T) {
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		assert.Equal(t, "GetSamples", req.OperationName)
+		assert.Equal(t, []any{"b", "missing", "a"}, req.Variables["ids"])
+
+		// upstream の返却順はリクエストの順序と一致しない
+		_, _ = w.Write([]byte(`{"data":{"samplesByIds":[{"id":"a"},{"id":"b"}]}}`))
+	})
+
+	samples, errs := target.GetSamples(context.Background(), []string{"b", "missing", "a"})
+
+	assert.Equal(t, "b", samples[0].ID)
+	assert.NoError(t, errs[0])
+	assert.Nil(t, samples[1])
+	assertAppError(t, errs[1], apperrors.ErrorTypeNotFound)
+	assert.Equal(t, "a", samples[2].ID)
+	assert.NoError(t, errs[2])
+}
@@ -0,0 +1,41 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+)
+
+// toAppError は upstream 呼び出しのエラーをアプリケーションエラーに変換します
+func toAppError(op operation, err error) error {
+	var gqlErrs graphqlErrors
+	var netErr net.Error
+	var missErr *CassetteMissError
+
+	switch {
+	case errors.As(err, &gqlErrs) && gqlErrs.hasCode("NOT_FOUND"):
+		return apperrors.NewNotFoundError("Resource not found", err)
+	case errors.As(err, &missErr):
+		// 記録の場所はログにのみ出力し、クライアントには返さない
+		return apperrors.NewExternalServiceError(fmt.Sprintf("Upstream GraphQL %s has no cassette recording", op.name), err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// ヘッジで採用されなかった呼び出しのキャンセルも、upstream の障害には数えない
+		return contextError(err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return apperrors.NewTimeoutError("Upstream GraphQL request timed out", err)
+	default:
+		return apperrors.NewExternalServiceError(fmt.Sprintf("Upstream GraphQL %s failed", op.name), err)
+	}
+}
+
+// contextError はコンテキストの終了をアプリケーションエラーに変換します
+// 呼び出し元のキャンセルはタイムアウトとして扱わず、upstream の障害にも数えません
+func contextError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return apperrors.NewCanceledError("Request canceled", err)
+	}
+	return apperrors.NewTimeoutError("Upstream GraphQL request timed out", err)
+}
@@ -0,0 +1,85 @@
+package piyographql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+)
+
+// maxResponseSize は upstream レスポンスボディの読み込み上限です
+const maxResponseSize = 10 << 20
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors graphqlErrors   `json:"errors"`
+}
+
+type graphqlError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// graphqlErrors はレスポンスの errors[] を表します
+type graphqlErrors []graphqlError
+
+func (e graphqlErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, ge := range e {
+		messages = append(messages, ge.Message)
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// hasCode は extensions.code に指定のコードを持つエラーが含まれるかを返します
+func (e graphqlErrors) hasCode(code string) bool {
+	for _, ge := range e {
+		if c, ok := ge.Extensions["code"].(string); ok && c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// statusError は upstream が 2xx 以外を返したことを表します
+type statusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("graphql: unexpected status code %d: %s", e.statusCode, e.body)
+}
+
+type sampleNode struct {
+	ID        string    `json:"id"`
+	StringVal string    `json:"stringVal"`
+	IntVal    int       `json:"intVal"`
+	ArrayVal  []string  `json:"arrayVal"`
+	Email     string    `json:"email"`
+	Team      string    `json:"team"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (n *sampleNode) toModel() *models.Sample {
+	return &models.Sample{
+		ID:        n.ID,
+		StringVal: n.StringVal,
+		IntVal:    n.IntVal,
+		ArrayVal:  n.ArrayVal,
+		Email:     n.Email,
+		Team:      n.Team,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+	}
+}
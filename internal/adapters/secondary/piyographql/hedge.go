@@ -0,0 +1,76 @@
+package piyographql
+
+import (
+	"context"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/resilience"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+)
+
+// hedgeMinSamples 未満の計測数ではヘッジしません
+const hedgeMinSamples = 20
+
+// hedgingClient は冪等な取得系の呼び出しが遅い場合に 2 本目のリクエストを送り、先に返った結果を使います
+// オペレーションごとにレイテンシの分布が異なるため、Hedger はオペレーションごとに持ちます
+type hedgingClient struct {
+	Client
+	metricsManager *datadog.MetricsManager
+	getSample      *resilience.Hedger
+	getSamples     *resilience.Hedger
+}
+
+func newHedgingClient(next Client, cfg *config.AppConfig, metricsManager *datadog.MetricsManager) Client {
+	settings := resilience.HedgeSettings{
+		Percentile: cfg.PiyoGraphQLHedgePercentile,
+		MinDelay:   cfg.PiyoGraphQLHedgeMinDelay,
+		MinSamples: hedgeMinSamples,
+		Budget:     cfg.PiyoGraphQLHedgeBudget,
+		MaxBurst:   cfg.PiyoGraphQLHedgeMaxBurst,
+	}
+	return &hedgingClient{
+		Client:         next,
+		metricsManager: metricsManager,
+		getSample:      resilience.NewHedger(settings),
+		getSamples:     resilience.NewHedger(settings),
+	}
+}
+
+func (c *hedgingClient) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	sample, outcome, err := resilience.Hedge(ctx, c.getSample, func(ctx context.Context) (*models.Sample, error) {
+		return c.Client.GetSample(ctx, ID)
+	})
+	c.recordHedge(getSampleOperation, outcome)
+	return sample, err
+}
+
+func (c *hedgingClient) GetSamples(ctx context.Context, IDs []string) ([]*models.Sample, []error) {
+	type result struct {
+		samples []*models.Sample
+		errs    []error
+	}
+	res, outcome, _ := resilience.Hedge(ctx, c.getSamples, func(ctx context.Context) (result, error) {
+		samples, errs := c.Client.GetSamples(ctx, IDs)
+		// キー単位の NotFound は正常な応答のため、upstream の失敗がある場合のみ失敗として扱う
+		for _, err := range errs {
+			if isUpstreamFailure(err) {
+				return result{samples, errs}, err
+			}
+		}
+		return result{samples, errs}, nil
+	})
+	c.recordHedge(getSamplesOperation, outcome)
+	return res.samples, res.errs
+}
+
+func (c *hedgingClient) recordHedge(op operation, outcome resilience.HedgeOutcome) {
+	if !outcome.Issued {
+		return
+	}
+	tags := []string{"operation:" + op.name}
+	c.metricsManager.Count("piyographql.hedge.issued", 1, tags)
+	if outcome.Won {
+		c.metricsManager.Count("piyographql.hedge.won", 1, tags)
+	}
+}
@@ -0,0 +1,125 @@
+package piyographql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+type loaderContextKey struct{}
+
+// Loader は一定時間内に要求された GetSample の ID を集め、GetSamples 1 回で取得するリクエストスコープのローダーです
+type Loader struct {
+	ctx      context.Context
+	client   Client
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *loaderBatch
+}
+
+type loaderBatch struct {
+	keys    []string
+	indexes map[string]int
+	done    chan struct{}
+	samples []*models.Sample
+	errs    []error
+}
+
+// LoaderFactory はリクエストごとに Loader を生成します
+type LoaderFactory struct {
+	client   Client
+	wait     time.Duration
+	maxBatch int
+}
+
+func NewLoaderFactory(cfg *config.AppConfig, client Client) *LoaderFactory {
+	return &LoaderFactory{
+		client:   client,
+		wait:     cfg.PiyoGraphQLLoaderWait,
+		maxBatch: cfg.PiyoGraphQLLoaderMaxBatch,
+	}
+}
+
+// WithLoader はリクエストスコープの Loader を持つコンテキストを返します
+// バッチの取得はこのコンテキストで行われるため、リクエストのコンテキストを渡してください
+func (f *LoaderFactory) WithLoader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loaderContextKey{}, &Loader{
+		ctx:      ctx,
+		client:   f.client,
+		wait:     f.wait,
+		maxBatch: f.maxBatch,
+	})
+}
+
+func loaderFromContext(ctx context.Context) (*Loader, bool) {
+	l, ok := ctx.Value(loaderContextKey{}).(*Loader)
+	return l, ok
+}
+
+// Load は ID をバッチに追加し、バッチの取得結果を待ちます
+func (l *Loader) Load(ctx context.Context, ID string) (*models.Sample, error) {
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &loaderBatch{
+			indexes: make(map[string]int),
+			done:    make(chan struct{}),
+		}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+	i, ok := b.indexes[ID]
+	if !ok {
+		i = len(b.keys)
+		b.indexes[ID] = i
+		b.keys = append(b.keys, ID)
+	}
+	full := len(b.keys) >= l.maxBatch
+	l.mu.Unlock()
+
+	if full {
+		l.dispatch(b)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, contextError(ctx.Err())
+	case <-b.done:
+		return cloneSample(b.samples[i]), b.errs[i]
+	}
+}
+
+// dispatch はバッチを締め切って取得します。同じバッチに対する 2 回目以降の呼び出しは何もしません
+func (l *Loader) dispatch(b *loaderBatch) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	b.samples, b.errs = l.client.GetSamples(l.ctx, b.keys)
+	close(b.done)
+}
+
+// batchingClient はコンテキストに Loader がある場合、GetSample を Loader 経由でまとめて取得します
+type batchingClient struct {
+	Client
+}
+
+func newBatchingClient(next Client) Client {
+	return &batchingClient{Client: next}
+}
+
+func (c *batchingClient) GetSample(ctx context.Context, ID string) (*models.Sample, error) {
+	if l, ok := loaderFromContext(ctx); ok {
+		return l.Load(ctx, ID)
+	}
+	return c.Client.GetSample(ctx, ID)
+}
@@ -0,0 +1,117 @@
+package piyographql
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchStubClient は GetSamples に渡されたキーを記録するテスト用の Client です
+type batchStubClient struct {
+	Client
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (s *batchStubClient) GetSamples(_ context.Context, IDs []string) ([]*models.Sample, []error) {
+	s.mu.Lock()
+	s.batches = append(s.batches, IDs)
+	s.mu.Unlock()
+
+	samples := make([]*models.Sample, len(IDs))
+	errs := make([]error, len(IDs))
+	for i, ID := range IDs {
+		if ID == "missing" {
+			errs[i] = apperrors.NewNotFoundError("Sample not found", nil)
+			continue
+		}
+		samples[i] = &models.Sample{ID: ID}
+	}
+	return samples, errs
+}
+
+func TestLoader(t *testing.T) {
+	newTarget := func(maxBatch int) (*batchStubClient, Client, *LoaderFactory) {
+		stub := &batchStubClient{}
+		target := newBatchingClient(stub)
+		factory := NewLoaderFactory(&config.AppConfig{
+			PiyoGraphQLLoaderWait:     20 * time.Millisecond,
+			PiyoGraphQLLoaderMaxBatch: maxBatch,
+		}, target)
+		return stub, target, factory
+	}
+
+	t.Run("待機時間内の GetSample は 1 回の GetSamples にまとめられる", func(t *testing.T) {
+		stub, target, factory := newTarget(100)
+		ctx := factory.WithLoader(context.Background())
+
+		IDs := []string{"a", "b", "missing", "a"}
+		results := make([]*models.Sample, len(IDs))
+		errs := make([]error, len(IDs))
+		var wg sync.WaitGroup
+		for i, ID := range IDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = target.GetSample(ctx, ID)
+			}()
+		}
+		wg.Wait()
+
+		if assert.Len(t, stub.batches, 1) {
+			assert.ElementsMatch(t, []string{"a", "b", "missing"}, stub.batches[0], "重複したキーは 1 つにまとめられる")
+		}
+		assert.Equal(t, "a", results[0].ID)
+		assert.Equal(t, "b", results[1].ID)
+		assertAppError(t, errs[2], apperrors.ErrorTypeNotFound)
+		assert.Equal(t, "a", results[3].ID)
+	})
+
+	t.Run("最大バッチサイズに達すると待たずに取得する", func(t *testing.T) {
+		stub, target, factory := newTarget(2)
+		ctx := factory.WithLoader(context.Background())
+
+		var wg sync.WaitGroup
+		for _, ID := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sample, err := target.GetSample(ctx, ID)
+				assert.NoError(t, err)
+				assert.Equal(t, ID, sample.ID)
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, stub.batches, 2)
+	})
+
+	t.Run("待機中にキャンセルされた場合はタイムアウトではなくキャンセルとして返す", func(t *testing.T) {
+		_, target, factory := newTarget(100)
+		ctx, cancel := context.WithCancel(factory.WithLoader(context.Background()))
+		cancel()
+
+		_, err := target.GetSample(ctx, "a")
+
+		assertAppError(t, err, apperrors.ErrorTypeCanceled)
+	})
+
+	t.Run("Loader がない場合は GetSample をそのまま呼び出す", func(t *testing.T) {
+		stub := &stubClient{get: func(ID string) (*models.Sample, error) {
+			return &models.Sample{ID: ID}, nil
+		}}
+		target := newBatchingClient(stub)
+
+		sample, err := target.GetSample(context.Background(), "a")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "a", sample.ID)
+		assert.Equal(t, int32(1), stub.calls.Load())
+	})
+}
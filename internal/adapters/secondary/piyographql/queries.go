@@ -0,0 +1,26 @@
+package piyographql
+
+import _ "embed"
+
+// operation は upstream に送信する GraphQL ドキュメントを表します
+type operation struct {
+	name  string
+	query string
+	// idempotent が true のオペレーションのみ再試行の対象になります
+	idempotent bool
+}
+
+var (
+	//go:embed queries/get_sample.graphql
+	getSampleQuery string
+	//go:embed queries/get_samples.graphql
+	getSamplesQuery string
+	//go:embed queries/list_sample.graphql
+	listSampleQuery string
+)
+
+var (
+	getSampleOperation  = operation{name: "GetSample", query: getSampleQuery, idempotent: true}
+	getSamplesOperation = operation{name: "GetSamples", query: getSamplesQuery, idempotent: true}
+	listSampleOperation = operation{name: "ListSample", query: listSampleQuery, idempotent: true}
+)
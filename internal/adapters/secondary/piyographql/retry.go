@@ -0,0 +1,123 @@
+package piyographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/resilience"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/telemetry/datadog"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// retryPolicy は upstream 呼び出しの再試行方針を表します
+type retryPolicy struct {
+	maxAttempts    int
+	backoff        resilience.Backoff
+	metricsManager *datadog.MetricsManager
+}
+
+func newRetryPolicy(cfg *config.AppConfig, metricsManager *datadog.MetricsManager) *retryPolicy {
+	return &retryPolicy{
+		maxAttempts: cfg.PiyoGraphQLRetryMaxAttempts,
+		backoff: resilience.Backoff{
+			BaseDelay: cfg.PiyoGraphQLRetryBaseDelay,
+			MaxDelay:  cfg.PiyoGraphQLRetryMaxDelay,
+		},
+		metricsManager: metricsManager,
+	}
+}
+
+// run は fn を実行し、再試行可能なエラーの場合はバックオフを挟んで再実行します
+// 冪等でないオペレーションは再試行しません
+// 待機後にリクエストコンテキストのデッドラインを超える場合も再試行しません
+func (p *retryPolicy) run(ctx context.Context, op operation, fn func(ctx context.Context) error) error {
+	maxAttempts := 1
+	if op.idempotent && p.maxAttempts > 1 {
+		maxAttempts = p.maxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := p.attempt(ctx, op, attempt, fn)
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		delay := p.backoff.Delay(attempt)
+		if !withinDeadline(ctx, delay) {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt は 1 回分の呼び出しを呼び出し元の span のイベントとメトリクスとして記録します
+// 各試行の HTTP 呼び出しは計装したトランスポートのクライアント span として記録されます
+func (p *retryPolicy) attempt(ctx context.Context, op operation, attempt int, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	err := fn(ctx)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	recordAttemptEvent(ctx, attempt, outcome, time.Since(start), err)
+
+	p.metricsManager.Count("piyographql.attempt", 1, []string{
+		fmt.Sprintf("operation:%s", op.name),
+		fmt.Sprintf("attempt:%d", attempt),
+		fmt.Sprintf("outcome:%s", outcome),
+	})
+	return err
+}
+
+// recordAttemptEvent は試行の結果を呼び出し元の span に retry.attempt.<n>.* のタグとして残します
+// dd-trace-go v1 には span イベントの API がないため、試行ごとの接頭辞を付けたタグで代用します
+func recordAttemptEvent(ctx context.Context, attempt int, outcome string, duration time.Duration, err error) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	prefix := fmt.Sprintf("retry.attempt.%d.", attempt)
+	span.SetTag("retry.attempts", attempt)
+	span.SetTag(prefix+"outcome", outcome)
+	span.SetTag(prefix+"duration_ms", duration.Milliseconds())
+	if err != nil {
+		span.SetTag(prefix+"retryable", isRetryable(err))
+		span.SetTag(prefix+"error", err.Error())
+	}
+}
+
+// isRetryable は一時的な障害とみなせるエラーかどうかを返します
+func isRetryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= 500
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// withinDeadline は delay だけ待機してもコンテキストのデッドラインに達しないかを返します
+func withinDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+	return time.Until(deadline) > delay
+}
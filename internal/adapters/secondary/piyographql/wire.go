@@ -2,4 +2,8 @@ package piyographql
 
 import "github.com/google/wire"
 
-var Set = wire.NewSet(NewClient)
+var Set = wire.NewSet(
+	NewCircuitBreaker,
+	NewClient,
+	NewLoaderFactory,
+)
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+type AccountTokenRepository interface {
+	// Create はトークンを保存します。有効期限を過ぎたトークンは書き込み時に削除します
+	Create(ctx context.Context, token *models.AccountToken) error
+	// Take はトークンを取り出して削除します。同じトークンは 2 度取り出せません
+	Take(ctx context.Context, tokenHash string) (*models.AccountToken, error)
+	// DeleteByUser はユーザーの指定した用途のトークンをすべて削除します
+	DeleteByUser(ctx context.Context, userID string, purpose models.AccountTokenPurpose) error
+}
+
+// NewAccountTokenRepository はユーザーストアと同じ種類のストアを返します
+func NewAccountTokenRepository(cfg *config.AppConfig, db *sql.DB) (AccountTokenRepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlAccountTokenRepository{db: db, now: time.Now}, nil
+	}
+	return NewMemoryAccountTokenRepository(), nil
+}
+
+type memoryAccountTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.AccountToken
+	now    func() time.Time
+}
+
+func NewMemoryAccountTokenRepository() AccountTokenRepository {
+	return &memoryAccountTokenRepository{
+		tokens: make(map[string]models.AccountToken),
+		now:    time.Now,
+	}
+}
+
+func (r *memoryAccountTokenRepository) Create(_ context.Context, token *models.AccountToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for hash, t := range r.tokens {
+		if !now.Before(t.ExpiresAt) {
+			delete(r.tokens, hash)
+		}
+	}
+	r.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (r *memoryAccountTokenRepository) Take(_ context.Context, tokenHash string) (*models.AccountToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("Token not found", nil)
+	}
+	delete(r.tokens, tokenHash)
+	return &token, nil
+}
+
+func (r *memoryAccountTokenRepository) DeleteByUser(_ context.Context, userID string, purpose models.AccountTokenPurpose) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+type sqlAccountTokenRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func (r *sqlAccountTokenRepository) Create(ctx context.Context, token *models.AccountToken) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM account_tokens WHERE expires_at <= $1`, r.now()); err != nil {
+			return apperrors.NewInternalError("Failed to purge account tokens", err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO account_tokens (token_hash, user_id, purpose, expires_at, created_at) VALUES ($1, $2, $3, $4, $5)`,
+			token.TokenHash, token.UserID, string(token.Purpose), token.ExpiresAt, token.CreatedAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("Failed to save account token", err)
+		}
+		return nil
+	})
+}
+
+// Take は DELETE ... RETURNING で取り出すため、同時に使われても 1 度しか成功しません
+func (r *sqlAccountTokenRepository) Take(ctx context.Context, tokenHash string) (*models.AccountToken, error) {
+	token := models.AccountToken{TokenHash: tokenHash}
+	var purpose string
+	err := r.db.QueryRowContext(ctx,
+		`DELETE FROM account_tokens WHERE token_hash = $1 RETURNING user_id, purpose, expires_at, created_at`, tokenHash,
+	).Scan(&token.UserID, &purpose, &token.ExpiresAt, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("Token not found", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to take account token", err)
+	}
+	token.Purpose = models.AccountTokenPurpose(purpose)
+	return &token, nil
+}
+
+func (r *sqlAccountTokenRepository) DeleteByUser(ctx context.Context, userID string, purpose models.AccountTokenPurpose) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM account_tokens WHERE user_id = $1 AND purpose = $2`, userID, string(purpose))
+	if err != nil {
+		return apperrors.NewInternalError("Failed to delete account tokens", err)
+	}
+	return nil
+}
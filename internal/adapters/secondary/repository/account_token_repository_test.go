@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("正常系: memory ストアのトークンは一度だけ取り出せる", func(t *testing.T) {
+		repo := NewMemoryAccountTokenRepository()
+		require.NoError(t, repo.Create(ctx, &models.AccountToken{TokenHash: "h1", UserID: "user123", Purpose: models.AccountTokenPurposePasswordReset, ExpiresAt: now.Add(time.Hour)}))
+
+		token, err := repo.Take(ctx, "h1")
+		require.NoError(t, err)
+		assert.Equal(t, "user123", token.UserID)
+
+		_, err = repo.Take(ctx, "h1")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("正常系: memory ストアはユーザーの同じ用途のトークンだけを削除する", func(t *testing.T) {
+		repo := NewMemoryAccountTokenRepository()
+		require.NoError(t, repo.Create(ctx, &models.AccountToken{TokenHash: "reset", UserID: "user123", Purpose: models.AccountTokenPurposePasswordReset, ExpiresAt: now.Add(time.Hour)}))
+		require.NoError(t, repo.Create(ctx, &models.AccountToken{TokenHash: "verify", UserID: "user123", Purpose: models.AccountTokenPurposeEmailVerification, ExpiresAt: now.Add(time.Hour)}))
+
+		require.NoError(t, repo.DeleteByUser(ctx, "user123", models.AccountTokenPurposePasswordReset))
+
+		_, err := repo.Take(ctx, "reset")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+		_, err = repo.Take(ctx, "verify")
+		assert.NoError(t, err)
+	})
+
+	t.Run("正常系: memory ストアは書き込み時に有効期限を過ぎたトークンを削除する", func(t *testing.T) {
+		repo := NewMemoryAccountTokenRepository()
+		require.NoError(t, repo.Create(ctx, &models.AccountToken{TokenHash: "expired", UserID: "user123", ExpiresAt: now.Add(-time.Minute)}))
+		require.NoError(t, repo.Create(ctx, &models.AccountToken{TokenHash: "fresh", UserID: "user123", ExpiresAt: now.Add(time.Hour)}))
+
+		_, err := repo.Take(ctx, "expired")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+		_, err = repo.Take(ctx, "fresh")
+		assert.NoError(t, err)
+	})
+
+	t.Run("正常系: SQL ストアは書き込みと同じトランザクションで期限切れの行を削除する", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM account_tokens WHERE expires_at <= \$1`).
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`INSERT INTO account_tokens`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := &sqlAccountTokenRepository{db: db, now: func() time.Time { return now }}
+		err = repo.Create(ctx, &models.AccountToken{TokenHash: "h1", UserID: "user123", ExpiresAt: now.Add(time.Hour)})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: SQL ストアは DELETE RETURNING で取り出す", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`DELETE FROM account_tokens WHERE token_hash = \$1 RETURNING user_id, purpose, expires_at, created_at`).
+			WithArgs("h1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "purpose", "expires_at", "created_at"}).
+				AddRow("user123", "email_verification", now, now))
+
+		token, err := (&sqlAccountTokenRepository{db: db}).Take(ctx, "h1")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountTokenPurposeEmailVerification, token.Purpose)
+		assert.Equal(t, "h1", token.TokenHash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: SQL ストアに存在しないトークンは NotFound", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`DELETE FROM account_tokens`).WithArgs("missing").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "purpose", "expires_at", "created_at"}))
+
+		_, err = (&sqlAccountTokenRepository{db: db}).Take(ctx, "missing")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+	})
+}
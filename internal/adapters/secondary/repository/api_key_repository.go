@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type APIKeyRepository interface {
+	// Create はキーを保存します。ID が重複する場合は Conflict エラーを返します
+	Create(ctx context.Context, key *models.APIKey) error
+	// Get は ID に一致するキーを返します。存在しない場合は NotFound エラーを返します
+	Get(ctx context.Context, id string) (*models.APIKey, error)
+	// ListByUser はユーザーのキーを作成日時の順に返します
+	ListByUser(ctx context.Context, userID string) ([]models.APIKey, error)
+	// Revoke はユーザーのキーを失効させます。ユーザーのキーでない場合は NotFound エラーを返します
+	Revoke(ctx context.Context, id, userID string, at time.Time) error
+	// UpdateLastUsed は最終使用日時を記録します
+	UpdateLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+// NewAPIKeyRepository はユーザーストアと同じ種類のストアを返します
+func NewAPIKeyRepository(cfg *config.AppConfig, db *sql.DB) (APIKeyRepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlAPIKeyRepository{db: db}, nil
+	}
+	return NewMemoryAPIKeyRepository(), nil
+}
+
+type memoryAPIKeyRepository struct {
+	mu   sync.Mutex
+	keys map[string]models.APIKey
+}
+
+func NewMemoryAPIKeyRepository() APIKeyRepository {
+	return &memoryAPIKeyRepository{
+		keys: make(map[string]models.APIKey),
+	}
+}
+
+func (r *memoryAPIKeyRepository) Create(_ context.Context, key *models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ID]; ok {
+		return apperrors.NewConflictError("API key already exists", nil)
+	}
+	r.keys[key.ID] = *key
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) Get(_ context.Context, id string) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("API key not found", nil)
+	}
+	return &key, nil
+}
+
+func (r *memoryAPIKeyRepository) ListByUser(_ context.Context, userID string) ([]models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]models.APIKey, 0)
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(_ context.Context, id, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userID {
+		return apperrors.NewNotFoundError("API key not found", nil)
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+		r.keys[id] = key
+	}
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) UpdateLastUsed(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.keys[id]; ok {
+		key.LastUsedAt = &at
+		r.keys[id] = key
+	}
+	return nil
+}
+
+// sqlAPIKeyRepository はスコープを空白区切りの文字列で保存します
+type sqlAPIKeyRepository struct {
+	db *sql.DB
+}
+
+const apiKeyColumns = `id, user_id, name, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *sqlAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO api_keys (id, user_id, name, key_hash, scopes, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		key.ID, key.UserID, key.Name, key.KeyHash, strings.Join(key.Scopes, " "), key.ExpiresAt, key.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return apperrors.NewConflictError("API key already exists", err)
+		}
+		return apperrors.NewInternalError("Failed to save API key", err)
+	}
+	return nil
+}
+
+func (r *sqlAPIKeyRepository) Get(ctx context.Context, id string) (*models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("API key not found", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get API key", err)
+	}
+	return key, nil
+}
+
+func (r *sqlAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to list API keys", err)
+	}
+	// nolint:errcheck
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, apperrors.NewInternalError("Failed to list API keys", err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("Failed to list API keys", err)
+	}
+	return keys, nil
+}
+
+func (r *sqlAPIKeyRepository) Revoke(ctx context.Context, id, userID string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3) WHERE id = $1 AND user_id = $2`, id, userID, at,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to revoke API key", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("Failed to revoke API key", err)
+	}
+	if n == 0 {
+		return apperrors.NewNotFoundError("API key not found", nil)
+	}
+	return nil
+}
+
+func (r *sqlAPIKeyRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to update API key", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.KeyHash, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("正常系: memory ストアはユーザーのキーだけを一覧にする", func(t *testing.T) {
+		repo := NewMemoryAPIKeyRepository()
+		require.NoError(t, repo.Create(ctx, &models.APIKey{ID: "gk_1", UserID: "alice", CreatedAt: now}))
+		require.NoError(t, repo.Create(ctx, &models.APIKey{ID: "gk_2", UserID: "bob", CreatedAt: now}))
+		require.NoError(t, repo.Create(ctx, &models.APIKey{ID: "gk_3", UserID: "alice", CreatedAt: now.Add(time.Second)}))
+
+		keys, err := repo.ListByUser(ctx, "alice")
+
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "gk_1", keys[0].ID)
+		assert.Equal(t, "gk_3", keys[1].ID)
+	})
+
+	t.Run("異常系: memory ストアは他のユーザーのキーを失効できない", func(t *testing.T) {
+		repo := NewMemoryAPIKeyRepository()
+		require.NoError(t, repo.Create(ctx, &models.APIKey{ID: "gk_1", UserID: "alice", CreatedAt: now}))
+
+		err := repo.Revoke(ctx, "gk_1", "bob", now)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+		key, err := repo.Get(ctx, "gk_1")
+		require.NoError(t, err)
+		assert.Nil(t, key.RevokedAt)
+	})
+
+	t.Run("正常系: SQL ストアは空白区切りのスコープを復元する", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, user_id, name, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE id = \$1`).
+			WithArgs("gk_1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at"}).
+				AddRow("gk_1", "alice", "batch", "hash", "samples:read samples:write", nil, now, nil, now))
+
+		key, err := (&sqlAPIKeyRepository{db: db}).Get(ctx, "gk_1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"samples:read", "samples:write"}, key.Scopes)
+		assert.Nil(t, key.ExpiresAt)
+		assert.NotNil(t, key.LastUsedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: SQL ストアは所有者の異なるキーの失効を NotFound にする", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE api_keys SET revoked_at = COALESCE\(revoked_at, \$3\) WHERE id = \$1 AND user_id = \$2`).
+			WithArgs("gk_1", "bob", now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = (&sqlAPIKeyRepository{db: db}).Revoke(ctx, "gk_1", "bob", now)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
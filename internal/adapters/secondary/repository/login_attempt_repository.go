@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// LoginAttemptRepository はログインの失敗とアカウントのロックを保持します
+// key はユーザー ID や IP アドレスに種類の接頭辞を付けたもの（user:alice, ip:192.0.2.1 など）です
+type LoginAttemptRepository interface {
+	// RecordFailure は失敗を記録し、since 以降の失敗の時刻を古い順に返します。since より前の記録は削除します
+	RecordFailure(ctx context.Context, key string, at, since time.Time) ([]time.Time, error)
+	// Failures は since 以降の失敗の時刻を古い順に返します
+	Failures(ctx context.Context, key string, since time.Time) ([]time.Time, error)
+	// RemoveFailure は at に記録した失敗を 1 件だけ削除します
+	RemoveFailure(ctx context.Context, key string, at time.Time) error
+	// ClearFailures は失敗の記録を削除します
+	ClearFailures(ctx context.Context, key string) error
+	// Lock は until までロックします
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil はロックの期限を返します。ロックされていない場合はゼロ値です
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Unlock はロックを解除します。ロックされていた場合は true を返します
+	Unlock(ctx context.Context, key string) (bool, error)
+}
+
+// NewLoginAttemptRepository はユーザーストアと同じ種類のストアを返します
+func NewLoginAttemptRepository(cfg *config.AppConfig, db *sql.DB) (LoginAttemptRepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlLoginAttemptRepository{db: db, now: time.Now}, nil
+	}
+	return NewMemoryLoginAttemptRepository(), nil
+}
+
+type memoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	locks    map[string]time.Time
+	now      func() time.Time
+}
+
+func NewMemoryLoginAttemptRepository() LoginAttemptRepository {
+	return &memoryLoginAttemptRepository{
+		failures: make(map[string][]time.Time),
+		locks:    make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (r *memoryLoginAttemptRepository) RecordFailure(_ context.Context, key string, at, since time.Time) ([]time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purge(since)
+
+	r.failures[key] = append(r.failures[key], at)
+	return append([]time.Time(nil), r.failures[key]...), nil
+}
+
+func (r *memoryLoginAttemptRepository) Failures(_ context.Context, key string, since time.Time) ([]time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var failures []time.Time
+	for _, at := range r.failures[key] {
+		if !at.Before(since) {
+			failures = append(failures, at)
+		}
+	}
+	return failures, nil
+}
+
+func (r *memoryLoginAttemptRepository) RemoveFailure(_ context.Context, key string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failures := r.failures[key]
+	for i, failedAt := range failures {
+		if failedAt.Equal(at) {
+			failures = append(failures[:i:i], failures[i+1:]...)
+			break
+		}
+	}
+	if len(failures) == 0 {
+		delete(r.failures, key)
+	} else {
+		r.failures[key] = failures
+	}
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) ClearFailures(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, key)
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) Lock(_ context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locks[key] = until
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) LockedUntil(_ context.Context, key string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.locks[key]
+	if !ok || !r.now().Before(until) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+func (r *memoryLoginAttemptRepository) Unlock(_ context.Context, key string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.locks[key]
+	delete(r.locks, key)
+	return ok && r.now().Before(until), nil
+}
+
+// purge は since より前の失敗と期限を過ぎたロックを削除します。呼び出し側でロックを取得してください
+func (r *memoryLoginAttemptRepository) purge(since time.Time) {
+	for key, failures := range r.failures {
+		i := 0
+		for i < len(failures) && failures[i].Before(since) {
+			i++
+		}
+		if i == len(failures) {
+			delete(r.failures, key)
+		} else if i > 0 {
+			r.failures[key] = append([]time.Time(nil), failures[i:]...)
+		}
+	}
+	now := r.now()
+	for key, until := range r.locks {
+		if !now.Before(until) {
+			delete(r.locks, key)
+		}
+	}
+}
+
+type sqlLoginAttemptRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func (r *sqlLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at, since time.Time) ([]time.Time, error) {
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM login_failures WHERE failed_at < $1`, since); err != nil {
+			return apperrors.NewInternalError("Failed to purge login failures", err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO login_failures (key, failed_at) VALUES ($1, $2)`, key, at); err != nil {
+			return apperrors.NewInternalError("Failed to record login failure", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Failures(ctx, key, since)
+}
+
+func (r *sqlLoginAttemptRepository) Failures(ctx context.Context, key string, since time.Time) ([]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT failed_at FROM login_failures WHERE key = $1 AND failed_at >= $2 ORDER BY failed_at`, key, since)
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get login failures", err)
+	}
+	defer rows.Close()
+
+	var failures []time.Time
+	for rows.Next() {
+		var at time.Time
+		if err := rows.Scan(&at); err != nil {
+			return nil, apperrors.NewInternalError("Failed to get login failures", err)
+		}
+		failures = append(failures, at)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("Failed to get login failures", err)
+	}
+	return failures, nil
+}
+
+func (r *sqlLoginAttemptRepository) RemoveFailure(ctx context.Context, key string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM login_failures WHERE ctid IN (SELECT ctid FROM login_failures WHERE key = $1 AND failed_at = $2 LIMIT 1)`, key, at)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to remove login failure", err)
+	}
+	return nil
+}
+
+func (r *sqlLoginAttemptRepository) ClearFailures(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_failures WHERE key = $1`, key); err != nil {
+		return apperrors.NewInternalError("Failed to clear login failures", err)
+	}
+	return nil
+}
+
+func (r *sqlLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM login_lockouts WHERE locked_until <= $1`, r.now()); err != nil {
+			return apperrors.NewInternalError("Failed to purge login lockouts", err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO login_lockouts (key, locked_until) VALUES ($1, $2)
+			ON CONFLICT (key) DO UPDATE SET locked_until = GREATEST(login_lockouts.locked_until, EXCLUDED.locked_until)`,
+			key, until,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("Failed to lock account", err)
+		}
+		return nil
+	})
+}
+
+func (r *sqlLoginAttemptRepository) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var until time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT locked_until FROM login_lockouts WHERE key = $1 AND locked_until > $2`, key, r.now()).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, apperrors.NewInternalError("Failed to get account lockout", err)
+	}
+	return until, nil
+}
+
+func (r *sqlLoginAttemptRepository) Unlock(ctx context.Context, key string) (bool, error) {
+	var unlocked bool
+	err := r.db.QueryRowContext(ctx,
+		`WITH deleted AS (DELETE FROM login_lockouts WHERE key = $1 RETURNING locked_until)
+		SELECT EXISTS (SELECT 1 FROM deleted WHERE locked_until > $2)`, key, r.now()).Scan(&unlocked)
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to unlock account", err)
+	}
+	return unlocked, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("正常系: memory ストアは since より前の失敗を削除して数える", func(t *testing.T) {
+		repo := NewMemoryLoginAttemptRepository()
+		_, err := repo.RecordFailure(ctx, "user:alice", now.Add(-20*time.Minute), now.Add(-time.Hour))
+		require.NoError(t, err)
+		_, err = repo.RecordFailure(ctx, "user:bob", now.Add(-20*time.Minute), now.Add(-time.Hour))
+		require.NoError(t, err)
+
+		failures, err := repo.RecordFailure(ctx, "user:alice", now, now.Add(-15*time.Minute))
+
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{now}, failures)
+		bob, err := repo.Failures(ctx, "user:bob", now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, bob, "他のキーの古い失敗も削除する")
+	})
+
+	t.Run("正常系: memory ストアは指定した時刻の失敗を 1 件だけ削除する", func(t *testing.T) {
+		repo := NewMemoryLoginAttemptRepository()
+		since := now.Add(-time.Hour)
+		for _, at := range []time.Time{now.Add(-time.Minute), now, now} {
+			_, err := repo.RecordFailure(ctx, "ip:192.0.2.1", at, since)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, repo.RemoveFailure(ctx, "ip:192.0.2.1", now))
+
+		failures, err := repo.Failures(ctx, "ip:192.0.2.1", since)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{now.Add(-time.Minute), now}, failures)
+	})
+
+	t.Run("正常系: memory ストアは期限を過ぎたロックを無視し、解除したロックが有効だったかを返す", func(t *testing.T) {
+		repo := NewMemoryLoginAttemptRepository()
+		require.NoError(t, repo.Lock(ctx, "user:alice", now.Add(time.Minute)))
+		require.NoError(t, repo.Lock(ctx, "user:bob", now.Add(-time.Minute)))
+
+		until, err := repo.LockedUntil(ctx, "user:alice")
+		require.NoError(t, err)
+		assert.True(t, until.Equal(now.Add(time.Minute)))
+		until, err = repo.LockedUntil(ctx, "user:bob")
+		require.NoError(t, err)
+		assert.True(t, until.IsZero())
+
+		unlocked, err := repo.Unlock(ctx, "user:alice")
+		require.NoError(t, err)
+		assert.True(t, unlocked)
+		unlocked, err = repo.Unlock(ctx, "user:bob")
+		require.NoError(t, err)
+		assert.False(t, unlocked)
+	})
+
+	t.Run("正常系: SQL ストアは記録した後にウィンドウ内の失敗を返す", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		since := now.Add(-15 * time.Minute)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM login_failures WHERE failed_at < \$1`).WithArgs(since).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO login_failures \(key, failed_at\) VALUES \(\$1, \$2\)`).WithArgs("ip:192.0.2.1", now).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(`SELECT failed_at FROM login_failures WHERE key = \$1 AND failed_at >= \$2 ORDER BY failed_at`).
+			WithArgs("ip:192.0.2.1", since).
+			WillReturnRows(sqlmock.NewRows([]string{"failed_at"}).AddRow(since.Add(time.Minute)).AddRow(now))
+
+		failures, err := (&sqlLoginAttemptRepository{db: db, now: time.Now}).RecordFailure(ctx, "ip:192.0.2.1", now, since)
+
+		require.NoError(t, err)
+		assert.Len(t, failures, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+)
+
+// memorySampleRepository はプロセス内にサンプルを保持するローカルリポジトリです
+type memorySampleRepository struct {
+	mu      sync.RWMutex
+	samples map[string]*models.Sample
+}
+
+func NewSampleRepository() SampleRepository {
+	return &memorySampleRepository{
+		samples: make(map[string]*models.Sample),
+	}
+}
+
+func (r *memorySampleRepository) Get(_ context.Context, ID string) (*models.Sample, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sample, ok := r.samples[ID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("Sample not found", nil)
+	}
+	return cloneSample(sample), nil
+}
+
+// List は ID 順に並べたサンプルを返します
+func (r *memorySampleRepository) List(_ context.Context, offset, limit *int) ([]*models.Sample, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	IDs := make([]string, 0, len(r.samples))
+	for ID := range r.samples {
+		IDs = append(IDs, ID)
+	}
+	sort.Strings(IDs)
+
+	start := 0
+	if offset != nil {
+		start = min(max(*offset, 0), len(IDs))
+	}
+	end := len(IDs)
+	if limit != nil {
+		end = min(start+max(*limit, 0), len(IDs))
+	}
+
+	samples := make([]*models.Sample, 0, end-start)
+	for _, ID := range IDs[start:end] {
+		samples = append(samples, cloneSample(r.samples[ID]))
+	}
+	return samples, nil
+}
+
+func (r *memorySampleRepository) Save(_ context.Context, sample *models.Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.samples[sample.ID] = cloneSample(sample)
+	return nil
+}
+
+func (r *memorySampleRepository) Delete(_ context.Context, ID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.samples[ID]; !ok {
+		return apperrors.NewNotFoundError("Sample not found", nil)
+	}
+	delete(r.samples, ID)
+	return nil
+}
+
+// cloneSample は保持しているデータが呼び出し元から変更されないようにコピーを返します
+func cloneSample(s *models.Sample) *models.Sample {
+	cloned := *s
+	if s.ArrayVal != nil {
+		cloned.ArrayVal = append([]string(nil), s.ArrayVal...)
+	}
+	return &cloned
+}
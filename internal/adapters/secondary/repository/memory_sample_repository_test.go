@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySampleRepository(t *testing.T) {
+	ctx := context.Background()
+	intPtr := func(v int) *int { return &v }
+
+	t.Run("正常系: 保存したサンプルを ID 順にページングして取得できる", func(t *testing.T) {
+		repo := NewSampleRepository()
+		for _, ID := range []string{"c", "a", "b"} {
+			require.NoError(t, repo.Save(ctx, &models.Sample{ID: ID}))
+		}
+
+		samples, err := repo.List(ctx, intPtr(1), intPtr(5))
+
+		require.NoError(t, err)
+		require.Len(t, samples, 2)
+		assert.Equal(t, "b", samples[0].ID)
+		assert.Equal(t, "c", samples[1].ID)
+	})
+
+	t.Run("正常系: 取得結果を変更しても保存内容は変わらない", func(t *testing.T) {
+		repo := NewSampleRepository()
+		require.NoError(t, repo.Save(ctx, &models.Sample{ID: "1", ArrayVal: []string{"x"}}))
+
+		got, err := repo.Get(ctx, "1")
+		require.NoError(t, err)
+		got.ArrayVal[0] = "changed"
+
+		again, err := repo.Get(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x"}, again.ArrayVal)
+	})
+
+	t.Run("異常系: 存在しないサンプルは NotFound", func(t *testing.T) {
+		repo := NewSampleRepository()
+
+		_, err := repo.Get(ctx, "missing")
+		assert.Error(t, err)
+		assert.Error(t, repo.Delete(ctx, "missing"))
+	})
+}
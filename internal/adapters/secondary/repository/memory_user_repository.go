@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+)
+
+// memoryUserRepository はプロセス内にユーザーを保持するストアです
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*models.User
+}
+
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{
+		users: make(map[string]*models.User),
+	}
+}
+
+func (r *memoryUserRepository) Get(_ context.Context, ID string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[ID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("User not found", nil)
+	}
+	return cloneUser(user), nil
+}
+
+func (r *memoryUserRepository) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email != "" && strings.EqualFold(user.Email, email) {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("User not found", nil)
+}
+
+func (r *memoryUserRepository) Create(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return apperrors.NewConflictError("User already exists", nil)
+	}
+	for _, existing := range r.users {
+		if user.Email != "" && strings.EqualFold(existing.Email, user.Email) {
+			return apperrors.NewConflictError("User already exists", nil)
+		}
+	}
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+func (r *memoryUserRepository) Update(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return apperrors.NewNotFoundError("User not found", nil)
+	}
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+func cloneUser(u *models.User) *models.User {
+	cloned := *u
+	if u.Roles != nil {
+		cloned.Roles = append([]string(nil), u.Roles...)
+	}
+	return &cloned
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+type MFARepository interface {
+	// Get はユーザーの登録を返します。登録がない場合は NotFound エラーを返します
+	Get(ctx context.Context, userID string) (*models.MFAEnrollment, error)
+	// Save は登録を保存します。既存の登録は置き換えます
+	Save(ctx context.Context, enrollment *models.MFAEnrollment) error
+	// Confirm は登録を確認済みにします。登録がない場合は NotFound エラーを返します
+	Confirm(ctx context.Context, userID string, at time.Time) error
+	// UseStep は使用済みのタイムステップを step に進めます
+	// 並行したリクエストで同じコードが使われた場合は一方のみ true を返します
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode はリカバリーコードを消費します。未使用のコードでない場合は false を返します
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	// Delete は登録を削除します。登録がない場合は NotFound エラーを返します
+	Delete(ctx context.Context, userID string) error
+}
+
+// NewMFARepository はユーザーストアと同じ種類のストアを返します
+func NewMFARepository(cfg *config.AppConfig, db *sql.DB) (MFARepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlMFARepository{db: db}, nil
+	}
+	return NewMemoryMFARepository(), nil
+}
+
+type memoryMFARepository struct {
+	mu          sync.Mutex
+	enrollments map[string]models.MFAEnrollment
+}
+
+func NewMemoryMFARepository() MFARepository {
+	return &memoryMFARepository{
+		enrollments: make(map[string]models.MFAEnrollment),
+	}
+}
+
+func (r *memoryMFARepository) Get(_ context.Context, userID string) (*models.MFAEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.enrollments[userID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("MFA enrollment not found", nil)
+	}
+	e.RecoveryCodeHashes = slices.Clone(e.RecoveryCodeHashes)
+	return &e, nil
+}
+
+func (r *memoryMFARepository) Save(_ context.Context, enrollment *models.MFAEnrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := *enrollment
+	e.RecoveryCodeHashes = slices.Clone(e.RecoveryCodeHashes)
+	r.enrollments[e.UserID] = e
+	return nil
+}
+
+func (r *memoryMFARepository) Confirm(_ context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.enrollments[userID]
+	if !ok {
+		return apperrors.NewNotFoundError("MFA enrollment not found", nil)
+	}
+	if e.ConfirmedAt == nil {
+		e.ConfirmedAt = &at
+		r.enrollments[userID] = e
+	}
+	return nil
+}
+
+func (r *memoryMFARepository) UseStep(_ context.Context, userID string, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.enrollments[userID]
+	if !ok || step <= e.LastUsedStep {
+		return false, nil
+	}
+	e.LastUsedStep = step
+	r.enrollments[userID] = e
+	return true, nil
+}
+
+func (r *memoryMFARepository) UseRecoveryCode(_ context.Context, userID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.enrollments[userID]
+	if !ok {
+		return false, nil
+	}
+	i := slices.Index(e.RecoveryCodeHashes, codeHash)
+	if i < 0 {
+		return false, nil
+	}
+	e.RecoveryCodeHashes = slices.Delete(slices.Clone(e.RecoveryCodeHashes), i, i+1)
+	r.enrollments[userID] = e
+	return true, nil
+}
+
+func (r *memoryMFARepository) Delete(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.enrollments[userID]; !ok {
+		return apperrors.NewNotFoundError("MFA enrollment not found", nil)
+	}
+	delete(r.enrollments, userID)
+	return nil
+}
+
+// sqlMFARepository はリカバリーコードのハッシュを空白区切りの文字列で保存します
+type sqlMFARepository struct {
+	db *sql.DB
+}
+
+func (r *sqlMFARepository) Get(ctx context.Context, userID string) (*models.MFAEnrollment, error) {
+	var e models.MFAEnrollment
+	var hashes string
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id, secret_ciphertext, recovery_code_hashes, last_used_step, confirmed_at, created_at FROM mfa_enrollments WHERE user_id = $1`, userID,
+	).Scan(&e.UserID, &e.SecretCiphertext, &hashes, &e.LastUsedStep, &confirmedAt, &e.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("MFA enrollment not found", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get MFA enrollment", err)
+	}
+	e.RecoveryCodeHashes = strings.Fields(hashes)
+	if confirmedAt.Valid {
+		e.ConfirmedAt = &confirmedAt.Time
+	}
+	return &e, nil
+}
+
+func (r *sqlMFARepository) Save(ctx context.Context, enrollment *models.MFAEnrollment) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO mfa_enrollments (user_id, secret_ciphertext, recovery_code_hashes, last_used_step, confirmed_at, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET secret_ciphertext = EXCLUDED.secret_ciphertext, recovery_code_hashes = EXCLUDED.recovery_code_hashes,
+		last_used_step = EXCLUDED.last_used_step, confirmed_at = EXCLUDED.confirmed_at, created_at = EXCLUDED.created_at`,
+		enrollment.UserID, enrollment.SecretCiphertext, strings.Join(enrollment.RecoveryCodeHashes, " "),
+		enrollment.LastUsedStep, enrollment.ConfirmedAt, enrollment.CreatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to save MFA enrollment", err)
+	}
+	return nil
+}
+
+func (r *sqlMFARepository) Confirm(ctx context.Context, userID string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE mfa_enrollments SET confirmed_at = COALESCE(confirmed_at, $2) WHERE user_id = $1`, userID, at,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to confirm MFA enrollment", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("Failed to confirm MFA enrollment", err)
+	}
+	if n == 0 {
+		return apperrors.NewNotFoundError("MFA enrollment not found", nil)
+	}
+	return nil
+}
+
+// UseStep は条件付きの UPDATE で使用済みのステップを進め、並行したリクエストでの再利用を防ぎます
+func (r *sqlMFARepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE mfa_enrollments SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`, userID, step,
+	)
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to update MFA enrollment", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to update MFA enrollment", err)
+	}
+	return n > 0, nil
+}
+
+func (r *sqlMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	used := false
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var hashes string
+		err := tx.QueryRowContext(ctx,
+			`SELECT recovery_code_hashes FROM mfa_enrollments WHERE user_id = $1 FOR UPDATE`, userID,
+		).Scan(&hashes)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return apperrors.NewInternalError("Failed to get MFA enrollment", err)
+		}
+
+		remaining := strings.Fields(hashes)
+		i := slices.Index(remaining, codeHash)
+		if i < 0 {
+			return nil
+		}
+		remaining = slices.Delete(remaining, i, i+1)
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE mfa_enrollments SET recovery_code_hashes = $2 WHERE user_id = $1`, userID, strings.Join(remaining, " "),
+		); err != nil {
+			return apperrors.NewInternalError("Failed to update MFA enrollment", err)
+		}
+		used = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return used, nil
+}
+
+func (r *sqlMFARepository) Delete(ctx context.Context, userID string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM mfa_enrollments WHERE user_id = $1`, userID)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to delete MFA enrollment", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return apperrors.NewInternalError("Failed to delete MFA enrollment", err)
+	}
+	if n == 0 {
+		return apperrors.NewNotFoundError("MFA enrollment not found", nil)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFARepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("正常系: memory ストアは使用済みのステップより後のものだけを受け付ける", func(t *testing.T) {
+		repo := NewMemoryMFARepository()
+		require.NoError(t, repo.Save(ctx, &models.MFAEnrollment{UserID: "alice", SecretCiphertext: "sealed", CreatedAt: now}))
+
+		ok, err := repo.UseStep(ctx, "alice", 100)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = repo.UseStep(ctx, "alice", 100)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = repo.UseStep(ctx, "bob", 100)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("正常系: memory ストアのリカバリーコードは一度だけ使える", func(t *testing.T) {
+		repo := NewMemoryMFARepository()
+		require.NoError(t, repo.Save(ctx, &models.MFAEnrollment{UserID: "alice", RecoveryCodeHashes: []string{"h1", "h2"}, CreatedAt: now}))
+
+		ok, err := repo.UseRecoveryCode(ctx, "alice", "h1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = repo.UseRecoveryCode(ctx, "alice", "h1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		e, err := repo.Get(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"h2"}, e.RecoveryCodeHashes)
+	})
+
+	t.Run("異常系: memory ストアは登録のないユーザーの削除を NotFound にする", func(t *testing.T) {
+		repo := NewMemoryMFARepository()
+
+		assertAppErrorType(t, repo.Delete(ctx, "alice"), apperrors.ErrorTypeNotFound)
+		_, err := repo.Get(ctx, "alice")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("正常系: SQL ストアは条件付きの UPDATE でステップを進める", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE mfa_enrollments SET last_used_step = \$2 WHERE user_id = \$1 AND last_used_step < \$2`).
+			WithArgs("alice", int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ok, err := (&sqlMFARepository{db: db}).UseStep(ctx, "alice", 100)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: SQL ストアは行をロックしてリカバリーコードを消費する", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT recovery_code_hashes FROM mfa_enrollments WHERE user_id = \$1 FOR UPDATE`).
+			WithArgs("alice").
+			WillReturnRows(sqlmock.NewRows([]string{"recovery_code_hashes"}).AddRow("h1 h2 h3"))
+		mock.ExpectExec(`UPDATE mfa_enrollments SET recovery_code_hashes = \$2 WHERE user_id = \$1`).
+			WithArgs("alice", "h1 h3").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		ok, err := (&sqlMFARepository{db: db}).UseRecoveryCode(ctx, "alice", "h2")
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type OAuthClientRepository interface {
+	// Get は ID に一致するクライアントを返します。存在しない場合は NotFound エラーを返します
+	Get(ctx context.Context, id string) (*models.OAuthClient, error)
+	// Create はクライアントを登録します。ID が重複する場合は Conflict エラーを返します
+	Create(ctx context.Context, client *models.OAuthClient) error
+}
+
+// oauthClientSeed は memory ストアに読み込むクライアントの定義です
+// client_secret を指定した場合は読み込み時にハッシュ化します
+type oauthClientSeed struct {
+	ID         string   `json:"client_id"`
+	Secret     string   `json:"client_secret"`
+	SecretHash string   `json:"client_secret_hash"`
+	Scopes     []string `json:"scopes"`
+	Roles      []string `json:"roles"`
+}
+
+// NewOAuthClientRepository はユーザーストアと同じ種類のストアを返します
+// memory の場合は oauth_clients_file のクライアントを読み込みます
+func NewOAuthClientRepository(cfg *config.AppConfig, db *sql.DB, hasher services.PasswordHasher) (OAuthClientRepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlOAuthClientRepository{db: db}, nil
+	}
+
+	repo := NewMemoryOAuthClientRepository()
+	if cfg.OAuthClientsFile != "" {
+		if err := seedOAuthClients(repo, cfg.OAuthClientsFile, hasher); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+func seedOAuthClients(repo OAuthClientRepository, path string, hasher services.PasswordHasher) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read oauth clients file: %w", err)
+	}
+	var seeds []oauthClientSeed
+	if err := json.Unmarshal(data, &seeds); err != nil {
+		return fmt.Errorf("failed to decode oauth clients file: %w", err)
+	}
+
+	for _, seed := range seeds {
+		hash := seed.SecretHash
+		if hash == "" {
+			if seed.Secret == "" {
+				return fmt.Errorf("oauth client %q: client_secret or client_secret_hash is required", seed.ID)
+			}
+			if hash, err = hasher.Hash(seed.Secret); err != nil {
+				return err
+			}
+		}
+		if err := repo.Create(context.Background(), &models.OAuthClient{
+			ID:         seed.ID,
+			SecretHash: hash,
+			Scopes:     seed.Scopes,
+			Roles:      seed.Roles,
+		}); err != nil {
+			return fmt.Errorf("failed to seed oauth client %q: %w", seed.ID, err)
+		}
+	}
+	return nil
+}
+
+type memoryOAuthClientRepository struct {
+	mu      sync.RWMutex
+	clients map[string]models.OAuthClient
+}
+
+func NewMemoryOAuthClientRepository() OAuthClientRepository {
+	return &memoryOAuthClientRepository{
+		clients: make(map[string]models.OAuthClient),
+	}
+}
+
+func (r *memoryOAuthClientRepository) Get(_ context.Context, id string) (*models.OAuthClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("OAuth client not found", nil)
+	}
+	return &client, nil
+}
+
+func (r *memoryOAuthClientRepository) Create(_ context.Context, client *models.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[client.ID]; ok {
+		return apperrors.NewConflictError("OAuth client already exists", nil)
+	}
+	r.clients[client.ID] = *client
+	return nil
+}
+
+// sqlOAuthClientRepository はスコープとロールを空白区切りの文字列で保存します
+type sqlOAuthClientRepository struct {
+	db *sql.DB
+}
+
+func (r *sqlOAuthClientRepository) Get(ctx context.Context, id string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	var scopes, roles string
+	err := r.db.QueryRowContext(ctx, `SELECT client_id, secret_hash, scopes, roles FROM oauth_clients WHERE client_id = $1`, id).
+		Scan(&client.ID, &client.SecretHash, &scopes, &roles)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("OAuth client not found", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get OAuth client", err)
+	}
+	client.Scopes = strings.Fields(scopes)
+	client.Roles = strings.Fields(roles)
+	return &client, nil
+}
+
+func (r *sqlOAuthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO oauth_clients (client_id, secret_hash, scopes, roles, created_at) VALUES ($1, $2, $3, $4, now())`,
+		client.ID, client.SecretHash, strings.Join(client.Scopes, " "), strings.Join(client.Roles, " "),
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return apperrors.NewConflictError("OAuth client already exists", err)
+		}
+		return apperrors.NewInternalError("Failed to save OAuth client", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthClientRepository(t *testing.T) {
+	ctx := context.Background()
+	hasher := services.NewPasswordHasher()
+
+	t.Run("正常系: memory ストアは oauth_clients_file のクライアントを読み込み、平文のシークレットはハッシュ化する", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "clients.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[
+			{"client_id": "batch", "client_secret": "s3cret", "scopes": ["samples:read"], "roles": ["role:teamA:viewer"]}
+		]`), 0o600))
+
+		repo, err := NewOAuthClientRepository(&config.AppConfig{UserStore: "memory", OAuthClientsFile: path}, nil, hasher)
+		require.NoError(t, err)
+
+		client, err := repo.Get(ctx, "batch")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"samples:read"}, client.Scopes)
+		assert.NotContains(t, client.SecretHash, "s3cret")
+		ok, err := hasher.Verify("s3cret", client.SecretHash)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = repo.Get(ctx, "unknown")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("異常系: シークレットのないクライアントは読み込めない", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "clients.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"client_id": "batch"}]`), 0o600))
+
+		_, err := NewOAuthClientRepository(&config.AppConfig{UserStore: "memory", OAuthClientsFile: path}, nil, hasher)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("正常系: SQL ストアは空白区切りのスコープとロールを復元する", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT client_id, secret_hash, scopes, roles FROM oauth_clients WHERE client_id = \$1`).
+			WithArgs("batch").
+			WillReturnRows(sqlmock.NewRows([]string{"client_id", "secret_hash", "scopes", "roles"}).
+				AddRow("batch", "hash", "samples:read api-keys:manage", "role:teamA:viewer"))
+
+		client, err := (&sqlOAuthClientRepository{db: db}).Get(ctx, "batch")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"samples:read", "api-keys:manage"}, client.Scopes)
+		assert.Equal(t, []string{"role:teamA:viewer"}, client.Roles)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
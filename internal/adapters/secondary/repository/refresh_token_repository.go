@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// Get はハッシュに一致するトークンを返します。存在しない場合は NotFound エラーを返します
+	Get(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// MarkUsed は未使用かつ未失効のトークンを使用済みにします
+	// 同時に使われた場合も成功するのは 1 度だけで、それ以外は false を返します
+	MarkUsed(ctx context.Context, tokenHash string, at time.Time) (bool, error)
+	// RevokeFamily はファミリーのトークンをすべて失効させます
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// RevokeByUser はユーザーのトークンをすべて失効させます
+	RevokeByUser(ctx context.Context, userID string, at time.Time) error
+}
+
+// NewRefreshTokenRepository はユーザーストアと同じ種類のストアを返します
+func NewRefreshTokenRepository(cfg *config.AppConfig, db *sql.DB) (RefreshTokenRepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlRefreshTokenRepository{db: db}, nil
+	}
+	return NewMemoryRefreshTokenRepository(), nil
+}
+
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{
+		tokens: make(map[string]models.RefreshToken),
+	}
+}
+
+func (r *memoryRefreshTokenRepository) Create(_ context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 期限切れのトークンは再利用の検知にも不要なため、ここでまとめて削除する
+	for hash, t := range r.tokens {
+		if t.ExpiresAt.Before(token.CreatedAt) {
+			delete(r.tokens, hash)
+		}
+	}
+	r.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) Get(_ context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("Refresh token not found", nil)
+	}
+	return &token, nil
+}
+
+func (r *memoryRefreshTokenRepository) MarkUsed(_ context.Context, tokenHash string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &at
+	r.tokens[tokenHash] = token
+	return true, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(_ context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.tokens[hash] = token
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeByUser(_ context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.tokens[hash] = token
+		}
+	}
+	return nil
+}
+
+type sqlRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *sqlRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (token_hash, family_id, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		token.TokenHash, token.FamilyID, token.UserID, token.ExpiresAt, token.CreatedAt,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to save refresh token", err)
+	}
+	return nil
+}
+
+func (r *sqlRefreshTokenRepository) Get(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token := models.RefreshToken{TokenHash: tokenHash}
+	var usedAt, revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT family_id, user_id, expires_at, created_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = $1`, tokenHash,
+	).Scan(&token.FamilyID, &token.UserID, &token.ExpiresAt, &token.CreatedAt, &usedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("Refresh token not found", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get refresh token", err)
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+// MarkUsed は条件付き UPDATE で更新するため、同時に使われても 1 度しか成功しません
+func (r *sqlRefreshTokenRepository) MarkUsed(ctx context.Context, tokenHash string, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET used_at = $2 WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL`, tokenHash, at,
+	)
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to update refresh token", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to update refresh token", err)
+	}
+	return n == 1, nil
+}
+
+func (r *sqlRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`, familyID, at,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to revoke refresh tokens", err)
+	}
+	return nil
+}
+
+func (r *sqlRefreshTokenRepository) RevokeByUser(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`, userID, at,
+	)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to revoke refresh tokens", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("正常系: memory ストアは使用済みにできるのが 1 度だけ", func(t *testing.T) {
+		repo := NewMemoryRefreshTokenRepository()
+		require.NoError(t, repo.Create(ctx, &models.RefreshToken{TokenHash: "h1", FamilyID: "f1", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+
+		first, err := repo.MarkUsed(ctx, "h1", now)
+		require.NoError(t, err)
+		second, err := repo.MarkUsed(ctx, "h1", now)
+		require.NoError(t, err)
+
+		assert.True(t, first)
+		assert.False(t, second)
+	})
+
+	t.Run("正常系: memory ストアはファミリーのトークンだけを失効させる", func(t *testing.T) {
+		repo := NewMemoryRefreshTokenRepository()
+		require.NoError(t, repo.Create(ctx, &models.RefreshToken{TokenHash: "a", FamilyID: "f1", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+		require.NoError(t, repo.Create(ctx, &models.RefreshToken{TokenHash: "b", FamilyID: "f2", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+
+		require.NoError(t, repo.RevokeFamily(ctx, "f1", now))
+
+		a, err := repo.Get(ctx, "a")
+		require.NoError(t, err)
+		b, err := repo.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.NotNil(t, a.RevokedAt)
+		assert.Nil(t, b.RevokedAt)
+	})
+
+	t.Run("正常系: SQL ストアは条件付き UPDATE で使用済みにする", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE refresh_tokens SET used_at = \$2 WHERE token_hash = \$1 AND used_at IS NULL AND revoked_at IS NULL`).
+			WithArgs("h1", now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		marked, err := (&sqlRefreshTokenRepository{db: db}).MarkUsed(ctx, "h1", now)
+
+		require.NoError(t, err)
+		assert.False(t, marked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -9,4 +9,7 @@ import (
 type SampleRepository interface {
 	Get(ctx context.Context, id string) (*models.Sample, error)
 	List(ctx context.Context, offset, limit *int) ([]*models.Sample, error)
+	// Save は ID が一致するサンプルを置き換え、存在しない場合は追加します
+	Save(ctx context.Context, sample *models.Sample) error
+	Delete(ctx context.Context, id string) error
 }
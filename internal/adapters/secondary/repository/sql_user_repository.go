@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgUniqueViolation は PostgreSQL の一意制約違反のエラーコードです
+const pgUniqueViolation = "23505"
+
+// sqlUserRepository は PostgreSQL にユーザーを保存するストアです
+// スキーマは internal/infrastructure/database/schema を参照してください
+type sqlUserRepository struct {
+	db *sql.DB
+}
+
+func NewSQLUserRepository(db *sql.DB) UserRepository {
+	return &sqlUserRepository{
+		db: db,
+	}
+}
+
+const selectUserColumns = `SELECT id, name, email, email_verified, password_hash, created_at, updated_at FROM users`
+
+func (r *sqlUserRepository) Get(ctx context.Context, ID string) (*models.User, error) {
+	return r.getBy(ctx, selectUserColumns+` WHERE id = $1`, ID)
+}
+
+func (r *sqlUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getBy(ctx, selectUserColumns+` WHERE lower(email) = lower($1)`, email)
+}
+
+func (r *sqlUserRepository) getBy(ctx context.Context, query string, arg any) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRowContext(ctx, query, arg).
+		Scan(&user.ID, &user.Name, &user.Email, &user.EmailVerified, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NewNotFoundError("User not found", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get user", err)
+	}
+
+	roles, err := r.roles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+	return &user, nil
+}
+
+func (r *sqlUserRepository) Create(ctx context.Context, user *models.User) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO users (id, name, email, email_verified, password_hash, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			user.ID, user.Name, user.Email, user.EmailVerified, user.PasswordHash, user.CreatedAt, user.UpdatedAt,
+		)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return apperrors.NewConflictError("User already exists", nil)
+		}
+		if err != nil {
+			return apperrors.NewInternalError("Failed to create user", err)
+		}
+		return insertRoles(ctx, tx, user)
+	})
+}
+
+func (r *sqlUserRepository) Update(ctx context.Context, user *models.User) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE users SET name = $2, email = $3, email_verified = $4, password_hash = $5, updated_at = $6 WHERE id = $1`,
+			user.ID, user.Name, user.Email, user.EmailVerified, user.PasswordHash, user.UpdatedAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("Failed to update user", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return apperrors.NewNotFoundError("User not found", nil)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1`, user.ID); err != nil {
+			return apperrors.NewInternalError("Failed to update user roles", err)
+		}
+		return insertRoles(ctx, tx, user)
+	})
+}
+
+func (r *sqlUserRepository) roles(ctx context.Context, ID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role`, ID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to get user roles", err)
+	}
+	// nolint:errcheck
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, apperrors.NewInternalError("Failed to get user roles", err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewInternalError("Failed to get user roles", err)
+	}
+	return roles, nil
+}
+
+func insertRoles(ctx context.Context, tx *sql.Tx, user *models.User) error {
+	for _, role := range user.Roles {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO user_roles (user_id, role) VALUES ($1, $2)`, user.ID, role); err != nil {
+			return apperrors.NewInternalError("Failed to save user roles", err)
+		}
+	}
+	return nil
+}
+
+// withTx は fn をトランザクション内で実行し、エラーの場合はロールバックします
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to begin transaction", err)
+	}
+	if err := fn(tx); err != nil {
+		// nolint:errcheck
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return apperrors.NewInternalError("Failed to commit transaction", fmt.Errorf("commit: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLUserRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("正常系: ユーザーとロールを取得できる", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, name, email, email_verified, password_hash, created_at, updated_at FROM users WHERE id = \$1`).
+			WithArgs("user123").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "email_verified", "password_hash", "created_at", "updated_at"}).
+				AddRow("user123", "User", "user@example.com", true, "hash", now, now))
+		mock.ExpectQuery(`SELECT role FROM user_roles WHERE user_id = \$1`).
+			WithArgs("user123").
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("role:teamA:editor").AddRow("role:teamB:viewer"))
+
+		user, err := NewSQLUserRepository(db).Get(ctx, "user123")
+
+		require.NoError(t, err)
+		assert.Equal(t, "hash", user.PasswordHash)
+		assert.Equal(t, []string{"role:teamA:editor", "role:teamB:viewer"}, user.Roles)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 存在しないユーザーは NotFound", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT .* FROM users`).WithArgs("missing").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err = NewSQLUserRepository(db).Get(ctx, "missing")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("正常系: ユーザーとロールをトランザクションで作成する", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO user_roles`).WithArgs("user123", "role:teamA:editor").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = NewSQLUserRepository(db).Create(ctx, &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("異常系: 一意制約違反は Conflict になりロールバックされる", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).WillReturnError(&pgconn.PgError{Code: pgUniqueViolation})
+		mock.ExpectRollback()
+
+		err = NewSQLUserRepository(db).Create(ctx, &models.User{ID: "user123"})
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func assertAppErrorType(t *testing.T, err error, want apperrors.ErrorType) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	if assert.True(t, errors.As(err, &appErr), "expected *apperrors.AppError, got %T", err) {
+		assert.Equal(t, want, appErr.Type)
+	}
+}
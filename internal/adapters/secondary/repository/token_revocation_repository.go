@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// TokenRevocationRepository は有効期限前に失効させたアクセストークンを保持します
+// エントリはトークンの有効期限（expiresAt）を過ぎると自動的に無効になります
+type TokenRevocationRepository interface {
+	// RevokeToken は jti のトークンを失効させます
+	RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error
+	// ClaimToken は jti のトークンを失効させ、この呼び出しで失効させた場合は true を返します
+	// 使い捨てのトークン（MFA のチャレンジなど）を並行したリクエストのうち 1 つだけが使えるようにします
+	ClaimToken(ctx context.Context, jti, userID string, expiresAt time.Time) (bool, error)
+	// RevokeUser は issuedBefore 以前に発行されたユーザーのトークンをすべて失効させます
+	RevokeUser(ctx context.Context, userID string, issuedBefore, expiresAt time.Time) error
+	// IsRevoked はトークンが失効しているかを返します
+	IsRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) (bool, error)
+}
+
+// NewTokenRevocationRepository はユーザーストアと同じ種類のストアを返します
+func NewTokenRevocationRepository(cfg *config.AppConfig, db *sql.DB) (TokenRevocationRepository, error) {
+	if cfg.UserStore == "sql" {
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return &sqlTokenRevocationRepository{db: db, now: time.Now}, nil
+	}
+	return NewMemoryTokenRevocationRepository(), nil
+}
+
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+type memoryTokenRevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[string]userRevocation
+	now    func() time.Time
+}
+
+func NewMemoryTokenRevocationRepository() TokenRevocationRepository {
+	return &memoryTokenRevocationRepository{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]userRevocation),
+		now:    time.Now,
+	}
+}
+
+func (r *memoryTokenRevocationRepository) RevokeToken(_ context.Context, jti, _ string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purge()
+	r.tokens[jti] = expiresAt
+	return nil
+}
+
+func (r *memoryTokenRevocationRepository) ClaimToken(_ context.Context, jti, _ string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purge()
+
+	if _, ok := r.tokens[jti]; ok {
+		return false, nil
+	}
+	r.tokens[jti] = expiresAt
+	return true, nil
+}
+
+func (r *memoryTokenRevocationRepository) RevokeUser(_ context.Context, userID string, issuedBefore, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purge()
+
+	// 既存のエントリより範囲が狭くならないようにする
+	if existing, ok := r.users[userID]; ok {
+		if existing.issuedBefore.After(issuedBefore) {
+			issuedBefore = existing.issuedBefore
+		}
+		if existing.expiresAt.After(expiresAt) {
+			expiresAt = existing.expiresAt
+		}
+	}
+	r.users[userID] = userRevocation{issuedBefore: issuedBefore, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *memoryTokenRevocationRepository) IsRevoked(_ context.Context, jti, userID string, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	if expiresAt, ok := r.tokens[jti]; ok && jti != "" && now.Before(expiresAt) {
+		return true, nil
+	}
+	if u, ok := r.users[userID]; ok && now.Before(u.expiresAt) && !issuedAt.After(u.issuedBefore) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// purge は有効期限を過ぎたエントリを削除します。呼び出し側でロックを取得してください
+func (r *memoryTokenRevocationRepository) purge() {
+	now := r.now()
+	for jti, expiresAt := range r.tokens {
+		if !now.Before(expiresAt) {
+			delete(r.tokens, jti)
+		}
+	}
+	for userID, u := range r.users {
+		if !now.Before(u.expiresAt) {
+			delete(r.users, userID)
+		}
+	}
+}
+
+type sqlTokenRevocationRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func (r *sqlTokenRevocationRepository) RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= $1`, r.now()); err != nil {
+			return apperrors.NewInternalError("Failed to purge revoked tokens", err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`,
+			jti, userID, expiresAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("Failed to revoke token", err)
+		}
+		return nil
+	})
+}
+
+func (r *sqlTokenRevocationRepository) ClaimToken(ctx context.Context, jti, userID string, expiresAt time.Time) (bool, error) {
+	var claimed bool
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= $1`, r.now()); err != nil {
+			return apperrors.NewInternalError("Failed to purge revoked tokens", err)
+		}
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`,
+			jti, userID, expiresAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("Failed to revoke token", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return apperrors.NewInternalError("Failed to revoke token", err)
+		}
+		claimed = n == 1
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+func (r *sqlTokenRevocationRepository) RevokeUser(ctx context.Context, userID string, issuedBefore, expiresAt time.Time) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM revoked_users WHERE expires_at <= $1`, r.now()); err != nil {
+			return apperrors.NewInternalError("Failed to purge revoked users", err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO revoked_users (user_id, revoked_before, expires_at) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO UPDATE SET
+				revoked_before = GREATEST(revoked_users.revoked_before, EXCLUDED.revoked_before),
+				expires_at = GREATEST(revoked_users.expires_at, EXCLUDED.expires_at)`,
+			userID, issuedBefore, expiresAt,
+		)
+		if err != nil {
+			return apperrors.NewInternalError("Failed to revoke user tokens", err)
+		}
+		return nil
+	})
+}
+
+func (r *sqlTokenRevocationRepository) IsRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > $4)
+			OR EXISTS (SELECT 1 FROM revoked_users WHERE user_id = $2 AND revoked_before >= $3 AND expires_at > $4)`,
+		jti, userID, issuedAt, r.now(),
+	).Scan(&revoked)
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to check token revocation", err)
+	}
+	return revoked, nil
+}
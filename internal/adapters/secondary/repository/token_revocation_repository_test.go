@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRevocationRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("正常系: memory ストアのエントリはトークンの有効期限で無効になる", func(t *testing.T) {
+		repo := NewMemoryTokenRevocationRepository().(*memoryTokenRevocationRepository)
+		repo.now = func() time.Time { return now }
+		require.NoError(t, repo.RevokeToken(ctx, "jti-1", "user123", now.Add(time.Minute)))
+
+		revoked, err := repo.IsRevoked(ctx, "jti-1", "user123", now)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		repo.now = func() time.Time { return now.Add(time.Minute) }
+		revoked, err = repo.IsRevoked(ctx, "jti-1", "user123", now)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+
+		require.NoError(t, repo.RevokeToken(ctx, "jti-2", "user123", now.Add(time.Hour)))
+		assert.NotContains(t, repo.tokens, "jti-1")
+	})
+
+	t.Run("正常系: memory ストアはユーザーの失効時刻以前に発行されたトークンだけを失効させる", func(t *testing.T) {
+		repo := NewMemoryTokenRevocationRepository().(*memoryTokenRevocationRepository)
+		repo.now = func() time.Time { return now }
+		require.NoError(t, repo.RevokeUser(ctx, "user123", now, now.Add(15*time.Minute)))
+
+		before, err := repo.IsRevoked(ctx, "a", "user123", now.Add(-time.Second))
+		require.NoError(t, err)
+		after, err := repo.IsRevoked(ctx, "b", "user123", now.Add(time.Second))
+		require.NoError(t, err)
+		otherUser, err := repo.IsRevoked(ctx, "c", "other", now.Add(-time.Second))
+		require.NoError(t, err)
+
+		assert.True(t, before)
+		assert.False(t, after)
+		assert.False(t, otherUser)
+	})
+
+	t.Run("正常系: memory ストアは同じ jti を一度だけ使わせる", func(t *testing.T) {
+		repo := NewMemoryTokenRevocationRepository()
+
+		first, err := repo.ClaimToken(ctx, "jti-1", "user123", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		second, err := repo.ClaimToken(ctx, "jti-1", "user123", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		assert.True(t, first)
+		assert.False(t, second)
+	})
+
+	t.Run("正常系: SQL ストアは既に失効している jti を使わせない", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expiresAt := now.Add(time.Minute)
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM revoked_tokens WHERE expires_at <= \$1`).WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO revoked_tokens \(jti, user_id, expires_at\) VALUES \(\$1, \$2, \$3\) ON CONFLICT \(jti\) DO NOTHING`).
+			WithArgs("jti-1", "user123", expiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		repo := &sqlTokenRevocationRepository{db: db, now: func() time.Time { return now }}
+		claimed, err := repo.ClaimToken(ctx, "jti-1", "user123", expiresAt)
+
+		require.NoError(t, err)
+		assert.False(t, claimed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("正常系: SQL ストアは有効期限内のエントリだけを参照する", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		issuedAt := now.Add(-time.Minute)
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM revoked_tokens WHERE jti = \$1 AND expires_at > \$4\)`).
+			WithArgs("jti-1", "user123", issuedAt, now).
+			WillReturnRows(sqlmock.NewRows([]string{"revoked"}).AddRow(true))
+
+		repo := &sqlTokenRevocationRepository{db: db, now: func() time.Time { return now }}
+		revoked, err := repo.IsRevoked(ctx, "jti-1", "user123", issuedAt)
+
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+type UserRepository interface {
+	// Get は ID に一致するユーザーを返します。存在しない場合は NotFound エラーを返します
+	Get(ctx context.Context, id string) (*models.User, error)
+	// GetByEmail はメールアドレスに一致するユーザーを返します。存在しない場合は NotFound エラーを返します
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// Create はユーザーを追加します。ID が既に存在する場合は Conflict エラーを返します
+	Create(ctx context.Context, user *models.User) error
+	// Update はユーザーを更新します。存在しない場合は NotFound エラーを返します
+	Update(ctx context.Context, user *models.User) error
+}
+
+// userSeed は memory ストアに読み込むユーザーの定義です
+// password を指定した場合は読み込み時にハッシュ化します
+type userSeed struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	Roles        []string `json:"roles"`
+	Password     string   `json:"password"`
+	PasswordHash string   `json:"password_hash"`
+	// Unverified を指定しない限り、シードしたユーザーはメール確認済みとして扱います
+	Unverified bool `json:"unverified"`
+}
+
+// NewUserRepository は設定に応じたユーザーストアを返します
+func NewUserRepository(cfg *config.AppConfig, db *sql.DB, hasher services.PasswordHasher) (UserRepository, error) {
+	switch cfg.UserStore {
+	case "sql":
+		if db == nil {
+			return nil, errors.New("user_store=sql requires database_dsn")
+		}
+		return NewSQLUserRepository(db), nil
+	case "memory":
+		repo := NewMemoryUserRepository()
+		if cfg.UserSeedFile != "" {
+			if err := seedUsers(repo, cfg.UserSeedFile, hasher); err != nil {
+				return nil, err
+			}
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unknown user store: %q", cfg.UserStore)
+	}
+}
+
+func seedUsers(repo UserRepository, path string, hasher services.PasswordHasher) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read user seed file: %w", err)
+	}
+	var seeds []userSeed
+	if err := json.Unmarshal(data, &seeds); err != nil {
+		return fmt.Errorf("failed to decode user seed file: %w", err)
+	}
+
+	now := time.Now()
+	for _, seed := range seeds {
+		hash := seed.PasswordHash
+		if hash == "" {
+			if hash, err = hasher.Hash(seed.Password); err != nil {
+				return err
+			}
+		}
+		if err := repo.Create(context.Background(), &models.User{
+			ID:            seed.ID,
+			Name:          seed.Name,
+			Email:         seed.Email,
+			Roles:         seed.Roles,
+			EmailVerified: !seed.Unverified,
+			PasswordHash:  hash,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}); err != nil {
+			return fmt.Errorf("failed to seed user %q: %w", seed.ID, err)
+		}
+	}
+	return nil
+}
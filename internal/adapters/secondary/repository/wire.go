@@ -0,0 +1,15 @@
+package repository
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(
+	NewSampleRepository,
+	NewUserRepository,
+	NewAccountTokenRepository,
+	NewRefreshTokenRepository,
+	NewTokenRevocationRepository,
+	NewAPIKeyRepository,
+	NewOAuthClientRepository,
+	NewLoginAttemptRepository,
+	NewMFARepository,
+)
@@ -5,4 +5,6 @@ type contextKey string
 const (
 	HTTPRequestKey contextKey = "httpRequest"
 	UserIDKey      contextKey = "userID"
+	// ClientCertificatePrincipalKey は検証済みのクライアント証明書に対応するプリンシパル（*models.User）です
+	ClientCertificatePrincipalKey contextKey = "clientCertificatePrincipal"
 )
@@ -0,0 +1,61 @@
+package freshness
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Info はレスポンスに含まれるデータの鮮度を表します
+type Info struct {
+	// Stale は有効期限切れのデータを返したことを表します
+	Stale bool
+	// Age はデータを取得してからの経過時間です
+	Age time.Duration
+	// Revalidating はバックグラウンドで再取得中であることを表します
+	// false の場合は upstream の失敗により再検証できなかったことを表します
+	Revalidating bool
+}
+
+type recorderKey struct{}
+
+type recorder struct {
+	mu   sync.Mutex
+	info Info
+}
+
+// WithRecorder はリクエスト内で返したデータの鮮度を記録するコンテキストを返します
+func WithRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recorderKey{}, &recorder{})
+}
+
+// MarkStale は古いデータを返したことを記録します
+// 複数のデータを返す場合は最も古いものを記録し、再検証の失敗を優先します
+func MarkStale(ctx context.Context, age time.Duration, revalidating bool) {
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if !rec.info.Stale {
+		rec.info = Info{Stale: true, Age: age, Revalidating: revalidating}
+		return
+	}
+	rec.info.Age = max(rec.info.Age, age)
+	rec.info.Revalidating = rec.info.Revalidating && revalidating
+}
+
+// FromContext は記録された鮮度を返します
+func FromContext(ctx context.Context) Info {
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return Info{}
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.info
+}
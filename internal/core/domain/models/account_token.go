@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AccountTokenPurpose はアカウント操作用トークンの用途です
+type AccountTokenPurpose string
+
+const (
+	AccountTokenPurposeEmailVerification AccountTokenPurpose = "email_verification"
+	AccountTokenPurposePasswordReset     AccountTokenPurpose = "password_reset"
+)
+
+// AccountToken はメールで送るワンタイムトークンです
+// トークンそのものは保存せず、SHA-256 ハッシュのみを保存します
+type AccountToken struct {
+	TokenHash string
+	UserID    string
+	Purpose   AccountTokenPurpose
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
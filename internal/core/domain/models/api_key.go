@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIKey はマシンクライアント向けの API キーです
+// キーそのものは保存せず、照合用のハッシュと表示用の接頭辞のみを保存します
+type APIKey struct {
+	// ID はキーの先頭部分（gk_ と 12 桁の 16 進数）で、一覧での識別にも使います
+	ID     string
+	UserID string
+	Name   string
+	// KeyHash はキー全体の SHA-256 です
+	KeyHash string
+	// Scopes はキーで許可する操作（samples:read など）です。所有者の権限の範囲内に限られます
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
@@ -2,8 +2,19 @@ package models
 
 import "github.com/golang-jwt/jwt/v5"
 
+// TokenPurposeMFAChallenge はパスワードを確認済みで、MFA のコードを待っていることを表すトークンの用途です
+const TokenPurposeMFAChallenge = "mfa_challenge"
+
+// Claims はアクセストークンのクレームです
+// RegisteredClaims.ID（jti）はトークンごとに一意で、ログアウト時の失効に使います
 type Claims struct {
 	UserID string   `json:"user_id"`
 	Roles  []string `json:"roles"`
+	// Scope は許可する操作を空白区切りで並べたものです（RFC 9068）。client_credentials で発行したトークンに付けます
+	Scope string `json:"scope,omitempty"`
+	// ClientID はトークンを取得した OAuth クライアントです。ユーザーのトークンでは空です
+	ClientID string `json:"client_id,omitempty"`
+	// Purpose はアクセストークン以外の用途のトークン（MFA のチャレンジなど）で設定します。アクセストークンでは空です
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,21 @@
+package models
+
+// JWK は RFC 7517 の公開鍵です
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS は公開鍵の集合です
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// MFAEnrollment はユーザーの TOTP の登録です
+// 登録直後は未確認で、認証アプリのコードを一度確認するまでログインには要求しません
+type MFAEnrollment struct {
+	UserID string
+	// SecretCiphertext は SecretBox で暗号化した TOTP のシークレットです
+	SecretCiphertext string
+	// RecoveryCodeHashes は未使用のリカバリーコードの SHA-256 です。使用したものは削除します
+	RecoveryCodeHashes []string
+	// LastUsedStep は最後に受け付けたコードのタイムステップで、同じコードの再利用を防ぎます
+	LastUsedStep int64
+	ConfirmedAt  *time.Time
+	CreatedAt    time.Time
+}
+
+// MFASetup は登録時に一度だけ返す情報です
+type MFASetup struct {
+	Secret        string
+	OTPAuthURI    string
+	RecoveryCodes []string
+}
+
+// LoginResult はログインの結果です
+// MFA が必要な場合はトークンの代わりに MFA のチャレンジを返します
+type LoginResult struct {
+	Tokens                *TokenPair
+	MFAChallenge          string
+	MFAChallengeExpiresIn time.Duration
+	// MFAEnrollmentRequired はロールにより MFA が必須で、まだ登録していないことを表します
+	MFAEnrollmentRequired bool
+}
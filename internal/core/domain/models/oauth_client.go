@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OAuthClient は client_credentials グラントでトークンを取得できる登録済みのクライアントです
+type OAuthClient struct {
+	ID string `json:"client_id"`
+	// SecretHash はクライアントシークレットの argon2id の PHC 形式のハッシュです
+	SecretHash string `json:"client_secret_hash"`
+	// Scopes はクライアントが要求できるスコープです
+	Scopes []string `json:"scopes"`
+	// Roles はクライアントのトークンに付与するロールです。許可する操作はさらにスコープで絞り込まれます
+	Roles []string `json:"roles"`
+}
+
+// ClientToken は client_credentials グラントで発行したアクセストークンです。リフレッシュトークンは発行しません
+type ClientToken struct {
+	AccessToken string
+	// ExpiresIn はアクセストークンの有効期間です
+	ExpiresIn time.Duration
+	// Scopes はトークンに付与したスコープです
+	Scopes []string
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RefreshToken はアクセストークンの再発行に使う不透明なトークンです
+// トークンそのものは保存せず、SHA-256 ハッシュのみを保存します
+// 同じログインから続くローテーションのトークンは同じ FamilyID を持ちます
+type RefreshToken struct {
+	TokenHash string
+	FamilyID  string
+	UserID    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	// UsedAt はローテーションで使用済みになった時刻です
+	UsedAt *time.Time
+	// RevokedAt はファミリーごと失効した時刻です
+	RevokedAt *time.Time
+}
+
+// TokenPair はログインやリフレッシュで発行するトークンの組です
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn はアクセストークンの有効期間です
+	ExpiresIn time.Duration
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rolePrefix はロール文字列の接頭辞です
+const rolePrefix = "role"
+
+// Role は role:<team>:<permission> 形式のロールを分解したものです
+// Permission は editor や viewer のような権限の束の名前で、具体的な操作の許可は設定の対応表で決まります
+type Role struct {
+	Team       string
+	Permission string
+}
+
+// ParseRole は role:<team>:<permission> 形式の文字列を Role に変換します
+func ParseRole(s string) (Role, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || parts[0] != rolePrefix || parts[1] == "" || parts[2] == "" {
+		return Role{}, fmt.Errorf("invalid role %q: expected role:<team>:<permission>", s)
+	}
+	return Role{Team: parts[1], Permission: parts[2]}, nil
+}
+
+func (r Role) String() string {
+	return rolePrefix + ":" + r.Team + ":" + r.Permission
+}
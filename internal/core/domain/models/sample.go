@@ -8,6 +8,7 @@ type Sample struct {
 	IntVal    int       `json:"int_val"`
 	ArrayVal  []string  `json:"array_val"`
 	Email     string    `json:"email"`
+	Team      string    `json:"team"` // 所有するチーム
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
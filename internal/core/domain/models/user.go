@@ -3,12 +3,17 @@ package models
 import "time"
 
 // User model
-// dummy user model
 type User struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Roles     []string  `json:"roles"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+	Email string   `json:"email"`
+	// Scopes は API キーなどで認証した場合に許可する操作です。nil の場合はロールの権限をすべて使えます
+	Scopes []string `json:"scopes,omitempty"`
+	// EmailVerified はメールアドレスの確認が完了しているかを表します
+	EmailVerified bool `json:"email_verified"`
+	// PasswordHash は argon2id の PHC 形式のハッシュです。レスポンスには含めません
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"slices"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+)
+
+// SubjectAttributes はユーザーの属性を返します
+//   - id, roles
+//   - permissions: ロール role:<team>:<permission> の <permission> の一覧
+//   - teams: <permission> ごとのチームの一覧（subject.teams.editor など）
+//   - all_teams: 所属するすべてのチーム
+func SubjectAttributes(user *models.User) map[string]any {
+	if user == nil {
+		return map[string]any{}
+	}
+
+	var permissions, allTeams []any
+	teams := map[string]any{}
+	for _, s := range user.Roles {
+		role, err := models.ParseRole(s)
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(permissions, any(role.Permission)) {
+			permissions = append(permissions, role.Permission)
+		}
+		if !slices.Contains(allTeams, any(role.Team)) {
+			allTeams = append(allTeams, role.Team)
+		}
+		list, _ := teams[role.Permission].([]any)
+		teams[role.Permission] = append(list, role.Team)
+	}
+
+	return map[string]any{
+		"id":             user.ID,
+		"roles":          toList(user.Roles),
+		"permissions":    permissions,
+		"teams":          teams,
+		"all_teams":      allTeams,
+		"email_verified": user.EmailVerified,
+	}
+}
+
+// SampleAttributes はサンプルの属性を返します
+func SampleAttributes(sample *models.Sample) map[string]any {
+	return map[string]any{
+		"type": "sample",
+		"id":   sample.ID,
+		"team": sample.Team,
+	}
+}
+
+// EnvironmentAttributes はリクエスト時点の環境の属性を返します
+func EnvironmentAttributes(now time.Time) map[string]any {
+	return map[string]any{
+		"time":    now.Format(time.RFC3339),
+		"hour":    now.Hour(),
+		"weekday": now.Weekday().String(),
+	}
+}
@@ -0,0 +1,187 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operator は条件の比較方法です
+type Operator string
+
+const (
+	OperatorEquals      Operator = "equals"
+	OperatorNotEquals   Operator = "not_equals"
+	OperatorIn          Operator = "in"           // 属性の値が比較する値（リスト）に含まれる
+	OperatorNotIn       Operator = "not_in"       // 属性の値が比較する値（リスト）に含まれない
+	OperatorContains    Operator = "contains"     // 属性の値（リスト）が比較する値を含む
+	OperatorNotContains Operator = "not_contains" // 属性の値（リスト）が比較する値を含まない
+	OperatorExists      Operator = "exists"       // 属性が空でない値を持つ
+	OperatorGTE         Operator = "gte"
+	OperatorLTE         Operator = "lte"
+)
+
+func (c Condition) validate() error {
+	if !isAttributePath(c.Attribute) {
+		return fmt.Errorf("invalid attribute %q: must start with subject., resource., action or environment.", c.Attribute)
+	}
+	if c.ValueFrom != "" && !isAttributePath(c.ValueFrom) {
+		return fmt.Errorf("invalid value_from %q", c.ValueFrom)
+	}
+	switch c.Operator {
+	case OperatorEquals, OperatorNotEquals, OperatorIn, OperatorNotIn, OperatorContains, OperatorNotContains:
+	case OperatorExists:
+		return nil
+	case OperatorGTE, OperatorLTE:
+		if c.ValueFrom == "" {
+			if _, ok := toNumber(c.Value); !ok {
+				return fmt.Errorf("operator %s requires a numeric value", c.Operator)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	if c.Value == nil && c.ValueFrom == "" {
+		return fmt.Errorf("operator %s requires value or value_from", c.Operator)
+	}
+	return nil
+}
+
+func isAttributePath(path string) bool {
+	return path == "action" ||
+		strings.HasPrefix(path, "subject.") ||
+		strings.HasPrefix(path, "resource.") ||
+		strings.HasPrefix(path, "environment.")
+}
+
+// evaluate は条件を満たすかを返します。存在しない属性は空の値として扱います
+func (c Condition) evaluate(input Input) bool {
+	left, _ := input.lookup(c.Attribute)
+	right := c.Value
+	if c.ValueFrom != "" {
+		right, _ = input.lookup(c.ValueFrom)
+	}
+
+	switch c.Operator {
+	case OperatorEquals:
+		return equal(left, right)
+	case OperatorNotEquals:
+		return !equal(left, right)
+	case OperatorIn:
+		return containsValue(right, left)
+	case OperatorNotIn:
+		return !containsValue(right, left)
+	case OperatorContains:
+		return containsValue(left, right)
+	case OperatorNotContains:
+		return !containsValue(left, right)
+	case OperatorExists:
+		return !isEmpty(left)
+	case OperatorGTE, OperatorLTE:
+		l, lok := toNumber(left)
+		r, rok := toNumber(right)
+		if !lok || !rok {
+			return false
+		}
+		if c.Operator == OperatorGTE {
+			return l >= r
+		}
+		return l <= r
+	}
+	return false
+}
+
+// lookup は subject.teams.editor のようなパスで属性を取り出します
+func (in Input) lookup(path string) (any, bool) {
+	if path == "action" {
+		return in.Action, true
+	}
+	root, rest, _ := strings.Cut(path, ".")
+	var current any
+	switch root {
+	case "subject":
+		current = in.Subject
+	case "resource":
+		current = in.Resource
+	case "environment":
+		current = in.Environment
+	default:
+		return nil, false
+	}
+	for _, key := range strings.Split(rest, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// toList はリストの属性を []any に揃えます。スカラーは 1 要素のリストとして扱います
+func toList(v any) []any {
+	switch l := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		return l
+	case []string:
+		list := make([]any, len(l))
+		for i, s := range l {
+			list[i] = s
+		}
+		return list
+	default:
+		return []any{v}
+	}
+}
+
+func containsValue(list, v any) bool {
+	for _, item := range toList(list) {
+		if equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal は YAML とアプリケーションで型の異なる数値や文字列を比較できるよう、文字列表現で比較します
+func equal(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func isEmpty(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []any:
+		return len(t) == 0
+	case []string:
+		return len(t) == 0
+	case map[string]any:
+		return len(t) == 0
+	}
+	return false
+}
+
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
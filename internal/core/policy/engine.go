@@ -0,0 +1,179 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// Input は評価の対象です。各属性は SubjectAttributes などで組み立てます
+type Input struct {
+	Subject     map[string]any
+	Action      string
+	Resource    map[string]any
+	Environment map[string]any
+}
+
+// Decision は評価の結果です
+type Decision struct {
+	// Allowed は実際に適用する判定です。dry-run では常に true です
+	Allowed bool
+	// Effect はポリシーによる判定です。dry-run でも強制した場合の判定を表します
+	Effect Effect
+	// PolicyID は判定を決めたポリシーです。既定の判定の場合は空です
+	PolicyID string
+	DryRun   bool
+}
+
+// Engine は宣言的なポリシーで属性ベースのアクセス制御を行います
+// 一致した deny は allow より優先し、どのポリシーにも一致しない場合は default_effect に従います
+type Engine interface {
+	Evaluate(ctx context.Context, input Input) Decision
+	// Enforce は拒否された場合に 403 を返します
+	Enforce(ctx context.Context, input Input) error
+}
+
+type engine struct {
+	logger logger.Logger
+	dryRun bool
+	now    func() time.Time
+	doc    atomic.Pointer[Document]
+}
+
+// NewEngine は policy_file のポリシーを読み込み、policy_reload_interval ごとに変更を反映する Engine を返します
+// policy_file が空の場合はすべて許可します。返す関数は変更の監視を停止し、終了を待ちます
+func NewEngine(cfg *config.AppConfig, logger logger.Logger) (Engine, func(), error) {
+	e := newEngine(logger.With("log_type", "audit"), cfg.PolicyDryRun)
+	if cfg.PolicyFile == "" {
+		e.doc.Store(&Document{DefaultEffect: EffectAllow})
+		return e, func() {}, nil
+	}
+	// time.NewTicker は 0 以下の間隔で panic するため、起動時に拒否する
+	if cfg.PolicyReloadInterval <= 0 {
+		return nil, nil, fmt.Errorf("policy_reload_interval must be positive: %s", cfg.PolicyReloadInterval)
+	}
+
+	// 読み込み中の変更を見逃さないよう、読み込む前の状態を基準にする
+	last, err := os.Stat(cfg.PolicyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	doc, err := LoadFile(cfg.PolicyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	e.doc.Store(doc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		e.watch(ctx, cfg.PolicyFile, last, cfg.PolicyReloadInterval)
+	}()
+	return e, func() {
+		cancel()
+		wg.Wait()
+	}, nil
+}
+
+func newEngine(logger logger.Logger, dryRun bool) *engine {
+	return &engine{
+		logger: logger,
+		dryRun: dryRun,
+		now:    time.Now,
+	}
+}
+
+// watch はファイルの更新時刻とサイズの変化を検知してポリシーを読み込み直し、ctx が終了すると戻ります
+// 読み込みに失敗した場合は直前のポリシーを使い続けます
+func (e *engine) watch(ctx context.Context, path string, last os.FileInfo, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			e.logger.Error("Failed to stat policy file", "path", path, "error", err)
+			continue
+		}
+		if info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+			continue
+		}
+		last = info
+		e.reload(path)
+	}
+}
+
+func (e *engine) reload(path string) {
+	doc, err := LoadFile(path)
+	if err != nil {
+		e.logger.Error("Failed to reload policy file, keeping previous policies", "path", path, "error", err)
+		return
+	}
+	e.doc.Store(doc)
+	e.logger.Info("Policy file reloaded", "path", path, "policies", len(doc.Policies))
+}
+
+func (e *engine) Evaluate(ctx context.Context, input Input) Decision {
+	if input.Environment == nil {
+		input.Environment = EnvironmentAttributes(e.now())
+	}
+
+	doc := e.doc.Load()
+	decision := Decision{Effect: doc.DefaultEffect, DryRun: e.dryRun}
+	for i := range doc.Policies {
+		p := &doc.Policies[i]
+		if !p.matchAction(input.Action) || !p.matches(input) {
+			continue
+		}
+		if p.Effect == EffectDeny {
+			decision.Effect, decision.PolicyID = EffectDeny, p.ID
+			break
+		}
+		if decision.PolicyID == "" {
+			decision.Effect, decision.PolicyID = EffectAllow, p.ID
+		}
+	}
+	decision.Allowed = decision.Effect == EffectAllow || e.dryRun
+
+	subjectID, _ := input.Subject["id"].(string)
+	resourceID, _ := input.Resource["id"].(string)
+	args := []any{"action", input.Action, "subject", subjectID, "resource", resourceID, "effect", decision.Effect, "policy_id", decision.PolicyID}
+	switch {
+	case decision.Effect == EffectAllow:
+		e.logger.DebugContext(ctx, "Policy allowed", args...)
+	case e.dryRun:
+		e.logger.WarnContext(ctx, "Policy would deny (dry run)", args...)
+	default:
+		e.logger.WarnContext(ctx, "Policy denied", args...)
+	}
+	return decision
+}
+
+func (e *engine) Enforce(ctx context.Context, input Input) error {
+	if !e.Evaluate(ctx, input).Allowed {
+		return apperrors.NewForbiddenError("Access denied by policy", nil)
+	}
+	return nil
+}
+
+func (p *Policy) matches(input Input) bool {
+	for _, c := range p.Conditions {
+		if !c.evaluate(input) {
+			return false
+		}
+	}
+	return true
+}
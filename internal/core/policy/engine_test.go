@@ -0,0 +1,167 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicies = `
+default_effect: deny
+policies:
+  - id: editors-update-own-team-samples
+    effect: allow
+    actions: [samples:update]
+    conditions:
+      - attribute: resource.team
+        operator: in
+        value_from: subject.teams.editor
+  - id: admins-do-anything
+    effect: allow
+    actions: ["*"]
+    conditions:
+      - attribute: subject.permissions
+        operator: contains
+        value: admin
+  - id: no-changes-at-night
+    effect: deny
+    actions: ["samples:*"]
+    conditions:
+      - attribute: environment.hour
+        operator: lte
+        value: 5
+`
+
+func writePolicyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	doc, err := Parse([]byte(testPolicies))
+	require.NoError(t, err)
+	target := newEngine(logger.NewLogger(&config.AppConfig{}), false)
+	target.doc.Store(doc)
+
+	editor := SubjectAttributes(&models.User{ID: "editor", Roles: []string{"role:teamA:editor", "role:teamB:viewer"}})
+	admin := SubjectAttributes(&models.User{ID: "admin", Roles: []string{"role:system:admin"}})
+	daytime := EnvironmentAttributes(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC))
+	night := EnvironmentAttributes(time.Date(2024, 6, 3, 3, 0, 0, 0, time.UTC))
+	resource := func(team string) map[string]any {
+		return SampleAttributes(&models.Sample{ID: "123", Team: team})
+	}
+
+	t.Run("正常系: 条件を満たす allow ポリシーで許可する", func(t *testing.T) {
+		decision := target.Evaluate(ctx, Input{Subject: editor, Action: "samples:update", Resource: resource("teamA"), Environment: daytime})
+
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "editors-update-own-team-samples", decision.PolicyID)
+	})
+
+	t.Run("異常系: どのポリシーにも一致しない場合は default_effect に従う", func(t *testing.T) {
+		decision := target.Evaluate(ctx, Input{Subject: editor, Action: "samples:update", Resource: resource("teamB"), Environment: daytime})
+
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, EffectDeny, decision.Effect)
+		assert.Empty(t, decision.PolicyID)
+	})
+
+	t.Run("異常系: deny は allow より優先する", func(t *testing.T) {
+		decision := target.Evaluate(ctx, Input{Subject: admin, Action: "samples:update", Resource: resource("teamA"), Environment: night})
+
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, "no-changes-at-night", decision.PolicyID)
+	})
+
+	t.Run("異常系: Enforce は拒否を Forbidden にする", func(t *testing.T) {
+		err := target.Enforce(ctx, Input{Subject: editor, Action: "samples:delete", Resource: resource("teamA"), Environment: daytime})
+
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, apperrors.ErrorTypeForbidden, appErr.Type)
+	})
+
+	t.Run("正常系: dry-run では拒否する判定を記録して許可する", func(t *testing.T) {
+		dryRun := newEngine(logger.NewLogger(&config.AppConfig{}), true)
+		dryRun.doc.Store(doc)
+
+		decision := dryRun.Evaluate(ctx, Input{Subject: editor, Action: "samples:delete", Resource: resource("teamA"), Environment: daytime})
+
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, EffectDeny, decision.Effect)
+		assert.True(t, decision.DryRun)
+	})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"異常系: 不明な effect", "policies:\n  - {id: p, effect: maybe, actions: [a]}"},
+		{"異常系: actions がない", "policies:\n  - {id: p, effect: allow}"},
+		{"異常系: id の重複", "policies:\n  - {id: p, effect: allow, actions: [a]}\n  - {id: p, effect: deny, actions: [a]}"},
+		{"異常系: 不明な演算子", "policies:\n  - id: p\n    effect: allow\n    actions: [a]\n    conditions: [{attribute: subject.id, operator: like, value: x}]"},
+		{"異常系: 不明な属性のルート", "policies:\n  - id: p\n    effect: allow\n    actions: [a]\n    conditions: [{attribute: user.id, operator: equals, value: x}]"},
+		{"異常系: 不明なキー", "policies:\n  - {id: p, effect: allow, actions: [a], when: x}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	ctx := context.Background()
+	path := writePolicyFile(t, "default_effect: deny\n")
+	target, stop, err := NewEngine(&config.AppConfig{PolicyFile: path, PolicyReloadInterval: 10 * time.Millisecond}, logger.NewLogger(&config.AppConfig{}))
+	require.NoError(t, err)
+	defer stop()
+	input := Input{Action: "samples:read"}
+	require.False(t, target.Evaluate(ctx, input).Allowed)
+
+	t.Run("正常系: ファイルの変更を反映する", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("default_effect: allow\npolicies: []\n"), 0o600))
+
+		assert.Eventually(t, func() bool { return target.Evaluate(ctx, input).Allowed }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("異常系: 不正なファイルに変更された場合は直前のポリシーを使い続ける", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("default_effect: [broken"), 0o600))
+		time.Sleep(50 * time.Millisecond)
+
+		assert.True(t, target.Evaluate(ctx, input).Allowed)
+	})
+
+	t.Run("異常系: policy_reload_interval が 0 以下の場合はエラー", func(t *testing.T) {
+		_, _, err := NewEngine(&config.AppConfig{PolicyFile: path, PolicyReloadInterval: -time.Second}, logger.NewLogger(&config.AppConfig{}))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("正常系: 停止した後は変更を反映しない", func(t *testing.T) {
+		path := writePolicyFile(t, "default_effect: deny\n")
+		target, stop, err := NewEngine(&config.AppConfig{PolicyFile: path, PolicyReloadInterval: 10 * time.Millisecond}, logger.NewLogger(&config.AppConfig{}))
+		require.NoError(t, err)
+
+		stop()
+		require.NoError(t, os.WriteFile(path, []byte("default_effect: allow\npolicies: []\n"), 0o600))
+		time.Sleep(50 * time.Millisecond)
+
+		assert.False(t, target.Evaluate(ctx, input).Allowed)
+	})
+}
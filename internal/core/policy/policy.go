@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Effect はポリシーが一致した場合の判定です
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Document は policy_file の内容です
+//
+//	default_effect: deny
+//	policies:
+//	  - id: editors-update-own-team-samples
+//	    effect: allow
+//	    actions: [samples:update, samples:delete]
+//	    conditions:
+//	      - attribute: resource.team
+//	        operator: in
+//	        value_from: subject.teams.editor
+type Document struct {
+	// DefaultEffect はどのポリシーにも一致しなかった場合の判定です。デフォルトは deny です
+	DefaultEffect Effect   `yaml:"default_effect"`
+	Policies      []Policy `yaml:"policies"`
+}
+
+// Policy は 1 つの規則です。actions のいずれかに一致し、conditions をすべて満たす場合に effect を適用します
+type Policy struct {
+	ID          string      `yaml:"id"`
+	Description string      `yaml:"description"`
+	Effect      Effect      `yaml:"effect"`
+	Actions     []string    `yaml:"actions"`
+	Conditions  []Condition `yaml:"conditions"`
+}
+
+// Condition は属性に対する条件です
+// 比較する値は value で直接指定するか、value_from で別の属性のパスを指定します
+type Condition struct {
+	Attribute string   `yaml:"attribute"`
+	Operator  Operator `yaml:"operator"`
+	Value     any      `yaml:"value"`
+	ValueFrom string   `yaml:"value_from"`
+}
+
+// LoadFile はポリシーファイルを読み込み、検証します
+func LoadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse は YAML のポリシーを読み込み、検証します
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode policy file: %w", err)
+	}
+	if err := doc.validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (d *Document) validate() error {
+	switch d.DefaultEffect {
+	case "":
+		d.DefaultEffect = EffectDeny
+	case EffectAllow, EffectDeny:
+	default:
+		return fmt.Errorf("policy file: invalid default_effect %q", d.DefaultEffect)
+	}
+
+	seen := make(map[string]bool, len(d.Policies))
+	for _, p := range d.Policies {
+		if p.ID == "" {
+			return fmt.Errorf("policy file: id is required")
+		}
+		if seen[p.ID] {
+			return fmt.Errorf("policy %s: duplicate id", p.ID)
+		}
+		seen[p.ID] = true
+		if p.Effect != EffectAllow && p.Effect != EffectDeny {
+			return fmt.Errorf("policy %s: invalid effect %q", p.ID, p.Effect)
+		}
+		if len(p.Actions) == 0 {
+			return fmt.Errorf("policy %s: actions are required", p.ID)
+		}
+		for _, c := range p.Conditions {
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("policy %s: %w", p.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchAction は action がポリシーの actions（samples:* や * を含む）に一致するかを返します
+func (p *Policy) matchAction(action string) bool {
+	for _, a := range p.Actions {
+		if a == "*" || a == action {
+			return true
+		}
+		if resource, ok := strings.CutSuffix(a, ":*"); ok && strings.HasPrefix(action, resource+":") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+package policy
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(
+	NewEngine,
+)
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// AccountTokenService はメール確認・パスワードリセット用のワンタイムトークンの規則を扱います
+//   - トークンは 256 bit の乱数で、保存するのは SHA-256 ハッシュのみ
+//   - 用途ごとに有効期限が異なる
+//   - 一度使ったトークンは使えない（ストアからの取り出しで消費する）
+type AccountTokenService interface {
+	// Issue は新しいトークンを生成し、メールで送る文字列と保存するレコードを返します
+	Issue(userID string, purpose models.AccountTokenPurpose) (string, *models.AccountToken, error)
+	// Hash はトークン文字列から保存用のハッシュを計算します
+	Hash(token string) string
+	// Validate は取り出したレコードが用途と有効期限を満たすか検証します
+	Validate(record *models.AccountToken, purpose models.AccountTokenPurpose) error
+}
+
+type accountTokenService struct {
+	ttls map[models.AccountTokenPurpose]time.Duration
+	now  func() time.Time
+}
+
+func NewAccountTokenService(cfg *config.AppConfig) AccountTokenService {
+	return &accountTokenService{
+		ttls: map[models.AccountTokenPurpose]time.Duration{
+			models.AccountTokenPurposeEmailVerification: cfg.AuthEmailVerificationTTL,
+			models.AccountTokenPurposePasswordReset:     cfg.AuthPasswordResetTTL,
+		},
+		now: time.Now,
+	}
+}
+
+func (s *accountTokenService) Issue(userID string, purpose models.AccountTokenPurpose) (string, *models.AccountToken, error) {
+	ttl, ok := s.ttls[purpose]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown account token purpose: %q", purpose)
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := s.now()
+	return token, &models.AccountToken{
+		TokenHash: s.Hash(token),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+func (s *accountTokenService) Hash(token string) string {
+	return hashOpaqueToken(token)
+}
+
+// Validate は失敗理由を区別しないエラーを返します
+func (s *accountTokenService) Validate(record *models.AccountToken, purpose models.AccountTokenPurpose) error {
+	if record == nil || record.Purpose != purpose || !s.now().Before(record.ExpiresAt) {
+		return invalidAccountTokenError()
+	}
+	return nil
+}
+
+func invalidAccountTokenError() error {
+	return apperrors.NewBadRequestError("Invalid or expired token", nil)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountTokenService(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewAccountTokenService(&config.AppConfig{
+		AuthEmailVerificationTTL: 24 * time.Hour,
+		AuthPasswordResetTTL:     30 * time.Minute,
+	}).(*accountTokenService)
+	s.now = func() time.Time { return now }
+
+	t.Run("正常系: 用途ごとの有効期限でトークンを発行し、ハッシュのみを記録する", func(t *testing.T) {
+		token, record, err := s.Issue("user123", models.AccountTokenPurposePasswordReset)
+
+		require.NoError(t, err)
+		assert.Len(t, token, 43)
+		assert.NotEqual(t, token, record.TokenHash)
+		assert.Equal(t, s.Hash(token), record.TokenHash)
+		assert.Equal(t, "user123", record.UserID)
+		assert.Equal(t, now.Add(30*time.Minute), record.ExpiresAt)
+		assert.NoError(t, s.Validate(record, models.AccountTokenPurposePasswordReset))
+	})
+
+	t.Run("正常系: 発行するたびに異なるトークンになる", func(t *testing.T) {
+		t1, _, err := s.Issue("user123", models.AccountTokenPurposeEmailVerification)
+		require.NoError(t, err)
+		t2, _, err := s.Issue("user123", models.AccountTokenPurposeEmailVerification)
+		require.NoError(t, err)
+		assert.NotEqual(t, t1, t2)
+	})
+
+	t.Run("異常系: 期限切れ・用途違い・未登録のトークンは同じエラーになる", func(t *testing.T) {
+		_, record, err := s.Issue("user123", models.AccountTokenPurposeEmailVerification)
+		require.NoError(t, err)
+
+		expired := *record
+		expired.ExpiresAt = now
+
+		for _, err := range []error{
+			s.Validate(&expired, models.AccountTokenPurposeEmailVerification),
+			s.Validate(record, models.AccountTokenPurposePasswordReset),
+			s.Validate(nil, models.AccountTokenPurposeEmailVerification),
+		} {
+			appErr, ok := err.(*apperrors.AppError)
+			require.True(t, ok)
+			assert.Equal(t, apperrors.ErrorTypeBadRequest, appErr.Type)
+			assert.Equal(t, "Invalid or expired token", appErr.Message)
+		}
+	})
+}
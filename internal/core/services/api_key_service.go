@@ -0,0 +1,91 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+)
+
+const (
+	// apiKeyPrefix は API キーであることを示す接頭辞です。シークレットスキャナーでの検出にも使えます
+	apiKeyPrefix = "gk_"
+	// apiKeyIDBytes は API キーの ID（表示用の接頭辞）の乱数のバイト数です
+	apiKeyIDBytes = 6
+)
+
+// APIKeyService は API キーの規則を扱います
+//   - キーは gk_<12 桁の 16 進数の ID>_<256 bit の乱数> の形式
+//   - 保存するのは ID とキー全体の SHA-256 のみで、キーは発行時に一度だけ返す
+type APIKeyService interface {
+	// Issue は新しいキーを生成します
+	Issue(userID, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error)
+	// ParseID はキーから ID を取り出します。形式が異なる場合は false を返します
+	ParseID(key string) (string, bool)
+	// Verify はキーがハッシュに一致し、失効しておらず有効期限内か検証します
+	Verify(record *models.APIKey, key string) error
+}
+
+type apiKeyService struct {
+	now func() time.Time
+}
+
+func NewAPIKeyService() APIKeyService {
+	return &apiKeyService{
+		now: time.Now,
+	}
+}
+
+func (s *apiKeyService) Issue(userID, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error) {
+	b := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key id: %w", err)
+	}
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	id := apiKeyPrefix + hex.EncodeToString(b)
+	key := id + "_" + secret
+	return key, &models.APIKey{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		KeyHash:   hashOpaqueToken(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: s.now(),
+	}, nil
+}
+
+func (s *apiKeyService) ParseID(key string) (string, bool) {
+	idLen := len(apiKeyPrefix) + hex.EncodedLen(apiKeyIDBytes)
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) <= idLen+1 || key[idLen] != '_' {
+		return "", false
+	}
+	return key[:idLen], true
+}
+
+func (s *apiKeyService) Verify(record *models.APIKey, key string) error {
+	if subtle.ConstantTimeCompare([]byte(record.KeyHash), []byte(hashOpaqueToken(key))) != 1 {
+		return InvalidAPIKeyError()
+	}
+	if record.RevokedAt != nil {
+		return InvalidAPIKeyError()
+	}
+	if record.ExpiresAt != nil && !s.now().Before(*record.ExpiresAt) {
+		return InvalidAPIKeyError()
+	}
+	return nil
+}
+
+// InvalidAPIKeyError はキーが存在しない・失効・期限切れのいずれでも返す 401 です
+func InvalidAPIKeyError() error {
+	return apperrors.NewUnauthorizedError("Invalid API key", nil)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// Authorizer はユーザーのロールから操作の許可を判定します
+// ロール role:<team>:<permission> の <permission> を auth_role_permissions で操作（samples:write など）に展開します
+// 操作は完全一致に加えて samples:* や * のワイルドカードで許可できます
+// ユーザーに Scopes がある場合（API キーなど）は、ロールの権限とスコープの両方で許可された操作のみを許可します
+type Authorizer interface {
+	// Authorize はいずれかのチームで permission を持つ場合に nil を、持たない場合は 403 を返します
+	Authorize(user *models.User, permission string) error
+	// Teams は permission を持つチームを返します
+	Teams(user *models.User, permission string) []string
+	// HasScope はユーザーのスコープが scope を含むかを返します。スコープで制限されていないユーザーは常に true です
+	HasScope(user *models.User, scope string) bool
+}
+
+type authorizer struct {
+	rolePermissions map[string][]string
+}
+
+func NewAuthorizer(cfg *config.AppConfig) Authorizer {
+	return &authorizer{
+		rolePermissions: cfg.AuthRolePermissions,
+	}
+}
+
+func (a *authorizer) Authorize(user *models.User, permission string) error {
+	if len(a.Teams(user, permission)) == 0 {
+		return apperrors.NewForbiddenError(fmt.Sprintf("Permission %s required", permission), nil)
+	}
+	return nil
+}
+
+func (a *authorizer) Teams(user *models.User, permission string) []string {
+	if user == nil {
+		return nil
+	}
+	if !a.HasScope(user, permission) {
+		return nil
+	}
+	var teams []string
+	for _, s := range user.Roles {
+		// 形式の誤ったロールは何も許可しない
+		role, err := models.ParseRole(s)
+		if err != nil {
+			continue
+		}
+		if slices.Contains(teams, role.Team) {
+			continue
+		}
+		if slices.ContainsFunc(a.rolePermissions[role.Permission], func(granted string) bool {
+			return matchPermission(granted, permission)
+		}) {
+			teams = append(teams, role.Team)
+		}
+	}
+	return teams
+}
+
+func (a *authorizer) HasScope(user *models.User, scope string) bool {
+	if user == nil {
+		return false
+	}
+	if user.Scopes == nil {
+		return true
+	}
+	return slices.ContainsFunc(user.Scopes, func(granted string) bool {
+		return matchPermission(granted, scope)
+	})
+}
+
+// matchPermission は付与された操作 granted が要求された操作 required を含むかを返します
+func matchPermission(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	resource, ok := strings.CutSuffix(granted, ":*")
+	return ok && strings.HasPrefix(required, resource+":")
+}
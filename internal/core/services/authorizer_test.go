@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizer(t *testing.T) {
+	target := NewAuthorizer(&config.AppConfig{
+		AuthRolePermissions: map[string][]string{
+			"viewer": {"samples:read"},
+			"editor": {"samples:*"},
+			"admin":  {"*"},
+		},
+	})
+
+	t.Run("正常系: ロールの権限に対応する操作を許可する", func(t *testing.T) {
+		user := &models.User{ID: "user123", Roles: []string{"role:teamA:editor", "role:teamB:viewer"}}
+
+		assert.NoError(t, target.Authorize(user, "samples:write"))
+		assert.Equal(t, []string{"teamA", "teamB"}, target.Teams(user, "samples:read"))
+		assert.Equal(t, []string{"teamA"}, target.Teams(user, "samples:write"))
+	})
+
+	t.Run("正常系: * はすべての操作を許可する", func(t *testing.T) {
+		user := &models.User{ID: "admin", Roles: []string{"role:system:admin"}}
+
+		assert.NoError(t, target.Authorize(user, "users:revoke-tokens"))
+	})
+
+	t.Run("異常系: 権限のない操作は Forbidden", func(t *testing.T) {
+		user := &models.User{ID: "user123", Roles: []string{"role:teamA:viewer"}}
+
+		err := target.Authorize(user, "samples:write")
+
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, apperrors.ErrorTypeForbidden, appErr.Type)
+	})
+
+	t.Run("異常系: 形式の誤ったロールや未知の権限は何も許可しない", func(t *testing.T) {
+		user := &models.User{ID: "user123", Roles: []string{"admin", "role:teamA", "role:teamA:owner"}}
+
+		assert.Error(t, target.Authorize(user, "samples:read"))
+		assert.Error(t, target.Authorize(nil, "samples:read"))
+	})
+
+	t.Run("異常系: リソースのワイルドカードは他のリソースに及ばない", func(t *testing.T) {
+		user := &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}}
+
+		assert.Error(t, target.Authorize(user, "samplesx:read"))
+		assert.Error(t, target.Authorize(user, "users:read"))
+	})
+	t.Run("正常系: スコープを持つユーザーはロールとスコープの両方で許可された操作のみ行える", func(t *testing.T) {
+		user := &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}, Scopes: []string{"samples:read"}}
+
+		assert.NoError(t, target.Authorize(user, "samples:read"))
+		assert.Error(t, target.Authorize(user, "samples:write"))
+		assert.Error(t, target.Authorize(&models.User{ID: "user123", Roles: []string{"role:teamA:editor"}, Scopes: []string{}}, "samples:read"))
+	})
+
+	t.Run("正常系: スコープで制限されていないユーザーはすべてのスコープを持つ", func(t *testing.T) {
+		assert.True(t, target.HasScope(&models.User{ID: "user123"}, "api-keys:manage"))
+		assert.True(t, target.HasScope(&models.User{ID: "client:batch", Scopes: []string{"api-keys:*"}}, "api-keys:manage"))
+		assert.False(t, target.HasScope(&models.User{ID: "client:batch", Scopes: []string{"samples:read"}}, "api-keys:manage"))
+		assert.False(t, target.HasScope(nil, "api-keys:manage"))
+	})
+}
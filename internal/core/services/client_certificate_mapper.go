@@ -0,0 +1,96 @@
+package services
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// ErrUnmappedCertificate はクライアント証明書に対応するプリンシパルがないことを表します
+var ErrUnmappedCertificate = errors.New("client certificate is not mapped to a principal")
+
+// CertificatePrincipalPrefix はクライアント証明書のプリンシパルのユーザー ID の接頭辞です
+const CertificatePrincipalPrefix = "cert:"
+
+// ClientCertificateMapper は検証済みのクライアント証明書をプリンシパル（ユーザー）に対応付けます
+// 対応表は tls_client_principals_file で定義し、対応のない証明書はチェーンを検証できても受け付けません
+type ClientCertificateMapper interface {
+	Map(cert *x509.Certificate) (*models.User, error)
+}
+
+// certificatePrincipal は tls_client_principals_file に記述する対応です
+// uri / dns / email は SAN、subject はサブジェクトの識別名（CN=batch,O=Example など）と比較し、指定したもののいずれかに一致すれば対応します
+//
+//	[{"id": "batch", "uri": "spiffe://example.com/batch", "roles": ["role:teamA:viewer"], "scopes": ["samples:read"]}]
+type certificatePrincipal struct {
+	ID      string   `json:"id"`
+	URI     string   `json:"uri"`
+	DNS     string   `json:"dns"`
+	Email   string   `json:"email"`
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+	// Scopes を指定した場合はロールの権限をさらに絞り込みます
+	Scopes []string `json:"scopes"`
+}
+
+type clientCertificateMapper struct {
+	principals []certificatePrincipal
+}
+
+func NewClientCertificateMapper(cfg *config.AppConfig) (ClientCertificateMapper, error) {
+	m := &clientCertificateMapper{}
+	if cfg.TLSClientPrincipalsFile == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(cfg.TLSClientPrincipalsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tls client principals file: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.principals); err != nil {
+		return nil, fmt.Errorf("failed to decode tls client principals file: %w", err)
+	}
+	for i, p := range m.principals {
+		if p.ID == "" {
+			return nil, fmt.Errorf("tls client principal #%d: id is required", i)
+		}
+		if p.URI == "" && p.DNS == "" && p.Email == "" && p.Subject == "" {
+			return nil, fmt.Errorf("tls client principal %s: one of uri, dns, email or subject is required", p.ID)
+		}
+	}
+	return m, nil
+}
+
+func (m *clientCertificateMapper) Map(cert *x509.Certificate) (*models.User, error) {
+	if cert == nil {
+		return nil, ErrUnmappedCertificate
+	}
+	uris := make([]string, len(cert.URIs))
+	for i, u := range cert.URIs {
+		uris[i] = u.String()
+	}
+
+	for _, p := range m.principals {
+		if (p.URI != "" && slices.Contains(uris, p.URI)) ||
+			(p.DNS != "" && slices.Contains(cert.DNSNames, p.DNS)) ||
+			(p.Email != "" && slices.Contains(cert.EmailAddresses, p.Email)) ||
+			(p.Subject != "" && p.Subject == cert.Subject.String()) {
+			user := &models.User{
+				ID:    CertificatePrincipalPrefix + p.ID,
+				Name:  p.ID,
+				Roles: p.Roles,
+			}
+			if p.Scopes != nil {
+				user.Scopes = append([]string{}, p.Scopes...)
+			}
+			return user, nil
+		}
+	}
+	return nil, ErrUnmappedCertificate
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCertificateMapper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "principals.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"id": "batch", "uri": "spiffe://example.com/batch", "roles": ["role:teamA:viewer"], "scopes": ["samples:read"]},
+		{"id": "legacy", "subject": "CN=legacy,O=Example", "roles": ["role:teamA:editor"]}
+	]`), 0o600))
+	target, err := NewClientCertificateMapper(&config.AppConfig{TLSClientPrincipalsFile: path})
+	require.NoError(t, err)
+
+	t.Run("正常系: SAN の URI でプリンシパルに対応付ける", func(t *testing.T) {
+		spiffe, err := url.Parse("spiffe://example.com/batch")
+		require.NoError(t, err)
+
+		user, err := target.Map(&x509.Certificate{Subject: pkix.Name{CommonName: "ignored"}, URIs: []*url.URL{spiffe}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "cert:batch", user.ID)
+		assert.Equal(t, []string{"role:teamA:viewer"}, user.Roles)
+		assert.Equal(t, []string{"samples:read"}, user.Scopes)
+	})
+
+	t.Run("正常系: サブジェクトの識別名で対応付け、scopes のない対応はロールの権限をすべて持つ", func(t *testing.T) {
+		user, err := target.Map(&x509.Certificate{Subject: pkix.Name{CommonName: "legacy", Organization: []string{"Example"}}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "cert:legacy", user.ID)
+		assert.Nil(t, user.Scopes)
+	})
+
+	t.Run("異常系: 対応のない証明書は ErrUnmappedCertificate", func(t *testing.T) {
+		_, err := target.Map(&x509.Certificate{Subject: pkix.Name{CommonName: "batch"}, DNSNames: []string{"batch.example.com"}})
+
+		assert.ErrorIs(t, err, ErrUnmappedCertificate)
+	})
+
+	t.Run("異常系: 照合する項目のない対応は読み込めない", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "principals.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"id": "batch", "roles": ["role:teamA:viewer"]}]`), 0o600))
+
+		_, err := NewClientCertificateMapper(&config.AppConfig{TLSClientPrincipalsFile: path})
+
+		assert.Error(t, err)
+	})
+}
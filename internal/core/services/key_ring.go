@@ -0,0 +1,230 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey は JWT の署名・検証に使う鍵です
+type SigningKey struct {
+	KID    string
+	Method jwt.SigningMethod
+	// Private は署名に使う鍵で、Public は検証に使う鍵です（HS256 では同じ共有鍵）
+	Private any
+	Public  any
+	// ActiveFrom 以降はこの鍵で署名します
+	ActiveFrom time.Time
+}
+
+// KeyRing は署名鍵のローテーションを扱います
+//   - 署名には ActiveFrom を過ぎた鍵のうち最新のものを使う
+//   - 次の鍵に切り替わった後も overlap の間は古い鍵で検証できる
+//   - 鍵は ActiveFrom の overlap 前から公開し、検証側が事前に取得できるようにする
+type KeyRing interface {
+	// SigningKey は現在の署名鍵を返します
+	SigningKey() (*SigningKey, error)
+	// VerificationKey は kid に一致する検証可能な鍵を返します
+	VerificationKey(kid string) (*SigningKey, bool)
+	// JWKS は公開中の公開鍵を返します。共有鍵の場合は空です
+	JWKS() models.JWKS
+}
+
+// keyManifestEntry は jwt_keys_file に記述する鍵の定義です
+type keyManifestEntry struct {
+	KID            string    `json:"kid"`
+	PrivateKeyFile string    `json:"private_key_file"`
+	ActiveFrom     time.Time `json:"active_from"`
+}
+
+// NewKeyRing は設定に応じた KeyRing を返します
+// jwt_keys_file を指定しない場合は JWTSecretKey による HS256 で署名します
+func NewKeyRing(cfg *config.AppConfig) (KeyRing, error) {
+	if cfg.JWTKeysFile == "" {
+		return &keyRing{
+			keys: []*SigningKey{{
+				Method:  jwt.SigningMethodHS256,
+				Private: []byte(cfg.JWTSecretKey),
+				Public:  []byte(cfg.JWTSecretKey),
+			}},
+			now: time.Now,
+		}, nil
+	}
+
+	data, err := os.ReadFile(cfg.JWTKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt keys file: %w", err)
+	}
+	var entries []keyManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode jwt keys file: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("jwt keys file has no keys")
+	}
+
+	seen := make(map[string]bool, len(entries))
+	keys := make([]*SigningKey, 0, len(entries))
+	for _, e := range entries {
+		if e.KID == "" || seen[e.KID] {
+			return nil, fmt.Errorf("jwt keys file: kid must be unique and non-empty: %q", e.KID)
+		}
+		seen[e.KID] = true
+
+		// 相対パスは鍵ファイルのディレクトリを基準にする
+		path := e.PrivateKeyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(cfg.JWTKeysFile), path)
+		}
+		key, err := loadSigningKey(e.KID, path)
+		if err != nil {
+			return nil, err
+		}
+		key.ActiveFrom = e.ActiveFrom
+		keys = append(keys, key)
+	}
+	return newKeyRing(keys, cfg.JWTKeyRotationOverlap, time.Now), nil
+}
+
+func newKeyRing(keys []*SigningKey, overlap time.Duration, now func() time.Time) *keyRing {
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].ActiveFrom.Before(keys[j].ActiveFrom) })
+	return &keyRing{keys: keys, overlap: overlap, now: now}
+}
+
+type keyRing struct {
+	// keys は ActiveFrom の昇順です
+	keys    []*SigningKey
+	overlap time.Duration
+	now     func() time.Time
+}
+
+func (r *keyRing) SigningKey() (*SigningKey, error) {
+	now := r.now()
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].ActiveFrom.After(now) {
+			return r.keys[i], nil
+		}
+	}
+	return nil, errors.New("no active signing key")
+}
+
+func (r *keyRing) VerificationKey(kid string) (*SigningKey, bool) {
+	now := r.now()
+	for i, key := range r.keys {
+		if key.KID == kid {
+			return key, r.published(i, now)
+		}
+	}
+	return nil, false
+}
+
+func (r *keyRing) JWKS() models.JWKS {
+	now := r.now()
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for i, key := range r.keys {
+		if !r.published(i, now) {
+			continue
+		}
+		if jwk, ok := toJWK(key); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+// published は i 番目の鍵が公開・検証の対象かを返します
+// ActiveFrom の overlap 前から、次の鍵の ActiveFrom の overlap 後までが対象です
+func (r *keyRing) published(i int, now time.Time) bool {
+	if r.keys[i].ActiveFrom.Add(-r.overlap).After(now) {
+		return false
+	}
+	if i+1 < len(r.keys) && !now.Before(r.keys[i+1].ActiveFrom.Add(r.overlap)) {
+		return false
+	}
+	return true
+}
+
+// loadSigningKey は PEM の秘密鍵を読み込みます。RSA は RS256、P-256 の EC は ES256 で署名します
+func loadSigningKey(kid, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key %q: %w", kid, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key %q is not PEM encoded", kid)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("private key %q has unsupported PEM type %q", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %q: %w", kid, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("private key %q: RSA keys must be at least 2048 bits", kid)
+		}
+		return &SigningKey{KID: kid, Method: jwt.SigningMethodRS256, Private: key, Public: &key.PublicKey}, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("private key %q: only P-256 EC keys are supported", kid)
+		}
+		return &SigningKey{KID: kid, Method: jwt.SigningMethodES256, Private: key, Public: &key.PublicKey}, nil
+	default:
+		return nil, fmt.Errorf("private key %q has unsupported type %T", kid, parsed)
+	}
+}
+
+func toJWK(key *SigningKey) (models.JWK, bool) {
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		return models.JWK{
+			Kty: "RSA",
+			Kid: key.KID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case *ecdsa.PublicKey:
+		// 座標は曲線のサイズに合わせて 0 埋めする（RFC 7518 6.2.1.2）
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return models.JWK{
+			Kty: "EC",
+			Kid: key.KID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			Crv: pub.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}, true
+	default:
+		return models.JWK{}, false
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeys はテスト用の RSA と EC の鍵を生成し、鍵ファイルのパスを返します
+func writeKeys(t *testing.T, rsaActiveFrom, ecActiveFrom time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "rsa.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "ec.pem"), "PRIVATE KEY", der)
+
+	manifest, err := json.Marshal([]keyManifestEntry{
+		{KID: "rsa-1", PrivateKeyFile: "rsa.pem", ActiveFrom: rsaActiveFrom},
+		{KID: "ec-2", PrivateKeyFile: "ec.pem", ActiveFrom: ecActiveFrom},
+	})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "keys.json")
+	require.NoError(t, os.WriteFile(path, manifest, 0o600))
+	return path
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
+
+func TestKeyRing(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	rotateAt := now.Add(2 * time.Hour)
+	cfg := &config.AppConfig{
+		JWTKeysFile:           writeKeys(t, now.Add(-24*time.Hour), rotateAt),
+		JWTKeyRotationOverlap: time.Hour,
+		AuthAccessTokenTTL:    15 * time.Minute,
+	}
+	loaded, err := NewKeyRing(cfg)
+	require.NoError(t, err)
+	ring := loaded.(*keyRing)
+	at := func(t time.Time) { ring.now = func() time.Time { return t } }
+
+	t.Run("正常系: 切り替え前は古い鍵で署名し、新しい鍵を overlap 前から公開する", func(t *testing.T) {
+		at(now)
+		key, err := ring.SigningKey()
+		require.NoError(t, err)
+		assert.Equal(t, "rsa-1", key.KID)
+		assert.Equal(t, "RS256", key.Method.Alg())
+		assert.Len(t, ring.JWKS().Keys, 1)
+
+		at(rotateAt.Add(-30 * time.Minute))
+		jwks := ring.JWKS()
+		require.Len(t, jwks.Keys, 2)
+		assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[0].E)
+		assert.Equal(t, "EC", jwks.Keys[1].Kty)
+		assert.Equal(t, "P-256", jwks.Keys[1].Crv)
+		assert.Len(t, jwks.Keys[1].X, 43)
+	})
+
+	t.Run("正常系: 切り替え後は overlap の間だけ古い鍵で検証できる", func(t *testing.T) {
+		at(rotateAt.Add(30 * time.Minute))
+		key, err := ring.SigningKey()
+		require.NoError(t, err)
+		assert.Equal(t, "ec-2", key.KID)
+		_, ok := ring.VerificationKey("rsa-1")
+		assert.True(t, ok)
+
+		at(rotateAt.Add(time.Hour))
+		_, ok = ring.VerificationKey("rsa-1")
+		assert.False(t, ok)
+		assert.Len(t, ring.JWKS().Keys, 1)
+	})
+
+	t.Run("正常系: kid で選んだ鍵で署名・検証できる", func(t *testing.T) {
+		at(rotateAt.Add(30 * time.Minute))
+		s := NewTokenService(cfg, ring)
+
+		tokenString, err := s.GenerateToken(context.Background(), "user123", []string{"role:teamA:editor"})
+		require.NoError(t, err)
+		parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, "ec-2", parsed.Header["kid"])
+		assert.Equal(t, "ES256", parsed.Method.Alg())
+
+		claims, err := s.ValidateToken(context.Background(), tokenString)
+		require.NoError(t, err)
+		assert.Equal(t, "user123", claims.UserID)
+	})
+
+	t.Run("異常系: 公開鍵を共有鍵として使う HS256 のトークンは拒否する", func(t *testing.T) {
+		at(now)
+		key, ok := ring.VerificationKey("rsa-1")
+		require.True(t, ok)
+		der, err := x509.MarshalPKIXPublicKey(key.Public)
+		require.NoError(t, err)
+
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "attacker", "exp": now.Add(time.Hour).Unix()})
+		forged.Header["kid"] = "rsa-1"
+		tokenString, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		require.NoError(t, err)
+
+		_, err = NewTokenService(cfg, ring).ValidateToken(context.Background(), tokenString)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系: 2048 bit 未満の RSA 鍵は読み込まない", func(t *testing.T) {
+		dir := t.TempDir()
+		weak, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+		writePEM(t, filepath.Join(dir, "weak.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(weak))
+
+		_, err = loadSigningKey("weak", filepath.Join(dir, "weak.pem"))
+
+		assert.ErrorContains(t, err, "at least 2048 bits")
+	})
+}
@@ -0,0 +1,39 @@
+package services
+
+import "net/http"
+
+// RFC 6749 5.2 のトークンエンドポイントのエラーコードです
+const (
+	OAuthErrorInvalidRequest       = "invalid_request"
+	OAuthErrorInvalidClient        = "invalid_client"
+	OAuthErrorInvalidGrant         = "invalid_grant"
+	OAuthErrorUnauthorizedClient   = "unauthorized_client"
+	OAuthErrorUnsupportedGrantType = "unsupported_grant_type"
+	OAuthErrorInvalidScope         = "invalid_scope"
+)
+
+// OAuthError はトークンエンドポイントが RFC 6749 の形式で返すエラーです
+// アプリケーションのエラー形式（AppError）ではなく、error と error_description を持つ JSON で返します
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return "oauth: " + e.Code
+	}
+	return "oauth: " + e.Code + ": " + e.Description
+}
+
+// StatusCode は HTTP ステータスコードを返します。クライアント認証の失敗のみ 401 で、それ以外は 400 です
+func (e *OAuthError) StatusCode() int {
+	if e.Code == OAuthErrorInvalidClient {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
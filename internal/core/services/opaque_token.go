@@ -0,0 +1,28 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// opaqueTokenBytes は不透明なトークンのエントロピー（バイト数）です
+const opaqueTokenBytes = 32
+
+// generateOpaqueToken は 256 bit の乱数を base64url で表したトークンを返します
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashOpaqueToken は保存用のハッシュを返します
+// トークンは十分なエントロピーを持つため、ソルトなしの SHA-256 で十分です
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrInvalidHash は保存されているハッシュの形式が不正なことを表します
+var ErrInvalidHash = errors.New("invalid password hash format")
+
+type PasswordHasher interface {
+	// Hash はパスワードを argon2id でハッシュ化し、PHC 形式の文字列を返します
+	Hash(password string) (string, error)
+	// Verify はパスワードがハッシュと一致するかを定数時間で比較します
+	Verify(password, encodedHash string) (bool, error)
+}
+
+// argon2Params は argon2id のパラメーターです
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+type passwordHasher struct {
+	params argon2Params
+}
+
+// NewPasswordHasher は RFC 9106 の推奨値に沿ったパラメーターの PasswordHasher を返します
+func NewPasswordHasher() PasswordHasher {
+	return &passwordHasher{
+		params: argon2Params{
+			memory:      64 * 1024,
+			iterations:  3,
+			parallelism: 2,
+			saltLength:  16,
+			keyLength:   32,
+		},
+	}
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.iterations, h.params.memory, h.params.parallelism, h.params.keyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.memory, h.params.iterations, h.params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify はハッシュに記録されたパラメーターで再計算するため、パラメーター変更前のハッシュも検証できます
+func (h *passwordHasher) Verify(password, encodedHash string) (bool, error) {
+	params, salt, key, err := decodeHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, params.keyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func decodeHash(encodedHash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.saltLength = uint32(len(salt))
+	params.keyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	hasher := &passwordHasher{params: argon2Params{memory: 1024, iterations: 1, parallelism: 1, saltLength: 16, keyLength: 32}}
+
+	t.Run("正常系: ハッシュ化したパスワードを検証できる", func(t *testing.T) {
+		hash, err := hasher.Hash("correct horse")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+		ok, err := hasher.Verify("correct horse", hash)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("正常系: 同じパスワードでもソルトによりハッシュが異なる", func(t *testing.T) {
+		h1, err := hasher.Hash("password")
+		require.NoError(t, err)
+		h2, err := hasher.Hash("password")
+		require.NoError(t, err)
+		assert.NotEqual(t, h1, h2)
+	})
+
+	t.Run("異常系: 異なるパスワードは一致しない", func(t *testing.T) {
+		hash, err := hasher.Hash("correct horse")
+		require.NoError(t, err)
+
+		ok, err := hasher.Verify("battery staple", hash)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("異常系: 不正な形式のハッシュはエラー", func(t *testing.T) {
+		_, err := hasher.Verify("password", "$2a$10$bcrypt-hash")
+		assert.ErrorIs(t, err, ErrInvalidHash)
+	})
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// RefreshTokenService はリフレッシュトークンの規則を扱います
+//   - トークンは 256 bit の乱数で、保存するのは SHA-256 ハッシュのみ
+//   - 使用するたびに同じファミリーの新しいトークンに置き換える（ローテーション）
+//   - 使用済みのトークンが再び使われた場合は漏洩とみなし、ファミリーごと失効させる
+type RefreshTokenService interface {
+	// Issue は新しいトークンを生成します。familyID が空の場合は新しいファミリーを作ります
+	Issue(userID, familyID string) (string, *models.RefreshToken, error)
+	// Hash はトークン文字列から保存用のハッシュを計算します
+	Hash(token string) string
+	// IsReused は使用済みのトークンが再び提示されたかを返します
+	IsReused(record *models.RefreshToken) bool
+	// Validate はトークンが失効しておらず有効期限内か検証します
+	Validate(record *models.RefreshToken) error
+}
+
+type refreshTokenService struct {
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewRefreshTokenService(cfg *config.AppConfig) RefreshTokenService {
+	return &refreshTokenService{
+		ttl: cfg.AuthRefreshTokenTTL,
+		now: time.Now,
+	}
+}
+
+func (s *refreshTokenService) Issue(userID, familyID string) (string, *models.RefreshToken, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	if familyID == "" {
+		if familyID, err = generateOpaqueToken(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	now := s.now()
+	return token, &models.RefreshToken{
+		TokenHash: s.Hash(token),
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+func (s *refreshTokenService) Hash(token string) string {
+	return hashOpaqueToken(token)
+}
+
+func (s *refreshTokenService) IsReused(record *models.RefreshToken) bool {
+	return record.UsedAt != nil
+}
+
+// Validate は失敗理由を区別しないエラーを返します
+func (s *refreshTokenService) Validate(record *models.RefreshToken) error {
+	if record == nil || record.RevokedAt != nil || !s.now().Before(record.ExpiresAt) {
+		return InvalidRefreshTokenError()
+	}
+	return nil
+}
+
+// InvalidRefreshTokenError はリフレッシュトークンが使えない場合のエラーです
+func InvalidRefreshTokenError() error {
+	return apperrors.NewUnauthorizedError("Invalid refresh token", nil)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// ErrSecretBoxOpen は暗号文の復号または改ざんの検知に失敗したことを表します
+var ErrSecretBoxOpen = errors.New("failed to open sealed secret")
+
+// SecretBox は保存する秘密情報（TOTP のシークレットなど）を AES-256-GCM で暗号化します
+// aad には持ち主のユーザー ID などを渡し、暗号文を別の行に付け替えても復号できないようにします
+type SecretBox interface {
+	Seal(plaintext []byte, aad string) (string, error)
+	Open(ciphertext string, aad string) ([]byte, error)
+}
+
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox は auth_mfa_encryption_key の鍵を使います
+// 鍵がない場合は dev 環境でのみプロセスごとのランダムな鍵を使い、それ以外の環境では起動を失敗させます
+func NewSecretBox(cfg *config.AppConfig) (SecretBox, error) {
+	var key []byte
+	switch {
+	case cfg.AuthMFAEncryptionKey != "":
+		decoded, err := base64.StdEncoding.DecodeString(cfg.AuthMFAEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode auth_mfa_encryption_key: %w", err)
+		}
+		if len(decoded) != 32 {
+			return nil, fmt.Errorf("auth_mfa_encryption_key must be 32 bytes, got %d", len(decoded))
+		}
+		key = decoded
+	case cfg.Env == "dev":
+		// 再起動すると登録済みの MFA のシークレットは復号できなくなる
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate mfa encryption key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("auth_mfa_encryption_key is required when env=%s", cfg.Env)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+	return &secretBox{aead: aead}, nil
+}
+
+// Seal はランダムなナンスを先頭に付けた暗号文を base64 で返します
+func (b *secretBox) Seal(plaintext []byte, aad string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, plaintext, []byte(aad))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *secretBox) Open(ciphertext string, aad string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return nil, ErrSecretBoxOpen
+	}
+	nonce, data := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, data, []byte(aad))
+	if err != nil {
+		return nil, ErrSecretBoxOpen
+	}
+	return plaintext, nil
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
@@ -12,46 +14,131 @@ import (
 
 type TokenService interface {
 	GenerateToken(ctx context.Context, userID string, roles []string) (string, error)
+	// GenerateClientToken は OAuth クライアントのトークンを発行します。ユーザー ID は client:<client_id> になります
+	GenerateClientToken(ctx context.Context, clientID string, roles, scopes []string) (string, error)
+	// ValidateToken はアクセストークンを検証します。用途の異なるトークンは受け付けません
 	ValidateToken(ctx context.Context, tokenString string) (*models.Claims, error)
+	// GenerateMFAChallenge はパスワードを確認したユーザーに、MFA のコードと引き換えるためのトークンを発行します
+	GenerateMFAChallenge(ctx context.Context, userID string) (string, error)
+	// ValidateMFAChallenge は GenerateMFAChallenge で発行したトークンを検証します
+	ValidateMFAChallenge(ctx context.Context, tokenString string) (*models.Claims, error)
 }
 
 type tokenService struct {
-	cfg *config.AppConfig
+	cfg     *config.AppConfig
+	keyRing KeyRing
+	parser  *jwt.Parser
 }
 
-func NewTokenService(cfg *config.AppConfig) TokenService {
+func NewTokenService(cfg *config.AppConfig, keyRing KeyRing) TokenService {
 	return &tokenService{
-		cfg: cfg,
+		cfg:     cfg,
+		keyRing: keyRing,
+		parser: jwt.NewParser(
+			jwt.WithIssuer(cfg.JWTIssuer),
+			jwt.WithAudience(cfg.JWTAudience),
+			jwt.WithLeeway(cfg.JWTLeeway),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
 	}
 }
 
 func (s *tokenService) GenerateToken(_ context.Context, userID string, roles []string) (string, error) {
-	claims := &models.Claims{
+	return s.sign(&models.Claims{
 		UserID: userID,
 		Roles:  roles,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+	}, s.cfg.AuthAccessTokenTTL)
+}
+
+func (s *tokenService) GenerateClientToken(_ context.Context, clientID string, roles, scopes []string) (string, error) {
+	return s.sign(&models.Claims{
+		UserID:   ClientPrincipalPrefix + clientID,
+		Roles:    roles,
+		Scope:    strings.Join(scopes, " "),
+		ClientID: clientID,
+	}, s.cfg.AuthAccessTokenTTL)
+}
+
+func (s *tokenService) GenerateMFAChallenge(_ context.Context, userID string) (string, error) {
+	return s.sign(&models.Claims{
+		UserID:  userID,
+		Purpose: models.TokenPurposeMFAChallenge,
+	}, s.cfg.AuthMFAChallengeTTL)
+}
+
+// ClientPrincipalPrefix は OAuth クライアントのユーザー ID の接頭辞です。ユーザー ID と衝突しないよう : を含めます
+const ClientPrincipalPrefix = "client:"
+
+// sign は ttl の有効期限と登録クレームを設定して署名します
+func (s *tokenService) sign(claims *models.Claims, ttl time.Duration) (string, error) {
+	jti, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.JWTSecretKey))
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		Issuer:    s.cfg.JWTIssuer,
+		Audience:  jwt.ClaimStrings{s.cfg.JWTAudience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	key, err := s.keyRing.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.KID != "" {
+		token.Header["kid"] = key.KID
+	}
+	return token.SignedString(key.Private)
 }
 
+// ValidateToken は失敗時に *TokenValidationError を返します
 func (s *tokenService) ValidateToken(_ context.Context, tokenString string) (*models.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(_ *jwt.Token) (any, error) {
-		return []byte(s.cfg.JWTSecretKey), nil
-	})
+	return s.validate(tokenString, "")
+}
 
+func (s *tokenService) ValidateMFAChallenge(_ context.Context, tokenString string) (*models.Claims, error) {
+	return s.validate(tokenString, models.TokenPurposeMFAChallenge)
+}
+
+// validate は署名と有効期限に加えて、トークンの用途が purpose に一致することを検証します
+func (s *tokenService) validate(tokenString, purpose string) (*models.Claims, error) {
+	token, err := s.parser.ParseWithClaims(tokenString, &models.Claims{}, s.keyFunc)
 	if err != nil {
-		return nil, err
+		return nil, NewTokenValidationError(err)
 	}
 
-	if claims, ok := token.Claims.(*models.Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*models.Claims)
+	if !ok || !token.Valid {
+		return nil, &TokenValidationError{Reason: TokenFailureInvalid}
+	}
+	if claims.Purpose != purpose {
+		return nil, &TokenValidationError{Reason: TokenFailureWrongPurpose}
+	}
+	return claims, nil
+}
+
+// keyFunc は署名の検証前にアルゴリズムを固定し、kid に一致する鍵を返します
+// アルゴリズムはヘッダーの値を信用せず、許可リストと鍵のアルゴリズムの両方に一致することを求めます
+func (s *tokenService) keyFunc(token *jwt.Token) (any, error) {
+	alg := token.Method.Alg()
+	if len(s.cfg.JWTAllowedAlgorithms) > 0 && !slices.Contains(s.cfg.JWTAllowedAlgorithms, alg) {
+		return nil, fmt.Errorf("%w: %s", errAlgorithmNotAllowed, alg)
 	}
 
-	return nil, errors.New("invalid token")
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keyRing.VerificationKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownKey, kid)
+	}
+	if alg != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: %s (key %q uses %s)", errAlgorithmNotAllowed, alg, kid, key.Method.Alg())
+	}
+	return key.Public, nil
 }
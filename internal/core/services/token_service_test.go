@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/mocks/mockservice"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateToken(t *testing.T) {
@@ -65,3 +68,127 @@ func TestGenerateToken(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenService_ValidateToken(t *testing.T) {
+	cfg := &config.AppConfig{
+		JWTSecretKey:        "secret",
+		JWTIssuer:           "issuer",
+		JWTAudience:         "audience",
+		JWTLeeway:           30 * time.Second,
+		AuthAccessTokenTTL:  15 * time.Minute,
+		AuthMFAChallengeTTL: 5 * time.Minute,
+	}
+	keyRing, err := NewKeyRing(cfg)
+	require.NoError(t, err)
+	s := NewTokenService(cfg, keyRing)
+
+	now := time.Now()
+	sign := func(claims jwt.MapClaims) string {
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		return tokenString
+	}
+	valid := func(overrides jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"user_id": "user123",
+			"iss":     "issuer",
+			"aud":     "audience",
+			"iat":     now.Unix(),
+			"exp":     now.Add(time.Minute).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = v
+			}
+		}
+		return claims
+	}
+
+	t.Run("正常系: 発行したトークンを検証できる", func(t *testing.T) {
+		tokenString, err := s.GenerateToken(context.Background(), "user123", []string{"role:teamA:editor"})
+		require.NoError(t, err)
+
+		claims, err := s.ValidateToken(context.Background(), tokenString)
+
+		require.NoError(t, err)
+		assert.Equal(t, "issuer", claims.Issuer)
+		assert.Equal(t, jwt.ClaimStrings{"audience"}, claims.Audience)
+	})
+
+	t.Run("正常系: クライアントのトークンにはクライアント ID とスコープを付ける", func(t *testing.T) {
+		tokenString, err := s.GenerateClientToken(context.Background(), "batch", []string{"role:teamA:viewer"}, []string{"samples:read", "api-keys:manage"})
+		require.NoError(t, err)
+
+		claims, err := s.ValidateToken(context.Background(), tokenString)
+
+		require.NoError(t, err)
+		assert.Equal(t, "client:batch", claims.UserID)
+		assert.Equal(t, "batch", claims.ClientID)
+		assert.Equal(t, "samples:read api-keys:manage", claims.Scope)
+		assert.Equal(t, []string{"role:teamA:viewer"}, claims.Roles)
+	})
+
+	t.Run("正常系: MFA のチャレンジはアクセストークンとして受け付けない", func(t *testing.T) {
+		challenge, err := s.GenerateMFAChallenge(context.Background(), "user123")
+		require.NoError(t, err)
+
+		claims, err := s.ValidateMFAChallenge(context.Background(), challenge)
+		require.NoError(t, err)
+		assert.Equal(t, "user123", claims.UserID)
+		assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+		_, err = s.ValidateToken(context.Background(), challenge)
+		assert.Equal(t, TokenFailureWrongPurpose, TokenFailureReason(err))
+
+		accessToken, err := s.GenerateToken(context.Background(), "user123", nil)
+		require.NoError(t, err)
+		_, err = s.ValidateMFAChallenge(context.Background(), accessToken)
+		assert.Equal(t, TokenFailureWrongPurpose, TokenFailureReason(err))
+	})
+
+	t.Run("正常系: leeway の範囲内の期限切れは受け付ける", func(t *testing.T) {
+		_, err := s.ValidateToken(context.Background(), sign(valid(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()})))
+
+		assert.NoError(t, err)
+	})
+
+	hsOther, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid(nil)).SignedString([]byte("other"))
+	require.NoError(t, err)
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid(nil)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		token  string
+		reason string
+	}{
+		{"異常系: leeway を超えた期限切れ", sign(valid(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})), TokenFailureExpired},
+		{"異常系: exp がない", sign(valid(jwt.MapClaims{"exp": nil})), TokenFailureMissingClaim},
+		{"異常系: 発行者が異なる", sign(valid(jwt.MapClaims{"iss": "someone-else"})), TokenFailureInvalidIssuer},
+		{"異常系: 発行者がない", sign(valid(jwt.MapClaims{"iss": nil})), TokenFailureMissingClaim},
+		{"異常系: 対象者が異なる", sign(valid(jwt.MapClaims{"aud": "other-api"})), TokenFailureInvalidAudience},
+		{"異常系: 未来の発行時刻", sign(valid(jwt.MapClaims{"iat": now.Add(time.Hour).Unix()})), TokenFailureNotYetValid},
+		{"異常系: 署名が一致しない", hsOther, TokenFailureInvalidSignature},
+		{"異常系: alg=none", none, TokenFailureAlgorithmNotAllowed},
+		{"異常系: JWT の形式でない", "not-a-jwt", TokenFailureMalformed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.ValidateToken(context.Background(), tc.token)
+
+			require.Error(t, err)
+			assert.Equal(t, tc.reason, TokenFailureReason(err))
+		})
+	}
+
+	t.Run("異常系: 許可リストにないアルゴリズムは鍵が一致しても拒否する", func(t *testing.T) {
+		pinned := *cfg
+		pinned.JWTAllowedAlgorithms = []string{"RS256"}
+
+		_, err := NewTokenService(&pinned, keyRing).ValidateToken(context.Background(), sign(valid(nil)))
+
+		assert.Equal(t, TokenFailureAlgorithmNotAllowed, TokenFailureReason(err))
+	})
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// トークン検証の失敗理由です。ログと span にのみ記録し、クライアントには返しません
+const (
+	TokenFailureMalformed           = "malformed"
+	TokenFailureUnknownKey          = "unknown_key"
+	TokenFailureAlgorithmNotAllowed = "algorithm_not_allowed"
+	TokenFailureInvalidSignature    = "invalid_signature"
+	TokenFailureExpired             = "expired"
+	TokenFailureNotYetValid         = "not_yet_valid"
+	TokenFailureInvalidIssuer       = "invalid_issuer"
+	TokenFailureInvalidAudience     = "invalid_audience"
+	TokenFailureMissingClaim        = "missing_claim"
+	TokenFailureRevoked             = "revoked"
+	TokenFailureWrongPurpose        = "wrong_purpose"
+	TokenFailureInvalid             = "invalid"
+)
+
+var (
+	errUnknownKey          = errors.New("unknown signing key")
+	errAlgorithmNotAllowed = errors.New("signing algorithm not allowed")
+)
+
+// TokenValidationError はトークン検証の失敗理由を表します
+type TokenValidationError struct {
+	Reason string
+	Err    error
+}
+
+func (e *TokenValidationError) Error() string {
+	if e.Err == nil {
+		return "token validation failed: " + e.Reason
+	}
+	return "token validation failed: " + e.Reason + ": " + e.Err.Error()
+}
+
+func (e *TokenValidationError) Unwrap() error {
+	return e.Err
+}
+
+// TokenFailureReason はエラーから検証の失敗理由を取り出します
+func TokenFailureReason(err error) string {
+	var validationErr *TokenValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Reason
+	}
+	return TokenFailureInvalid
+}
+
+// NewTokenValidationError は jwt の検証エラーを失敗理由に分類します。外部の IdP のトークンの検証でも使います
+// 複数に該当する場合は、利用者の対処に近い理由（期限切れなど）より鍵や署名の問題を優先します
+func NewTokenValidationError(err error) *TokenValidationError {
+	reason := TokenFailureInvalid
+	switch {
+	case errors.Is(err, errUnknownKey):
+		reason = TokenFailureUnknownKey
+	case errors.Is(err, errAlgorithmNotAllowed):
+		reason = TokenFailureAlgorithmNotAllowed
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		reason = TokenFailureMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		reason = TokenFailureInvalidSignature
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		reason = TokenFailureMissingClaim
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		reason = TokenFailureInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		reason = TokenFailureInvalidAudience
+	case errors.Is(err, jwt.ErrTokenExpired):
+		reason = TokenFailureExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		reason = TokenFailureNotYetValid
+	}
+	return &TokenValidationError{Reason: reason, Err: err}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+)
+
+// RFC 6238 のパラメーター。認証アプリの多くが対応する既定値（SHA-1, 6 桁, 30 秒）を使います
+const (
+	totpSecretBytes = 20
+	totpDigits      = 6
+	totpPeriod      = 30 * time.Second
+	// totpSkew は端末の時計のずれを許容する前後のステップ数です
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+	// recoveryCodeBytes はソルトのない SHA-256 で保存しても総当たりで戻せないよう 80 bit にします
+	recoveryCodeBytes = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPService は RFC 6238 の時間ベースのワンタイムパスワードと、認証アプリを失った場合のリカバリーコードを扱います
+type TOTPService interface {
+	// GenerateSecret は base32 で表したランダムなシークレットを返します
+	GenerateSecret() (string, error)
+	// URI は認証アプリに登録する otpauth URI を返します
+	URI(account, secret string) string
+	// Code は時刻 t のコードを返します
+	Code(secret string, t time.Time) (string, error)
+	// Validate はコードを検証し、一致したタイムステップを返します
+	// lastStep 以前のステップは一致しても受け付けないため、同じコードを二度使うことはできません
+	Validate(secret, code string, lastStep int64) (int64, bool)
+	// GenerateRecoveryCodes はリカバリーコードと、保存用のハッシュを返します
+	GenerateRecoveryCodes() (codes []string, hashes []string, err error)
+	// HashRecoveryCode は入力されたリカバリーコードを保存用のハッシュと比較できる形にします
+	HashRecoveryCode(code string) string
+}
+
+type totpService struct {
+	issuer string
+	now    func() time.Time
+}
+
+func NewTOTPService(cfg *config.AppConfig) TOTPService {
+	return &totpService{
+		issuer: cfg.AuthMFAIssuer,
+		now:    time.Now,
+	}
+}
+
+func (s *totpService) GenerateSecret() (string, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+func (s *totpService) URI(account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", s.issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + s.issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+func (s *totpService) Code(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, totpStep(t)), nil
+}
+
+func (s *totpService) Validate(secret, code string, lastStep int64) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := totpStep(s.now())
+	// 時計のずれを許容しつつ、使用済みのステップより後のものだけを比較します
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes は 80 bit のコードを xxxx-xxxx-xxxx-xxxx 形式（base32 の小文字）で返します
+func (s *totpService) GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(b))
+		code := raw[:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:]
+		codes = append(codes, code)
+		hashes = append(hashes, s.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode は大文字小文字と区切りの有無を無視して比較できるよう正規化してからハッシュ化します
+func (s *totpService) HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashOpaqueToken(normalized)
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return nil, fmt.Errorf("invalid totp secret: %w", err)
+	}
+	return key, nil
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod.Seconds())
+}
+
+// hotp は RFC 4226 の HOTP を計算します
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range totpDigits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
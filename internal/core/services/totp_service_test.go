@@ -0,0 +1,158 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPService(t *testing.T) {
+	// RFC 6238 付録 B の SHA-1 のシークレット "12345678901234567890"
+	const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	target := NewTOTPService(&config.AppConfig{AuthMFAIssuer: "Example App"}).(*totpService)
+
+	t.Run("正常系: RFC 6238 のテストベクターと一致する", func(t *testing.T) {
+		vectors := []struct {
+			unix int64
+			code string
+		}{
+			{59, "287082"},
+			{1111111109, "081804"},
+			{1111111111, "050471"},
+			{1234567890, "005924"},
+			{2000000000, "279037"},
+			{20000000000, "353130"},
+		}
+		for _, v := range vectors {
+			code, err := target.Code(rfcSecret, time.Unix(v.unix, 0))
+
+			require.NoError(t, err)
+			assert.Equal(t, v.code, code, "unix=%d", v.unix)
+		}
+	})
+
+	t.Run("正常系: 前後 1 ステップのずれを許容し、一致したステップを返す", func(t *testing.T) {
+		now := time.Unix(1111111111, 0)
+		target.now = func() time.Time { return now }
+		defer func() { target.now = time.Now }()
+
+		previous, err := target.Code(rfcSecret, now.Add(-30*time.Second))
+		require.NoError(t, err)
+		step, ok := target.Validate(rfcSecret, previous, 0)
+		assert.True(t, ok)
+		assert.Equal(t, totpStep(now)-1, step)
+
+		tooOld, err := target.Code(rfcSecret, now.Add(-90*time.Second))
+		require.NoError(t, err)
+		_, ok = target.Validate(rfcSecret, tooOld, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("異常系: 使用済みのステップのコードは受け付けない", func(t *testing.T) {
+		now := time.Unix(1111111111, 0)
+		target.now = func() time.Time { return now }
+		defer func() { target.now = time.Now }()
+
+		code, err := target.Code(rfcSecret, now)
+		require.NoError(t, err)
+		step, ok := target.Validate(rfcSecret, code, 0)
+		require.True(t, ok)
+
+		_, ok = target.Validate(rfcSecret, code, step)
+		assert.False(t, ok)
+	})
+
+	t.Run("異常系: 形式の誤ったコードやシークレットは受け付けない", func(t *testing.T) {
+		_, ok := target.Validate(rfcSecret, "12345", 0)
+		assert.False(t, ok)
+		_, ok = target.Validate("not base32!", "123456", 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("正常系: otpauth URI に発行者とアカウントを含める", func(t *testing.T) {
+		secret, err := target.GenerateSecret()
+		require.NoError(t, err)
+
+		u, err := url.Parse(target.URI("user123", secret))
+
+		require.NoError(t, err)
+		assert.Equal(t, "otpauth", u.Scheme)
+		assert.Equal(t, "totp", u.Host)
+		assert.Equal(t, "/Example App:user123", u.Path)
+		assert.Equal(t, secret, u.Query().Get("secret"))
+		assert.Equal(t, "Example App", u.Query().Get("issuer"))
+		assert.Equal(t, "6", u.Query().Get("digits"))
+	})
+
+	t.Run("正常系: リカバリーコードは入力の揺れを吸収してハッシュと比較できる", func(t *testing.T) {
+		codes, hashes, err := target.GenerateRecoveryCodes()
+
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+		assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, codes[0])
+		assert.Equal(t, hashes[0], target.HashRecoveryCode(codes[0]))
+		assert.Equal(t, hashes[0], target.HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+		assert.NotEqual(t, hashes[0], hashes[1])
+	})
+}
+
+func TestSecretBox(t *testing.T) {
+	box, err := NewSecretBox(&config.AppConfig{AuthMFAEncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	require.NoError(t, err)
+
+	t.Run("正常系: 暗号化した値を同じ AAD で復号できる", func(t *testing.T) {
+		sealed, err := box.Seal([]byte("secret"), "user123")
+		require.NoError(t, err)
+		assert.NotContains(t, sealed, "secret")
+
+		plaintext, err := box.Open(sealed, "user123")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), plaintext)
+	})
+
+	t.Run("異常系: AAD が異なる、または改ざんされた暗号文は復号できない", func(t *testing.T) {
+		sealed, err := box.Seal([]byte("secret"), "user123")
+		require.NoError(t, err)
+
+		_, err = box.Open(sealed, "user456")
+		assert.ErrorIs(t, err, ErrSecretBoxOpen)
+
+		tampered := []byte(sealed)
+		tampered[len(tampered)-3] ^= 1
+		_, err = box.Open(string(tampered), "user123")
+		assert.ErrorIs(t, err, ErrSecretBoxOpen)
+	})
+
+	t.Run("異常系: 32 バイトでない鍵は受け付けない", func(t *testing.T) {
+		_, err := NewSecretBox(&config.AppConfig{AuthMFAEncryptionKey: "c2hvcnQ="})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系: dev 以外の環境では鍵を省略できない", func(t *testing.T) {
+		_, err := NewSecretBox(&config.AppConfig{Env: "prd", JWTSecretKey: "jwt-secret"})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("正常系: dev 環境で鍵を省略した場合は JWT の鍵に依存しないランダムな鍵を使う", func(t *testing.T) {
+		cfg := &config.AppConfig{Env: "dev", JWTSecretKey: "jwt-secret"}
+		first, err := NewSecretBox(cfg)
+		require.NoError(t, err)
+		second, err := NewSecretBox(cfg)
+		require.NoError(t, err)
+		sealed, err := first.Seal([]byte("secret"), "user123")
+		require.NoError(t, err)
+
+		_, err = second.Open(sealed, "user123")
+
+		assert.ErrorIs(t, err, ErrSecretBoxOpen)
+	})
+}
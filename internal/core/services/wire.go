@@ -3,5 +3,14 @@ package services
 import "github.com/google/wire"
 
 var Set = wire.NewSet(
+	NewKeyRing,
 	NewTokenService,
+	NewPasswordHasher,
+	NewAccountTokenService,
+	NewRefreshTokenService,
+	NewAuthorizer,
+	NewAPIKeyService,
+	NewClientCertificateMapper,
+	NewTOTPService,
+	NewSecretBox,
 )
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/mailer"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// AccountUsecase はユーザー登録、メール確認、パスワードリセットを扱います
+type AccountUsecase interface {
+	// Register はユーザーを作成し、確認メールを送ります
+	// 登録済みのメールアドレスや ID の場合も成功として扱い、メールアドレスの場合はアドレスの所有者に通知します
+	// clientIP は総当たり対策に使い、空の場合は IP アドレスごとには数えません
+	Register(ctx context.Context, user *models.User, password, clientIP string) error
+	// VerifyEmail は確認トークンを消費してメールアドレスを確認済みにします
+	VerifyEmail(ctx context.Context, token string) error
+	// ForgotPassword はリセットメールを送ります。アドレスの登録有無にかかわらず成功します
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword はリセットトークンを消費してパスワードを変更し、発行済みのトークンとログインのロックを無効にします
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+type accountUsecase struct {
+	cfg                    *config.AppConfig
+	logger                 logger.Logger
+	passwordHasher         services.PasswordHasher
+	accountTokenService    services.AccountTokenService
+	userRepository         repository.UserRepository
+	accountTokenRepository repository.AccountTokenRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	revocationRepository   repository.TokenRevocationRepository
+	loginThrottle          LoginThrottle
+	mailer                 mailer.Mailer
+	now                    func() time.Time
+	// async はメールの送信をリクエストとは別に実行します
+	async func(fn func())
+}
+
+func NewAccountUsecase(
+	cfg *config.AppConfig,
+	logger logger.Logger,
+	passwordHasher services.PasswordHasher,
+	accountTokenService services.AccountTokenService,
+	userRepository repository.UserRepository,
+	accountTokenRepository repository.AccountTokenRepository,
+	refreshTokenRepository repository.RefreshTokenRepository,
+	revocationRepository repository.TokenRevocationRepository,
+	loginThrottle LoginThrottle,
+	mailer mailer.Mailer,
+) AccountUsecase {
+	return &accountUsecase{
+		cfg:                    cfg,
+		logger:                 logger,
+		passwordHasher:         passwordHasher,
+		accountTokenService:    accountTokenService,
+		userRepository:         userRepository,
+		accountTokenRepository: accountTokenRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		revocationRepository:   revocationRepository,
+		loginThrottle:          loginThrottle,
+		mailer:                 mailer,
+		now:                    time.Now,
+		async:                  func(fn func()) { go fn() },
+	}
+}
+
+// Register は登録済みのメールアドレスや ID かどうかを応答から推測されないよう、どの場合も同じ処理を行い同じ結果を返します
+func (uc *accountUsecase) Register(ctx context.Context, user *models.User, password, clientIP string) error {
+	if err := uc.loginThrottle.CheckRegistration(ctx, clientIP); err != nil {
+		return err
+	}
+
+	hash, err := uc.passwordHasher.Hash(password)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to hash password", err)
+	}
+
+	existing, err := uc.userRepository.GetByEmail(ctx, user.Email)
+	if err != nil && !isNotFoundError(err) {
+		return err
+	}
+	if existing != nil {
+		uc.notifyExistingAccount(ctx, existing)
+		return nil
+	}
+
+	now := uc.now()
+	created := &models.User{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Roles:         append([]string{}, uc.cfg.AuthDefaultRoles...),
+		EmailVerified: false,
+		PasswordHash:  hash,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := uc.userRepository.Create(ctx, created); err != nil {
+		// 同じメールアドレスで並行して登録された場合も、登録済みの場合と同じ結果にする
+		if existing, getErr := uc.userRepository.GetByEmail(ctx, user.Email); getErr == nil {
+			uc.notifyExistingAccount(ctx, existing)
+			return nil
+		}
+		// ID が使われているかを推測されないよう、ID の重複も成功として扱う。別人のアドレスかもしれないため通知は送らない
+		if isConflictError(err) {
+			uc.logger.InfoContext(ctx, "Registration attempted for existing user ID", "user_id", created.ID)
+			return nil
+		}
+		return err
+	}
+
+	// ユーザーは作成済みのため、メール送信の失敗は登録の失敗にしない
+	uc.dispatch(ctx, func(ctx context.Context) {
+		if err := uc.sendToken(ctx, created, models.AccountTokenPurposeEmailVerification); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to send verification mail", "user_id", created.ID, "error", err)
+		}
+	})
+	uc.logger.InfoContext(ctx, "User registered", "user_id", created.ID)
+	return nil
+}
+
+// notifyExistingAccount は登録済みのアドレスへの登録があったことを所有者に知らせます
+func (uc *accountUsecase) notifyExistingAccount(ctx context.Context, user *models.User) {
+	uc.logger.InfoContext(ctx, "Registration attempted for existing email", "user_id", user.ID)
+	uc.dispatch(ctx, func(ctx context.Context) {
+		if err := uc.mailer.Send(ctx, uc.existingAccountMessage(user)); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to send existing account mail", "user_id", user.ID, "error", err)
+		}
+	})
+}
+
+func (uc *accountUsecase) VerifyEmail(ctx context.Context, token string) error {
+	user, err := uc.consumeToken(ctx, token, models.AccountTokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user.EmailVerified = true
+	user.UpdatedAt = uc.now()
+	if err := uc.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Email verified", "user_id", user.ID)
+	return nil
+}
+
+// ForgotPassword は登録の有無を応答時間から推測されないよう、トークンの発行とメールの送信をリクエストとは別に行います
+func (uc *accountUsecase) ForgotPassword(ctx context.Context, email string) error {
+	user, err := uc.userRepository.GetByEmail(ctx, email)
+	if isNotFoundError(err) {
+		// 登録の有無を推測されないよう、存在しない場合も成功として扱う
+		uc.logger.InfoContext(ctx, "Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	uc.dispatch(ctx, func(ctx context.Context) {
+		// 新しいリセットを要求した時点で、以前に送ったリセットトークンは無効にする
+		if err := uc.accountTokenRepository.DeleteByUser(ctx, user.ID, models.AccountTokenPurposePasswordReset); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to delete password reset tokens", "user_id", user.ID, "error", err)
+			return
+		}
+		if err := uc.sendToken(ctx, user, models.AccountTokenPurposePasswordReset); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to send password reset mail", "user_id", user.ID, "error", err)
+		}
+	})
+	uc.logger.InfoContext(ctx, "Password reset requested", "user_id", user.ID)
+	return nil
+}
+
+func (uc *accountUsecase) ResetPassword(ctx context.Context, token, password string) error {
+	user, err := uc.consumeToken(ctx, token, models.AccountTokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := uc.passwordHasher.Hash(password)
+	if err != nil {
+		return apperrors.NewInternalError("Failed to hash password", err)
+	}
+	user.PasswordHash = hash
+	// リセットメールを受け取れたことでアドレスの所有も確認できている
+	user.EmailVerified = true
+	now := uc.now()
+	user.UpdatedAt = now
+	if err := uc.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// 漏えいしたパスワードで発行されたトークンが残らないよう、リセット前に発行したトークンを失効させる
+	if err := uc.revocationRepository.RevokeUser(ctx, user.ID, now, now.Add(uc.cfg.AuthAccessTokenTTL)); err != nil {
+		return err
+	}
+	if err := uc.refreshTokenRepository.RevokeByUser(ctx, user.ID, now); err != nil {
+		return err
+	}
+	// 新しいパスワードでログインできるよう、失敗によるロックを解除する
+	if _, err := uc.loginThrottle.Unlock(ctx, user.ID); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Password reset", "user_id", user.ID)
+	return nil
+}
+
+// consumeToken はトークンをストアから取り出して検証し、対象のユーザーを返します
+// 取り出した時点でトークンは消費されるため、検証に失敗したトークンも再利用できません
+func (uc *accountUsecase) consumeToken(ctx context.Context, token string, purpose models.AccountTokenPurpose) (*models.User, error) {
+	record, err := uc.accountTokenRepository.Take(ctx, uc.accountTokenService.Hash(token))
+	if isNotFoundError(err) {
+		record = nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := uc.accountTokenService.Validate(record, purpose); err != nil {
+		uc.logger.WarnContext(ctx, "Invalid account token", "purpose", purpose, "found", record != nil)
+		return nil, err
+	}
+
+	user, err := uc.userRepository.Get(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// dispatch は fn をリクエストとは別に実行します。リクエストのキャンセルは fn に伝えません
+func (uc *accountUsecase) dispatch(ctx context.Context, fn func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	uc.async(func() { fn(ctx) })
+}
+
+func (uc *accountUsecase) sendToken(ctx context.Context, user *models.User, purpose models.AccountTokenPurpose) error {
+	token, record, err := uc.accountTokenService.Issue(user.ID, purpose)
+	if err != nil {
+		return err
+	}
+	if err := uc.accountTokenRepository.Create(ctx, record); err != nil {
+		return err
+	}
+	return uc.mailer.Send(ctx, uc.buildMessage(user, purpose, token, record.ExpiresAt))
+}
+
+func (uc *accountUsecase) buildMessage(user *models.User, purpose models.AccountTokenPurpose, token string, expiresAt time.Time) mailer.Message {
+	baseURL := strings.TrimSuffix(uc.cfg.AppBaseURL, "/")
+	switch purpose {
+	case models.AccountTokenPurposePasswordReset:
+		return mailer.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Open the link below to set a new password.\n\n%s/reset-password?token=%s\n\nToken: %s\nThis link expires at %s. If you did not request this, you can ignore this email.",
+				baseURL, token, token, expiresAt.UTC().Format(time.RFC3339)),
+		}
+	default:
+		return mailer.Message{
+			To:      user.Email,
+			Subject: "Verify your email address",
+			Body: fmt.Sprintf("Open the link below to verify your email address.\n\n%s/verify-email?token=%s\n\nToken: %s\nThis link expires at %s.",
+				baseURL, token, token, expiresAt.UTC().Format(time.RFC3339)),
+		}
+	}
+}
+
+func (uc *accountUsecase) existingAccountMessage(user *models.User) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is already registered",
+		Body:    "Someone tried to create an account with this email address, which is already registered.\n\nIf this was you, sign in or request a password reset instead. If not, you can ignore this email.",
+	}
+}
+
+func isNotFoundError(err error) bool {
+	var appErr *apperrors.AppError
+	return errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound
+}
+
+func isConflictError(err error) bool {
+	var appErr *apperrors.AppError
+	return errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeConflict
+}
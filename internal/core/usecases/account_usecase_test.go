@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/mailer"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer は送信したメールを記録します
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	require.NotEmpty(t, m.messages)
+	match := regexp.MustCompile(`Token: (\S+)`).FindStringSubmatch(m.messages[len(m.messages)-1].Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+// blockingMailer は release が閉じられるまで送信を完了しません
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan mailer.Message
+}
+
+func (m *blockingMailer) Send(_ context.Context, msg mailer.Message) error {
+	<-m.release
+	m.sent <- msg
+	return nil
+}
+
+func TestAccountUsecase(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.AppConfig{
+		AuthDefaultRoles:         []string{"role:default:viewer"},
+		AuthEmailVerificationTTL: time.Hour,
+		AuthPasswordResetTTL:     time.Hour,
+		AppBaseURL:               "http://localhost:8081",
+	}
+	hasher := services.NewPasswordHasher()
+
+	newUsecase := func(cfg *config.AppConfig, users repository.UserRepository, m mailer.Mailer) *accountUsecase {
+		return NewAccountUsecase(cfg, logger.NewLogger(&config.AppConfig{}), hasher,
+			services.NewAccountTokenService(cfg), users, repository.NewMemoryAccountTokenRepository(),
+			repository.NewMemoryRefreshTokenRepository(), repository.NewMemoryTokenRevocationRepository(), newLoginThrottle(cfg), m).(*accountUsecase)
+	}
+	setup := func() (AccountUsecase, repository.UserRepository, *recordingMailer) {
+		users := repository.NewMemoryUserRepository()
+		m := &recordingMailer{}
+		uc := newUsecase(cfg, users, m)
+		// メールの送信を待ってから検証できるよう同期的に実行する
+		uc.async = func(fn func()) { fn() }
+		return uc, users, m
+	}
+
+	t.Run("正常系: 登録したユーザーは未確認で、確認トークンでメールが確認済みになる", func(t *testing.T) {
+		uc, users, m := setup()
+
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Name: "Alice", Email: "alice@example.com"}, "password123", ""))
+		user, err := users.Get(ctx, "alice")
+		require.NoError(t, err)
+		assert.False(t, user.EmailVerified)
+		assert.Equal(t, []string{"role:default:viewer"}, user.Roles)
+		assert.Equal(t, "alice@example.com", m.messages[0].To)
+
+		token := m.lastToken(t)
+		require.NoError(t, uc.VerifyEmail(ctx, token))
+
+		stored, err := users.Get(ctx, "alice")
+		require.NoError(t, err)
+		assert.True(t, stored.EmailVerified)
+
+		// トークンは一度しか使えない
+		assertAppErrorType(t, uc.VerifyEmail(ctx, token), apperrors.ErrorTypeBadRequest)
+	})
+
+	t.Run("正常系: 使用済みの ID での登録も同じく成功し、既存のユーザーは変わらない", func(t *testing.T) {
+		uc, users, m := setup()
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Email: "alice@example.com"}, "password123", ""))
+
+		err := uc.Register(ctx, &models.User{ID: "alice", Email: "other@example.com"}, "password123", "")
+
+		require.NoError(t, err)
+		stored, err := users.Get(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", stored.Email)
+		_, err = users.GetByEmail(ctx, "other@example.com")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+		// 別人のアドレスかもしれないため、どちらのアドレスにも送らない
+		assert.Len(t, m.messages, 1)
+	})
+
+	t.Run("異常系: 同じ IP アドレスからの登録が上限を超えると 429", func(t *testing.T) {
+		limited := *cfg
+		limited.AuthLoginFailureWindow = time.Hour
+		limited.AuthRegisterIPMaxAttempts = 2
+		uc := newUsecase(&limited, repository.NewMemoryUserRepository(), &recordingMailer{})
+		uc.async = func(fn func()) { fn() }
+
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Email: "alice@example.com"}, "password123", "192.0.2.1"))
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Email: "alice@example.com"}, "password123", "192.0.2.1"))
+		err := uc.Register(ctx, &models.User{ID: "bob", Email: "bob@example.com"}, "password123", "192.0.2.1")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeRateLimit)
+		// 別の IP アドレスからは登録できる
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "bob", Email: "bob@example.com"}, "password123", "192.0.2.2"))
+	})
+
+	t.Run("正常系: 登録済みのメールアドレスでも成功し、所有者に通知する", func(t *testing.T) {
+		uc, users, m := setup()
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Email: "alice@example.com"}, "password123", ""))
+
+		err := uc.Register(ctx, &models.User{ID: "mallory", Email: "alice@example.com"}, "password123", "")
+
+		require.NoError(t, err)
+		_, err = users.Get(ctx, "mallory")
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+		require.Len(t, m.messages, 2)
+		assert.Equal(t, "alice@example.com", m.messages[1].To)
+		assert.Equal(t, "Your email address is already registered", m.messages[1].Subject)
+	})
+
+	t.Run("正常系: パスワードリセットはメールの送信を待たずに応答する", func(t *testing.T) {
+		users := repository.NewMemoryUserRepository()
+		require.NoError(t, users.Create(ctx, &models.User{ID: "alice", Email: "alice@example.com"}))
+		m := &blockingMailer{release: make(chan struct{}), sent: make(chan mailer.Message, 1)}
+		uc := newUsecase(cfg, users, m)
+
+		require.NoError(t, uc.ForgotPassword(ctx, "alice@example.com"))
+
+		close(m.release)
+		msg := <-m.sent
+		assert.Equal(t, "alice@example.com", msg.To)
+	})
+
+	t.Run("正常系: リセットトークンでパスワードを変更でき、古いトークンは無効になる", func(t *testing.T) {
+		uc, users, m := setup()
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Email: "alice@example.com"}, "password123", ""))
+
+		require.NoError(t, uc.ForgotPassword(ctx, "alice@example.com"))
+		oldToken := m.lastToken(t)
+		require.NoError(t, uc.ForgotPassword(ctx, "alice@example.com"))
+		newToken := m.lastToken(t)
+
+		assertAppErrorType(t, uc.ResetPassword(ctx, oldToken, "new-password"), apperrors.ErrorTypeBadRequest)
+		require.NoError(t, uc.ResetPassword(ctx, newToken, "new-password"))
+
+		stored, err := users.Get(ctx, "alice")
+		require.NoError(t, err)
+		ok, err := hasher.Verify("new-password", stored.PasswordHash)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assertAppErrorType(t, uc.ResetPassword(ctx, newToken, "another-password"), apperrors.ErrorTypeBadRequest)
+	})
+
+	t.Run("正常系: リセット前に発行したトークンは使えなくなり、ログインのロックも解除される", func(t *testing.T) {
+		authCfg := *cfg
+		authCfg.JWTSecretKey = "secret"
+		authCfg.JWTIssuer = "issuer"
+		authCfg.JWTAudience = "audience"
+		authCfg.AuthAccessTokenTTL = 15 * time.Minute
+		authCfg.AuthRefreshTokenTTL = time.Hour
+		authCfg.AuthLoginFailureWindow = time.Hour
+		authCfg.AuthLockoutThreshold = 1
+		authCfg.AuthLockoutDuration = time.Hour
+		hash, err := hasher.Hash("password123")
+		require.NoError(t, err)
+		users := repository.NewMemoryUserRepository()
+		require.NoError(t, users.Create(ctx, &models.User{ID: "alice", Email: "alice@example.com", EmailVerified: true, PasswordHash: hash}))
+		refreshTokens := repository.NewMemoryRefreshTokenRepository()
+		revocations := repository.NewMemoryTokenRevocationRepository()
+		throttle := newLoginThrottle(&authCfg)
+		keyRing := mustKeyRing(t, &authCfg)
+		auth, err := NewAuthUsecase(&authCfg, logger.NewLogger(&config.AppConfig{}), services.NewTokenService(&authCfg, keyRing), keyRing, hasher, users,
+			services.NewRefreshTokenService(&authCfg), refreshTokens, revocations, mustOIDCVerifier(t), throttle,
+			repository.NewMemoryMFARepository(), services.NewTOTPService(&authCfg), mustSecretBox(t))
+		require.NoError(t, err)
+		m := &recordingMailer{}
+		uc := NewAccountUsecase(&authCfg, logger.NewLogger(&config.AppConfig{}), hasher,
+			services.NewAccountTokenService(&authCfg), users, repository.NewMemoryAccountTokenRepository(),
+			refreshTokens, revocations, throttle, m).(*accountUsecase)
+		uc.async = func(fn func()) { fn() }
+
+		result, err := auth.Login(ctx, "alice", "password123", "")
+		require.NoError(t, err)
+		before := result.Tokens
+		// 失敗してロックされる
+		_, err = auth.Login(ctx, "alice", "wrong-password", "")
+		require.Error(t, err)
+		_, err = auth.Login(ctx, "alice", "password123", "")
+		assertAppErrorType(t, err, apperrors.ErrorTypeRateLimit)
+
+		require.NoError(t, uc.ForgotPassword(ctx, "alice@example.com"))
+		require.NoError(t, uc.ResetPassword(ctx, m.lastToken(t), "new-password"))
+
+		_, err = auth.Refresh(ctx, before.RefreshToken)
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+		_, err = auth.Authenticate(ctx, before.AccessToken)
+		require.Error(t, err)
+		_, err = auth.Login(ctx, "alice", "new-password", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("正常系: 未登録のメールアドレスでも成功し、メールは送らない", func(t *testing.T) {
+		uc, _, m := setup()
+
+		require.NoError(t, uc.ForgotPassword(ctx, "nobody@example.com"))
+		assert.Empty(t, m.messages)
+	})
+
+	t.Run("異常系: 確認トークンではパスワードをリセットできない", func(t *testing.T) {
+		uc, _, m := setup()
+		require.NoError(t, uc.Register(ctx, &models.User{ID: "alice", Email: "alice@example.com"}, "password123", ""))
+
+		err := uc.ResetPassword(ctx, m.lastToken(t), "new-password")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeBadRequest)
+	})
+}
+
+func assertAppErrorType(t *testing.T, err error, want apperrors.ErrorType) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	if assert.True(t, errors.As(err, &appErr), "expected *apperrors.AppError, got %T", err) {
+		assert.Equal(t, want, appErr.Type)
+	}
+}
@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// apiKeyLastUsedResolution は最終使用日時を記録する間隔です。リクエストのたびに書き込まないよう間引きます
+const apiKeyLastUsedResolution = time.Minute
+
+type APIKeyUsecase interface {
+	// Create はキーを発行します。キーはこの戻り値でしか得られません
+	// スコープは actor が持つ権限の範囲内に限られます。ユーザーストアにいないプリンシパルは 403 です
+	Create(ctx context.Context, actor *models.User, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error)
+	List(ctx context.Context, actor *models.User) ([]models.APIKey, error)
+	Revoke(ctx context.Context, actor *models.User, id string) error
+	// Authenticate はキーを検証し、所有者のロールとキーのスコープを持つユーザーを返します
+	Authenticate(ctx context.Context, key string) (*models.User, error)
+}
+
+type apiKeyUsecase struct {
+	logger           logger.Logger
+	apiKeyService    services.APIKeyService
+	authorizer       services.Authorizer
+	apiKeyRepository repository.APIKeyRepository
+	userRepository   repository.UserRepository
+	now              func() time.Time
+}
+
+func NewAPIKeyUsecase(
+	logger logger.Logger,
+	apiKeyService services.APIKeyService,
+	authorizer services.Authorizer,
+	apiKeyRepository repository.APIKeyRepository,
+	userRepository repository.UserRepository,
+) APIKeyUsecase {
+	return &apiKeyUsecase{
+		logger:           logger,
+		apiKeyService:    apiKeyService,
+		authorizer:       authorizer,
+		apiKeyRepository: apiKeyRepository,
+		userRepository:   userRepository,
+		now:              time.Now,
+	}
+}
+
+func (uc *apiKeyUsecase) Create(ctx context.Context, actor *models.User, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error) {
+	if actor == nil {
+		return "", nil, apperrors.NewUnauthorizedError("Authentication required", nil)
+	}
+	// キーは所有者のロールで認証するため、OAuth クライアントや証明書などユーザーストアにいないプリンシパルには発行しない
+	if _, err := uc.userRepository.Get(ctx, actor.ID); err != nil {
+		if isNotFoundError(err) {
+			return "", nil, apperrors.NewForbiddenError("API keys can only be created by users", nil)
+		}
+		return "", nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(uc.now()) {
+		return "", nil, apperrors.NewBadRequestError("expires_at must be in the future", nil)
+	}
+	// スコープを付けたキーで、より広いスコープのキーを発行できないようにする
+	for _, scope := range scopes {
+		if err := uc.authorizer.Authorize(actor, scope); err != nil {
+			return "", nil, apperrors.NewForbiddenError(fmt.Sprintf("Scope %s exceeds your permissions", scope), nil)
+		}
+	}
+
+	key, record, err := uc.apiKeyService.Issue(actor.ID, name, scopes, expiresAt)
+	if err != nil {
+		return "", nil, apperrors.NewInternalError("Failed to issue API key", err)
+	}
+	if err := uc.apiKeyRepository.Create(ctx, record); err != nil {
+		return "", nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "API key created", "api_key_id", record.ID, "scopes", scopes)
+	return key, record, nil
+}
+
+func (uc *apiKeyUsecase) List(ctx context.Context, actor *models.User) ([]models.APIKey, error) {
+	if actor == nil {
+		return nil, apperrors.NewUnauthorizedError("Authentication required", nil)
+	}
+	return uc.apiKeyRepository.ListByUser(ctx, actor.ID)
+}
+
+func (uc *apiKeyUsecase) Revoke(ctx context.Context, actor *models.User, id string) error {
+	if actor == nil {
+		return apperrors.NewUnauthorizedError("Authentication required", nil)
+	}
+	if err := uc.apiKeyRepository.Revoke(ctx, id, actor.ID, uc.now()); err != nil {
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "API key revoked", "api_key_id", id)
+	return nil
+}
+
+// Authenticate は失敗理由にかかわらず同じ 401 を返します
+func (uc *apiKeyUsecase) Authenticate(ctx context.Context, key string) (*models.User, error) {
+	id, ok := uc.apiKeyService.ParseID(key)
+	if !ok {
+		return nil, services.InvalidAPIKeyError()
+	}
+	record, err := uc.apiKeyRepository.Get(ctx, id)
+	if isNotFoundError(err) {
+		return nil, services.InvalidAPIKeyError()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.apiKeyService.Verify(record, key); err != nil {
+		uc.logger.WarnContext(ctx, "Invalid API key", "api_key_id", id)
+		return nil, err
+	}
+
+	// ロールの変更をすぐに反映するため、所有者のロールはストアから読み直す
+	owner, err := uc.userRepository.Get(ctx, record.UserID)
+	if isNotFoundError(err) {
+		return nil, services.InvalidAPIKeyError()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := uc.now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= apiKeyLastUsedResolution {
+		if err := uc.apiKeyRepository.UpdateLastUsed(ctx, record.ID, now); err != nil {
+			// 記録に失敗しても認証は妨げない
+			uc.logger.ErrorContext(ctx, "Failed to record API key usage", "api_key_id", record.ID, "error", err)
+		}
+	}
+
+	scopes := record.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return &models.User{
+		ID:     owner.ID,
+		Name:   owner.Name,
+		Email:  owner.Email,
+		Roles:  owner.Roles,
+		Scopes: scopes,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyUsecase(t *testing.T) {
+	ctx := context.Background()
+
+	editor := &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}}
+	users := repository.NewMemoryUserRepository()
+	require.NoError(t, users.Create(ctx, editor))
+
+	cfg := &config.AppConfig{AuthRolePermissions: map[string][]string{"editor": {"samples:read", "samples:write"}}}
+	authorizer := services.NewAuthorizer(cfg)
+	setup := func() (APIKeyUsecase, repository.APIKeyRepository) {
+		keys := repository.NewMemoryAPIKeyRepository()
+		return NewAPIKeyUsecase(logger.NewLogger(&config.AppConfig{}), services.NewAPIKeyService(), authorizer, keys, users), keys
+	}
+
+	t.Run("正常系: 発行したキーで所有者のロールとキーのスコープを持つユーザーとして認証できる", func(t *testing.T) {
+		target, keys := setup()
+		key, record, err := target.Create(ctx, editor, "batch", []string{"samples:read"}, nil)
+		require.NoError(t, err)
+		assert.Contains(t, key, record.ID+"_")
+		assert.NotContains(t, record.KeyHash, key)
+
+		user, err := target.Authenticate(ctx, key)
+
+		require.NoError(t, err)
+		assert.Equal(t, "user123", user.ID)
+		assert.Equal(t, []string{"samples:read"}, user.Scopes)
+		assert.NoError(t, authorizer.Authorize(user, "samples:read"))
+		assert.Error(t, authorizer.Authorize(user, "samples:write"), "スコープ外の操作は許可しない")
+
+		stored, err := keys.Get(ctx, record.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.LastUsedAt)
+	})
+
+	t.Run("異常系: 持っていない権限のスコープは発行できない", func(t *testing.T) {
+		target, _ := setup()
+
+		_, _, err := target.Create(ctx, editor, "batch", []string{"samples:read", "users:admin"}, nil)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+	})
+
+	t.Run("異常系: スコープ付きのキーではより広いスコープのキーを発行できない", func(t *testing.T) {
+		target, _ := setup()
+		scoped := &models.User{ID: editor.ID, Roles: editor.Roles, Scopes: []string{"samples:read"}}
+
+		_, _, err := target.Create(ctx, scoped, "batch", []string{"samples:write"}, nil)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+	})
+
+	t.Run("異常系: OAuth クライアントなどユーザーでないプリンシパルはキーを発行できない", func(t *testing.T) {
+		target, keys := setup()
+		client := &models.User{ID: "client:batch", Roles: editor.Roles, Scopes: []string{"api-keys:manage", "samples:read"}}
+
+		_, _, err := target.Create(ctx, client, "batch", []string{"samples:read"}, nil)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+		listed, err := keys.ListByUser(ctx, client.ID)
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+
+	t.Run("異常系: 失効・期限切れ・改ざんされたキーは同じ 401", func(t *testing.T) {
+		target, keys := setup()
+		revoked, record, err := target.Create(ctx, editor, "revoked", []string{"samples:read"}, nil)
+		require.NoError(t, err)
+		require.NoError(t, target.Revoke(ctx, editor, record.ID))
+
+		past := time.Now().Add(-time.Minute)
+		expired, expiredRecord, err := services.NewAPIKeyService().Issue(editor.ID, "expired", []string{"samples:read"}, &past)
+		require.NoError(t, err)
+		require.NoError(t, keys.Create(ctx, expiredRecord))
+
+		valid, _, err := target.Create(ctx, editor, "valid", []string{"samples:read"}, nil)
+		require.NoError(t, err)
+		tampered := valid[:len(valid)-1] + "x"
+		if tampered == valid {
+			tampered = valid[:len(valid)-1] + "y"
+		}
+
+		for _, key := range []string{revoked, expired, tampered, "not-an-api-key"} {
+			_, err := target.Authenticate(ctx, key)
+			assert.Equal(t, services.InvalidAPIKeyError(), err)
+		}
+	})
+
+	t.Run("異常系: 他のユーザーのキーは失効できない", func(t *testing.T) {
+		target, _ := setup()
+		_, record, err := target.Create(ctx, editor, "batch", []string{"samples:read"}, nil)
+		require.NoError(t, err)
+
+		err = target.Revoke(ctx, &models.User{ID: "other"}, record.ID)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeNotFound)
+	})
+
+	t.Run("異常系: 過去の有効期限は指定できない", func(t *testing.T) {
+		target, _ := setup()
+		past := time.Now().Add(-time.Minute)
+
+		_, _, err := target.Create(ctx, editor, "batch", []string{"samples:read"}, &past)
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeBadRequest)
+	})
+}
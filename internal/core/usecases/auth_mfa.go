@@ -0,0 +1,323 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+)
+
+// mfaStatus は MFA が必要かと、確認済みの登録があるかを返します
+// 確認済みの登録があるユーザーと、auth_mfa_required_roles のロールを持つユーザーは MFA が必要です
+func (uc *authUsecase) mfaStatus(ctx context.Context, user *models.User) (required, enrolled bool, err error) {
+	enrollment, err := uc.mfaRepository.Get(ctx, user.ID)
+	if err != nil && !isNotFoundError(err) {
+		return false, false, err
+	}
+	enrolled = enrollment != nil && enrollment.ConfirmedAt != nil
+	return enrolled || hasAnyRole(user.Roles, uc.cfg.AuthMFARequiredRoles), enrolled, nil
+}
+
+func (uc *authUsecase) startMFAChallenge(ctx context.Context, user *models.User, enrolled bool) (*models.LoginResult, error) {
+	challenge, err := uc.tokenService.GenerateMFAChallenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "MFA challenge issued", "user_id", user.ID, "enrollment_required", !enrolled)
+	return &models.LoginResult{
+		MFAChallenge:          challenge,
+		MFAChallengeExpiresIn: uc.cfg.AuthMFAChallengeTTL,
+		MFAEnrollmentRequired: !enrolled,
+	}, nil
+}
+
+// VerifyMFA はコードの失敗もログインの失敗として数え、総当たりを防ぎます
+// コードを受け付けたチャレンジはトークンの発行前に失効させ、同じチャレンジで再びトークンを得られないようにします
+func (uc *authUsecase) VerifyMFA(ctx context.Context, challenge, code, clientIP string) (*models.TokenPair, error) {
+	claims, err := uc.validateMFAChallenge(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	attempt, err := uc.loginThrottle.Check(ctx, claims.UserID, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	defer uc.releaseLoginAttempt(ctx, attempt)
+
+	// ロールの変更を反映するため、ユーザーはストアから読み直す
+	user, err := uc.userRepository.Get(ctx, claims.UserID)
+	if isNotFoundError(err) {
+		return nil, invalidMFAChallengeError(nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := uc.mfaRepository.Get(ctx, user.ID)
+	if isNotFoundError(err) {
+		return nil, apperrors.NewForbiddenError("MFA enrollment required", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// リカバリーコードは登録の確認前には使えない
+	ok, err := uc.verifyMFACode(ctx, enrollment, code, enrollment.ConfirmedAt != nil)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.recordMFAFailure(ctx, attempt)
+		return nil, invalidMFACodeError()
+	}
+	// 並行したリクエストが別々のコードで同じチャレンジを使っても、トークンを得られるのは 1 つだけにする
+	if err := uc.claimMFAChallenge(ctx, claims); err != nil {
+		return nil, err
+	}
+	if enrollment.ConfirmedAt == nil {
+		if err := uc.confirmEnrollment(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	uc.recordLoginSuccess(ctx, attempt)
+	return uc.issueTokens(ctx, user, "")
+}
+
+func (uc *authUsecase) EnrollMFA(ctx context.Context, actor *models.User) (*models.MFASetup, error) {
+	if err := requireUserSession(actor); err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepository.Get(ctx, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(ctx, user)
+}
+
+func (uc *authUsecase) EnrollMFAWithChallenge(ctx context.Context, challenge string) (*models.MFASetup, error) {
+	claims, err := uc.validateMFAChallenge(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepository.Get(ctx, claims.UserID)
+	if isNotFoundError(err) {
+		return nil, invalidMFAChallengeError(nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(ctx, user)
+}
+
+// startEnrollment はシークレットとリカバリーコードを生成し、未確認の登録として保存します
+// 未確認の登録がある場合は置き換えます。シークレットとリカバリーコードを返すのはこのときだけです
+func (uc *authUsecase) startEnrollment(ctx context.Context, user *models.User) (*models.MFASetup, error) {
+	existing, err := uc.mfaRepository.Get(ctx, user.ID)
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+	if existing != nil && existing.ConfirmedAt != nil {
+		return nil, apperrors.NewConflictError("MFA is already enabled", nil)
+	}
+
+	secret, err := uc.totpService.GenerateSecret()
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to generate MFA secret", err)
+	}
+	codes, hashes, err := uc.totpService.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to generate recovery codes", err)
+	}
+	sealed, err := uc.secretBox.Seal([]byte(secret), user.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to encrypt MFA secret", err)
+	}
+
+	if err := uc.mfaRepository.Save(ctx, &models.MFAEnrollment{
+		UserID:             user.ID,
+		SecretCiphertext:   sealed,
+		RecoveryCodeHashes: hashes,
+		CreatedAt:          time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.ID
+	}
+	uc.logger.InfoContext(ctx, "MFA enrollment started", "user_id", user.ID)
+	return &models.MFASetup{
+		Secret:        secret,
+		OTPAuthURI:    uc.totpService.URI(account, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (uc *authUsecase) ConfirmMFA(ctx context.Context, actor *models.User, code string) error {
+	if err := requireUserSession(actor); err != nil {
+		return err
+	}
+	enrollment, err := uc.mfaRepository.Get(ctx, actor.ID)
+	if err != nil {
+		return err
+	}
+	if enrollment.ConfirmedAt != nil {
+		return apperrors.NewConflictError("MFA is already enabled", nil)
+	}
+
+	attempt, err := uc.loginThrottle.Check(ctx, actor.ID, "")
+	if err != nil {
+		return err
+	}
+	defer uc.releaseLoginAttempt(ctx, attempt)
+	ok, err := uc.verifyMFACode(ctx, enrollment, code, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		uc.recordMFAFailure(ctx, attempt)
+		return invalidMFACodeError()
+	}
+	return uc.confirmEnrollment(ctx, actor.ID)
+}
+
+func (uc *authUsecase) DisableMFA(ctx context.Context, actor *models.User, code string) error {
+	if err := requireUserSession(actor); err != nil {
+		return err
+	}
+	user, err := uc.userRepository.Get(ctx, actor.ID)
+	if err != nil {
+		return err
+	}
+	if hasAnyRole(user.Roles, uc.cfg.AuthMFARequiredRoles) {
+		return apperrors.NewForbiddenError("MFA is required for your role", nil)
+	}
+	enrollment, err := uc.mfaRepository.Get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// 有効な登録の削除は、アクセストークンの漏洩だけでは行えないようコードを要求する
+	if enrollment.ConfirmedAt != nil {
+		attempt, err := uc.loginThrottle.Check(ctx, user.ID, "")
+		if err != nil {
+			return err
+		}
+		defer uc.releaseLoginAttempt(ctx, attempt)
+		ok, err := uc.verifyMFACode(ctx, enrollment, code, true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			uc.recordMFAFailure(ctx, attempt)
+			return invalidMFACodeError()
+		}
+	}
+
+	if err := uc.mfaRepository.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+	uc.logger.With("log_type", "audit").InfoContext(ctx, "MFA disabled", "event", "mfa_disabled", "user_id", user.ID)
+	return nil
+}
+
+func (uc *authUsecase) confirmEnrollment(ctx context.Context, userID string) error {
+	if err := uc.mfaRepository.Confirm(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+	uc.logger.With("log_type", "audit").InfoContext(ctx, "MFA enabled", "event", "mfa_enabled", "user_id", userID)
+	return nil
+}
+
+// verifyMFACode は TOTP のコードを検証し、allowRecovery の場合はリカバリーコードも受け付けます
+// 受け付けたコードは使用済みにし、同じコードを二度使えないようにします
+func (uc *authUsecase) verifyMFACode(ctx context.Context, enrollment *models.MFAEnrollment, code string, allowRecovery bool) (bool, error) {
+	secret, err := uc.secretBox.Open(enrollment.SecretCiphertext, enrollment.UserID)
+	if err != nil {
+		return false, apperrors.NewInternalError("Failed to decrypt MFA secret", err)
+	}
+
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if step, ok := uc.totpService.Validate(string(secret), code, enrollment.LastUsedStep); ok {
+		return uc.mfaRepository.UseStep(ctx, enrollment.UserID, step)
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	used, err := uc.mfaRepository.UseRecoveryCode(ctx, enrollment.UserID, uc.totpService.HashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	if used {
+		uc.logger.With("log_type", "audit").InfoContext(ctx, "MFA recovery code used",
+			"event", "mfa_recovery_code_used", "user_id", enrollment.UserID, "remaining", len(enrollment.RecoveryCodeHashes)-1)
+	}
+	return used, nil
+}
+
+func (uc *authUsecase) recordMFAFailure(ctx context.Context, attempt *LoginAttempt) {
+	uc.logger.WarnContext(ctx, "MFA verification failed", "user_id", attempt.userID)
+	uc.recordLoginFailure(ctx, attempt)
+}
+
+// validateMFAChallenge は署名と有効期限に加えて、使用済みでないことを検証します
+func (uc *authUsecase) validateMFAChallenge(ctx context.Context, challenge string) (*models.Claims, error) {
+	claims, err := uc.tokenService.ValidateMFAChallenge(ctx, challenge)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "MFA challenge rejected", "error", err)
+		return nil, invalidMFAChallengeError(err)
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := uc.revocationRepository.IsRevoked(ctx, claims.ID, claims.UserID, issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		uc.logger.WarnContext(ctx, "Revoked MFA challenge used", "user_id", claims.UserID)
+		return nil, invalidMFAChallengeError(nil)
+	}
+	return claims, nil
+}
+
+// claimMFAChallenge はチャレンジを失効させます。既に失効していた場合は 401 を返します
+func (uc *authUsecase) claimMFAChallenge(ctx context.Context, claims *models.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return invalidMFAChallengeError(nil)
+	}
+	claimed, err := uc.revocationRepository.ClaimToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		uc.logger.WarnContext(ctx, "MFA challenge already used", "user_id", claims.UserID)
+		return invalidMFAChallengeError(nil)
+	}
+	return nil
+}
+
+// requireUserSession は API キーやクライアントのトークンなど、スコープで制限されたプリンシパルを拒否します
+func requireUserSession(actor *models.User) error {
+	if actor == nil {
+		return apperrors.NewUnauthorizedError("Authentication required", nil)
+	}
+	if actor.Scopes != nil {
+		return apperrors.NewForbiddenError("MFA settings require a user session", nil)
+	}
+	return nil
+}
+
+func invalidMFAChallengeError(err error) error {
+	return apperrors.NewUnauthorizedError("Invalid or expired MFA challenge", err)
+}
+
+func invalidMFACodeError() error {
+	return apperrors.NewUnauthorizedError("Invalid MFA code", nil)
+}
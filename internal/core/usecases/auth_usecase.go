@@ -2,37 +2,417 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/oidc"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
 )
 
 type AuthUsecase interface {
-	Login(ctx context.Context, userID string, roles []string) (string, error)
+	// Login は認証情報を検証してトークンを発行します。clientIP は総当たり対策に使い、空の場合は IP アドレスごとには数えません
+	// MFA が必要なユーザーにはトークンの代わりに MFA のチャレンジを返します
+	Login(ctx context.Context, userID, password, clientIP string) (*models.LoginResult, error)
+	// VerifyMFA は MFA のチャレンジと TOTP またはリカバリーコードを検証してトークンを発行します
+	VerifyMFA(ctx context.Context, challenge, code, clientIP string) (*models.TokenPair, error)
+	// EnrollMFA はログイン中のユーザーの TOTP の登録を開始します。ConfirmMFA でコードを確認するまで有効になりません
+	EnrollMFA(ctx context.Context, actor *models.User) (*models.MFASetup, error)
+	// EnrollMFAWithChallenge はロールにより MFA が必須で未登録のユーザーが、MFA のチャレンジで登録を開始します
+	// 登録は VerifyMFA でコードを確認した時点で有効になります
+	EnrollMFAWithChallenge(ctx context.Context, challenge string) (*models.MFASetup, error)
+	// ConfirmMFA は認証アプリのコードを確認して登録を有効にします
+	ConfirmMFA(ctx context.Context, actor *models.User, code string) error
+	// DisableMFA は TOTP またはリカバリーコードを確認して登録を削除します。MFA が必須のロールでは削除できません
+	DisableMFA(ctx context.Context, actor *models.User, code string) error
+	// Refresh はリフレッシュトークンを新しいトークンの組に交換します
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error)
 	Authenticate(ctx context.Context, tokenString string) (*models.User, error)
+	// Logout はアクセストークンを失効させます。リフレッシュトークンを指定した場合はそのファミリーも失効させます
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	// RevokeUserTokens はユーザーに発行済みのトークンをすべて失効させます。管理者のみ実行できます
+	RevokeUserTokens(ctx context.Context, actor *models.User, userID string) error
+	// UnlockUser はログインの失敗によるユーザーのロックを解除します。管理者のみ実行できます
+	UnlockUser(ctx context.Context, actor *models.User, userID string) error
+	// JWKS はアクセストークンの検証に使う公開鍵を返します
+	JWKS(ctx context.Context) models.JWKS
 }
 
 type authUsecase struct {
-	tokenService services.TokenService
+	cfg            *config.AppConfig
+	logger         logger.Logger
+	tokenService   services.TokenService
+	keyRing        services.KeyRing
+	passwordHasher services.PasswordHasher
+	userRepository repository.UserRepository
+
+	refreshTokenService    services.RefreshTokenService
+	refreshTokenRepository repository.RefreshTokenRepository
+	revocationRepository   repository.TokenRevocationRepository
+	oidcVerifier           oidc.Verifier
+	loginThrottle          LoginThrottle
+	mfaRepository          repository.MFARepository
+	totpService            services.TOTPService
+	secretBox              services.SecretBox
+
+	// dummyHash は存在しないユーザーでも同じ時間をかけて検証するためのハッシュです
+	dummyHash string
 }
 
-func NewAuthUsecase(tokenService services.TokenService) AuthUsecase {
+func NewAuthUsecase(
+	cfg *config.AppConfig,
+	logger logger.Logger,
+	tokenService services.TokenService,
+	keyRing services.KeyRing,
+	passwordHasher services.PasswordHasher,
+	userRepository repository.UserRepository,
+	refreshTokenService services.RefreshTokenService,
+	refreshTokenRepository repository.RefreshTokenRepository,
+	revocationRepository repository.TokenRevocationRepository,
+	oidcVerifier oidc.Verifier,
+	loginThrottle LoginThrottle,
+	mfaRepository repository.MFARepository,
+	totpService services.TOTPService,
+	secretBox services.SecretBox,
+) (AuthUsecase, error) {
+	// ハッシュがないと存在しないユーザーの検証だけが速く終わるため、起動を失敗させる
+	dummyHash, err := passwordHasher.Hash("dummy-password")
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
 	return &authUsecase{
-		tokenService: tokenService,
+		cfg:            cfg,
+		logger:         logger,
+		tokenService:   tokenService,
+		keyRing:        keyRing,
+		passwordHasher: passwordHasher,
+		userRepository: userRepository,
+
+		refreshTokenService:    refreshTokenService,
+		refreshTokenRepository: refreshTokenRepository,
+		revocationRepository:   revocationRepository,
+		oidcVerifier:           oidcVerifier,
+		loginThrottle:          loginThrottle,
+		mfaRepository:          mfaRepository,
+		totpService:            totpService,
+		secretBox:              secretBox,
+		dummyHash:              dummyHash,
+	}, nil
+}
+
+// Login は認証情報を検証し、ストアに登録されたロールでトークンを発行します
+// ユーザーの有無を推測されないよう、失敗理由にかかわらず同じ 401 を返します
+// 失敗が続いた場合は、パスワードを検証せずに Retry-After 付きの 429 を返します
+func (uc *authUsecase) Login(ctx context.Context, userID, password, clientIP string) (*models.LoginResult, error) {
+	attempt, err := uc.loginThrottle.Check(ctx, userID, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	defer uc.releaseLoginAttempt(ctx, attempt)
+
+	user, err := uc.verifyCredentials(ctx, userID, password)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeUnauthorized {
+			uc.recordLoginFailure(ctx, attempt)
+		}
+		return nil, err
+	}
+
+	required, enrolled, err := uc.mfaStatus(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		// 失敗の記録は MFA のコードの確認まで残し、パスワードの入力で MFA の失敗を打ち消せないようにする
+		// この試行の予約は defer で取り消す
+		return uc.startMFAChallenge(ctx, user, enrolled)
+	}
+
+	uc.recordLoginSuccess(ctx, attempt)
+	tokens, err := uc.issueTokens(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResult{Tokens: tokens}, nil
+}
+
+func (uc *authUsecase) recordLoginFailure(ctx context.Context, attempt *LoginAttempt) {
+	if err := uc.loginThrottle.RecordFailure(ctx, attempt); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to record login failure", "user_id", attempt.userID, "error", err)
+	}
+}
+
+func (uc *authUsecase) recordLoginSuccess(ctx context.Context, attempt *LoginAttempt) {
+	if err := uc.loginThrottle.RecordSuccess(ctx, attempt); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to reset login failures", "user_id", attempt.userID, "error", err)
+	}
+}
+
+// releaseLoginAttempt は確定しなかった試行の予約を取り消します
+func (uc *authUsecase) releaseLoginAttempt(ctx context.Context, attempt *LoginAttempt) {
+	if err := uc.loginThrottle.Release(ctx, attempt); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to release login attempt", "user_id", attempt.userID, "error", err)
+	}
+}
+
+// Refresh はリフレッシュトークンをローテーションし、アクセストークンを再発行します
+// 使用済みのトークンが再び使われた場合は、漏洩したものとしてファミリーごと失効させます
+func (uc *authUsecase) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	record, err := uc.refreshTokenRepository.Get(ctx, uc.refreshTokenService.Hash(refreshToken))
+	if isNotFoundError(err) {
+		uc.logger.WarnContext(ctx, "Unknown refresh token")
+		return nil, services.InvalidRefreshTokenError()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.refreshTokenService.IsReused(record) {
+		return nil, uc.revokeFamily(ctx, record)
+	}
+	if err := uc.refreshTokenService.Validate(record); err != nil {
+		uc.logger.WarnContext(ctx, "Refresh token rejected", "user_id", record.UserID, "revoked", record.RevokedAt != nil)
+		return nil, err
 	}
+
+	// 同時に同じトークンが使われた場合、使用済みにできなかった側は再利用として扱う
+	marked, err := uc.refreshTokenRepository.MarkUsed(ctx, record.TokenHash, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, uc.revokeFamily(ctx, record)
+	}
+
+	// ロールの変更を反映するため、ユーザーは毎回ストアから読み直す
+	user, err := uc.userRepository.Get(ctx, record.UserID)
+	if isNotFoundError(err) {
+		uc.logger.WarnContext(ctx, "Refresh token for unknown user", "user_id", record.UserID)
+		return nil, services.InvalidRefreshTokenError()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.issueTokens(ctx, user, record.FamilyID)
+}
+
+func (uc *authUsecase) revokeFamily(ctx context.Context, record *models.RefreshToken) error {
+	uc.logger.WarnContext(ctx, "Refresh token reuse detected; revoking token family", "user_id", record.UserID)
+	if err := uc.refreshTokenRepository.RevokeFamily(ctx, record.FamilyID, time.Now()); err != nil {
+		return err
+	}
+	return services.InvalidRefreshTokenError()
 }
 
-func (uc *authUsecase) Login(ctx context.Context, userID string, roles []string) (string, error) {
-	return uc.tokenService.GenerateToken(ctx, userID, roles)
+// issueTokens はアクセストークンと、familyID のファミリーに属するリフレッシュトークンを発行します
+func (uc *authUsecase) issueTokens(ctx context.Context, user *models.User, familyID string) (*models.TokenPair, error) {
+	accessToken, err := uc.tokenService.GenerateToken(ctx, user.ID, user.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, record, err := uc.refreshTokenService.Issue(user.ID, familyID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to issue refresh token", err)
+	}
+	if err := uc.refreshTokenRepository.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    uc.cfg.AuthAccessTokenTTL,
+	}, nil
+}
+
+func (uc *authUsecase) verifyCredentials(ctx context.Context, userID, password string) (*models.User, error) {
+	user, err := uc.userRepository.Get(ctx, userID)
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+
+	hash := uc.dummyHash
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	ok, verifyErr := uc.passwordHasher.Verify(password, hash)
+	if verifyErr != nil {
+		uc.logger.ErrorContext(ctx, "Failed to verify password hash", "user_id", userID, "error", verifyErr)
+	}
+	if user == nil || !ok {
+		uc.logger.WarnContext(ctx, "Login failed", "user_id", userID, "user_exists", user != nil)
+		return nil, invalidCredentialsError()
+	}
+	// メール未確認であることもクライアントには区別させない
+	if uc.cfg.AuthRequireVerifiedEmail && !user.EmailVerified {
+		uc.logger.WarnContext(ctx, "Login rejected: email not verified", "user_id", userID)
+		return nil, invalidCredentialsError()
+	}
+	return user, nil
+}
+
+func invalidCredentialsError() error {
+	return apperrors.NewUnauthorizedError("Invalid user ID or password", nil)
 }
 
 func (uc *authUsecase) Authenticate(ctx context.Context, tokenString string) (*models.User, error) {
-	claims, err := uc.tokenService.ValidateToken(ctx, tokenString)
+	// 設定された IdP が発行したトークンは IdP の鍵で検証し、それ以外は自身が発行したトークンとして検証する
+	identity, err := uc.oidcVerifier.Verify(ctx, tokenString)
+	if err == nil {
+		if err := uc.checkRevoked(ctx, identity.TokenID, identity.User.ID, identity.IssuedAt); err != nil {
+			return nil, err
+		}
+		return identity.User, nil
+	}
+	if !errors.Is(err, oidc.ErrUnknownIssuer) {
+		return nil, err
+	}
+
+	claims, err := uc.validateAccessToken(ctx, tokenString)
 	if err != nil {
 		return nil, err
 	}
-	return &models.User{
+	user := &models.User{
 		ID:    claims.UserID,
 		Roles: claims.Roles,
-	}, nil
+	}
+	if claims.ClientID != "" {
+		// クライアントのトークンはスコープの範囲に限定する。スコープがない場合も空のスライスにして何も許可しない
+		user.Scopes = append([]string{}, strings.Fields(claims.Scope)...)
+	}
+	return user, nil
+}
+
+// validateAccessToken は署名と有効期限に加えて、失効していないことを検証します
+func (uc *authUsecase) validateAccessToken(ctx context.Context, tokenString string) (*models.Claims, error) {
+	claims, err := uc.tokenService.ValidateToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	if err := uc.checkRevoked(ctx, claims.ID, claims.UserID, issuedAt); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkRevoked はトークン単位とユーザー単位の失効を確認します
+func (uc *authUsecase) checkRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) error {
+	revoked, err := uc.revocationRepository.IsRevoked(ctx, jti, userID, issuedAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return &services.TokenValidationError{Reason: services.TokenFailureRevoked}
+	}
+	return nil
+}
+
+func (uc *authUsecase) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	claims, err := uc.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		// jti を持たない古いトークンは個別に失効できないため、有効期限まで使える
+		uc.logger.WarnContext(ctx, "Access token without jti cannot be revoked", "user_id", claims.UserID)
+	} else if err := uc.revocationRepository.RevokeToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if refreshToken != "" {
+		record, err := uc.refreshTokenRepository.Get(ctx, uc.refreshTokenService.Hash(refreshToken))
+		if err != nil && !isNotFoundError(err) {
+			return err
+		}
+		// 他人のリフレッシュトークンは失効させない
+		if record != nil && record.UserID == claims.UserID {
+			if err := uc.refreshTokenRepository.RevokeFamily(ctx, record.FamilyID, time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+
+	uc.logger.InfoContext(ctx, "User logged out", "user_id", claims.UserID)
+	return nil
+}
+
+func (uc *authUsecase) RevokeUserTokens(ctx context.Context, actor *models.User, userID string) error {
+	if err := uc.requireAdmin(actor); err != nil {
+		uc.logger.WarnContext(ctx, "Token revocation denied", "target_user_id", userID)
+		return err
+	}
+	// IdP のユーザーはストアにいないため、存在を確認せずに失効させる
+	if !oidc.IsUserID(userID) {
+		if _, err := uc.userRepository.Get(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	// アクセストークンは最長でも AuthAccessTokenTTL で期限切れになるため、それまで失効を保持する
+	now := time.Now()
+	if err := uc.revocationRepository.RevokeUser(ctx, userID, now, now.Add(uc.cfg.AuthAccessTokenTTL)); err != nil {
+		return err
+	}
+	if err := uc.refreshTokenRepository.RevokeByUser(ctx, userID, now); err != nil {
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "All tokens revoked for user", "target_user_id", userID, "actor_user_id", actor.ID)
+	return nil
+}
+
+func (uc *authUsecase) UnlockUser(ctx context.Context, actor *models.User, userID string) error {
+	if err := uc.requireAdmin(actor); err != nil {
+		uc.logger.WarnContext(ctx, "Account unlock denied", "target_user_id", userID)
+		return err
+	}
+	if _, err := uc.userRepository.Get(ctx, userID); err != nil {
+		return err
+	}
+
+	unlocked, err := uc.loginThrottle.Unlock(ctx, userID)
+	if err != nil {
+		return err
+	}
+	uc.logger.With("log_type", "audit").InfoContext(ctx, "Account unlocked",
+		"event", "account_unlocked", "target_user_id", userID, "actor_user_id", actor.ID, "was_locked", unlocked)
+	return nil
+}
+
+// requireAdmin は管理者のロールを持つユーザーのセッションかを確認します
+// API キーやクライアントのトークンなど、スコープで制限されたプリンシパルは管理者のロールを持っていても拒否します
+func (uc *authUsecase) requireAdmin(actor *models.User) error {
+	if actor == nil || actor.Scopes != nil || !hasAnyRole(actor.Roles, uc.cfg.AuthAdminRoles) {
+		return apperrors.NewForbiddenError("Admin role required", nil)
+	}
+	return nil
+}
+
+func (uc *authUsecase) JWKS(_ context.Context) models.JWKS {
+	return uc.keyRing.JWKS()
+}
+
+func hasAnyRole(roles, allowed []string) bool {
+	for _, role := range roles {
+		for _, a := range allowed {
+			if role == a {
+				return true
+			}
+		}
+	}
+	return false
 }
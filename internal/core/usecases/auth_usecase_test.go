@@ -0,0 +1,573 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/oidc"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/mocks/mockservice"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthUsecase_Login(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hasher := services.NewPasswordHasher()
+	hash, err := hasher.Hash("s3cret")
+	require.NoError(t, err)
+
+	users := repository.NewMemoryUserRepository()
+	require.NoError(t, users.Create(ctx, &models.User{
+		ID:           "user123",
+		Roles:        []string{"role:teamA:editor"},
+		PasswordHash: hash,
+	}))
+
+	cfg := &config.AppConfig{AuthAccessTokenTTL: 15 * time.Minute, AuthRefreshTokenTTL: time.Hour}
+	mockTokenService := mockservice.NewMockTokenService(ctrl)
+	target, err := NewAuthUsecase(cfg, logger.NewLogger(&config.AppConfig{}), mockTokenService, mustKeyRing(t, cfg), hasher, users,
+		services.NewRefreshTokenService(cfg), repository.NewMemoryRefreshTokenRepository(), repository.NewMemoryTokenRevocationRepository(), mustOIDCVerifier(t), newLoginThrottle(cfg),
+		repository.NewMemoryMFARepository(), services.NewTOTPService(cfg), mustSecretBox(t))
+	require.NoError(t, err)
+
+	t.Run("正常系: ストアのロールでトークンが発行される", func(t *testing.T) {
+		mockTokenService.EXPECT().
+			GenerateToken(ctx, "user123", []string{"role:teamA:editor"}).
+			Return("jwt", nil)
+
+		result, err := target.Login(ctx, "user123", "s3cret", "")
+
+		require.NoError(t, err)
+		tokens := result.Tokens
+		assert.Equal(t, "jwt", tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, 15*time.Minute, tokens.ExpiresIn)
+	})
+
+	t.Run("異常系: パスワード誤りとユーザー不在は同じエラーになる", func(t *testing.T) {
+		_, wrongPassword := target.Login(ctx, "user123", "wrong", "")
+		_, unknownUser := target.Login(ctx, "unknown", "s3cret", "")
+
+		for _, err := range []error{wrongPassword, unknownUser} {
+			var appErr *apperrors.AppError
+			require.True(t, errors.As(err, &appErr))
+			assert.Equal(t, apperrors.ErrorTypeUnauthorized, appErr.Type)
+		}
+		assert.Equal(t, wrongPassword.Error(), unknownUser.Error())
+	})
+}
+
+func TestAuthUsecase_Refresh(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hasher := services.NewPasswordHasher()
+	hash, err := hasher.Hash("s3cret")
+	require.NoError(t, err)
+
+	users := repository.NewMemoryUserRepository()
+	require.NoError(t, users.Create(ctx, &models.User{
+		ID:           "user123",
+		Roles:        []string{"role:teamA:editor"},
+		PasswordHash: hash,
+	}))
+
+	cfg := &config.AppConfig{AuthAccessTokenTTL: 15 * time.Minute, AuthRefreshTokenTTL: time.Hour}
+	mockTokenService := mockservice.NewMockTokenService(ctrl)
+	mockTokenService.EXPECT().GenerateToken(gomock.Any(), "user123", gomock.Any()).Return("jwt", nil).AnyTimes()
+
+	setup := func() (AuthUsecase, repository.RefreshTokenRepository) {
+		refreshTokens := repository.NewMemoryRefreshTokenRepository()
+		target, err := NewAuthUsecase(cfg, logger.NewLogger(&config.AppConfig{}), mockTokenService, mustKeyRing(t, cfg), hasher, users,
+			services.NewRefreshTokenService(cfg), refreshTokens, repository.NewMemoryTokenRevocationRepository(), mustOIDCVerifier(t), newLoginThrottle(cfg),
+			repository.NewMemoryMFARepository(), services.NewTOTPService(cfg), mustSecretBox(t))
+		require.NoError(t, err)
+		return target, refreshTokens
+	}
+
+	t.Run("正常系: リフレッシュのたびに新しいトークンにローテーションされる", func(t *testing.T) {
+		target, _ := setup()
+		login, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+
+		first, err := target.Refresh(ctx, login.Tokens.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, login.Tokens.RefreshToken, first.RefreshToken)
+
+		second, err := target.Refresh(ctx, first.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, "jwt", second.AccessToken)
+	})
+
+	t.Run("異常系: 使用済みのトークンを再利用するとファミリーごと失効する", func(t *testing.T) {
+		target, _ := setup()
+		login, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+		rotated, err := target.Refresh(ctx, login.Tokens.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = target.Refresh(ctx, login.Tokens.RefreshToken)
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+
+		// 正規の利用者が持つ最新のトークンも使えなくなる
+		_, err = target.Refresh(ctx, rotated.RefreshToken)
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+	})
+
+	t.Run("正常系: 別のログインのファミリーは再利用の影響を受けない", func(t *testing.T) {
+		target, _ := setup()
+		leaked, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+		other, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+		_, err = target.Refresh(ctx, leaked.Tokens.RefreshToken)
+		require.NoError(t, err)
+		_, err = target.Refresh(ctx, leaked.Tokens.RefreshToken)
+		require.Error(t, err)
+
+		_, err = target.Refresh(ctx, other.Tokens.RefreshToken)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("異常系: 期限切れ・未知のトークンは 401", func(t *testing.T) {
+		target, refreshTokens := setup()
+		require.NoError(t, refreshTokens.Create(ctx, &models.RefreshToken{
+			TokenHash: services.NewRefreshTokenService(cfg).Hash("expired"),
+			FamilyID:  "family",
+			UserID:    "user123",
+			ExpiresAt: time.Now().Add(-time.Minute),
+			CreatedAt: time.Now().Add(-time.Hour),
+		}))
+
+		_, expiredErr := target.Refresh(ctx, "expired")
+		_, unknownErr := target.Refresh(ctx, "unknown")
+
+		assertAppErrorType(t, expiredErr, apperrors.ErrorTypeUnauthorized)
+		assertAppErrorType(t, unknownErr, apperrors.ErrorTypeUnauthorized)
+	})
+}
+
+func TestAuthUsecase_Revocation(t *testing.T) {
+	ctx := context.Background()
+
+	hasher := services.NewPasswordHasher()
+	hash, err := hasher.Hash("s3cret")
+	require.NoError(t, err)
+
+	users := repository.NewMemoryUserRepository()
+	for _, u := range []*models.User{
+		{ID: "user123", Roles: []string{"role:teamA:editor"}, PasswordHash: hash},
+		{ID: "admin", Roles: []string{"role:system:admin"}, PasswordHash: hash},
+	} {
+		require.NoError(t, users.Create(ctx, u))
+	}
+
+	cfg := &config.AppConfig{
+		JWTSecretKey:        "secret",
+		JWTIssuer:           "issuer",
+		JWTAudience:         "audience",
+		AuthAccessTokenTTL:  15 * time.Minute,
+		AuthRefreshTokenTTL: time.Hour,
+		AuthAdminRoles:      []string{"role:system:admin"},
+	}
+	keyRing := mustKeyRing(t, cfg)
+	setupWithVerifier := func(verifier oidc.Verifier) AuthUsecase {
+		target, err := NewAuthUsecase(cfg, logger.NewLogger(&config.AppConfig{}), services.NewTokenService(cfg, keyRing), keyRing, hasher, users,
+			services.NewRefreshTokenService(cfg), repository.NewMemoryRefreshTokenRepository(), repository.NewMemoryTokenRevocationRepository(), verifier, newLoginThrottle(cfg),
+			repository.NewMemoryMFARepository(), services.NewTOTPService(cfg), mustSecretBox(t))
+		require.NoError(t, err)
+		return target
+	}
+	setup := func() AuthUsecase {
+		return setupWithVerifier(mustOIDCVerifier(t))
+	}
+
+	t.Run("正常系: ログアウトしたアクセストークンとリフレッシュトークンは使えない", func(t *testing.T) {
+		target := setup()
+		result, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+		other, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+		tokens := result.Tokens
+
+		require.NoError(t, target.Logout(ctx, tokens.AccessToken, tokens.RefreshToken))
+
+		_, err = target.Authenticate(ctx, tokens.AccessToken)
+		assert.Equal(t, services.TokenFailureRevoked, services.TokenFailureReason(err))
+		_, err = target.Refresh(ctx, tokens.RefreshToken)
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+
+		// 別のログインのトークンは影響を受けない
+		_, err = target.Authenticate(ctx, other.Tokens.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("正常系: 管理者はユーザーのトークンをすべて失効できる", func(t *testing.T) {
+		target := setup()
+		first, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+		second, err := target.Login(ctx, "user123", "s3cret", "")
+		require.NoError(t, err)
+
+		require.NoError(t, target.RevokeUserTokens(ctx, &models.User{ID: "admin", Roles: []string{"role:system:admin"}}, "user123"))
+
+		for _, tokens := range []*models.TokenPair{first.Tokens, second.Tokens} {
+			_, err = target.Authenticate(ctx, tokens.AccessToken)
+			assert.Equal(t, services.TokenFailureRevoked, services.TokenFailureReason(err))
+			_, err = target.Refresh(ctx, tokens.RefreshToken)
+			assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+		}
+	})
+
+	t.Run("正常系: 管理者は IdP のユーザーのトークンも失効できる", func(t *testing.T) {
+		userID := oidc.UserID("https://idp.example.com", "alice")
+		target := setupWithVerifier(&stubOIDCVerifier{token: "idp-token", identity: &oidc.Identity{
+			User:     &models.User{ID: userID},
+			TokenID:  "idp-jti",
+			IssuedAt: time.Now().Add(-time.Minute),
+		}})
+		_, err := target.Authenticate(ctx, "idp-token")
+		require.NoError(t, err)
+
+		require.NoError(t, target.RevokeUserTokens(ctx, &models.User{ID: "admin", Roles: []string{"role:system:admin"}}, userID))
+
+		_, err = target.Authenticate(ctx, "idp-token")
+		assert.Equal(t, services.TokenFailureRevoked, services.TokenFailureReason(err))
+	})
+
+	t.Run("正常系: クライアントのトークンはスコープで制限されたユーザーとして認証する", func(t *testing.T) {
+		target := setup()
+		tokenString, err := services.NewTokenService(cfg, keyRing).GenerateClientToken(ctx, "batch", []string{"role:teamA:viewer"}, nil)
+		require.NoError(t, err)
+
+		user, err := target.Authenticate(ctx, tokenString)
+
+		require.NoError(t, err)
+		assert.Equal(t, "client:batch", user.ID)
+		assert.NotNil(t, user.Scopes, "スコープがないクライアントも何も許可しないよう空のスコープを持つ")
+		assert.Empty(t, user.Scopes)
+	})
+
+	t.Run("異常系: 管理者以外はトークンを失効できない", func(t *testing.T) {
+		target := setup()
+
+		err := target.RevokeUserTokens(ctx, &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}}, "admin")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+	})
+
+	t.Run("異常系: 管理者のロールを持つ API キーやクライアントのトークンでも失効できない", func(t *testing.T) {
+		target := setup()
+
+		for _, actor := range []*models.User{
+			{ID: "admin", Roles: []string{"role:system:admin"}, Scopes: []string{"*"}},
+			{ID: "client:batch", Roles: []string{"role:system:admin"}, Scopes: []string{}},
+		} {
+			err := target.RevokeUserTokens(ctx, actor, "user123")
+			assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+			assertAppErrorType(t, target.UnlockUser(ctx, actor, "user123"), apperrors.ErrorTypeForbidden)
+		}
+	})
+}
+
+func TestAuthUsecase_Lockout(t *testing.T) {
+	ctx := context.Background()
+
+	hasher := services.NewPasswordHasher()
+	hash, err := hasher.Hash("s3cret")
+	require.NoError(t, err)
+	users := repository.NewMemoryUserRepository()
+	require.NoError(t, users.Create(ctx, &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}, PasswordHash: hash}))
+
+	cfg := &config.AppConfig{
+		JWTSecretKey:           "secret",
+		JWTIssuer:              "issuer",
+		JWTAudience:            "audience",
+		AuthAccessTokenTTL:     15 * time.Minute,
+		AuthRefreshTokenTTL:    time.Hour,
+		AuthAdminRoles:         []string{"role:system:admin"},
+		AuthLoginFailureWindow: 15 * time.Minute,
+		AuthLockoutThreshold:   3,
+		AuthLockoutDuration:    15 * time.Minute,
+	}
+	keyRing := mustKeyRing(t, cfg)
+	target, err := NewAuthUsecase(cfg, logger.NewLogger(&config.AppConfig{}), services.NewTokenService(cfg, keyRing), keyRing, hasher, users,
+		services.NewRefreshTokenService(cfg), repository.NewMemoryRefreshTokenRepository(), repository.NewMemoryTokenRevocationRepository(), mustOIDCVerifier(t), newLoginThrottle(cfg),
+		repository.NewMemoryMFARepository(), services.NewTOTPService(cfg), mustSecretBox(t))
+	require.NoError(t, err)
+
+	for range cfg.AuthLockoutThreshold {
+		_, err := target.Login(ctx, "user123", "wrong", "192.0.2.1")
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+	}
+
+	t.Run("異常系: ロック中は正しいパスワードでも Retry-After 付きの 429", func(t *testing.T) {
+		_, err := target.Login(ctx, "user123", "s3cret", "192.0.2.1")
+
+		var appErr *apperrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, apperrors.ErrorTypeRateLimit, appErr.Type)
+		assert.Greater(t, appErr.RetryAfter, 14*time.Minute)
+	})
+
+	t.Run("異常系: 管理者以外はロックを解除できない", func(t *testing.T) {
+		err := target.UnlockUser(ctx, &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}}, "user123")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+	})
+
+	t.Run("正常系: 管理者がロックを解除するとログインできる", func(t *testing.T) {
+		require.NoError(t, target.UnlockUser(ctx, &models.User{ID: "admin", Roles: []string{"role:system:admin"}}, "user123"))
+
+		_, err := target.Login(ctx, "user123", "s3cret", "192.0.2.1")
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestAuthUsecase_MFA(t *testing.T) {
+	ctx := context.Background()
+
+	hasher := services.NewPasswordHasher()
+	hash, err := hasher.Hash("s3cret")
+	require.NoError(t, err)
+	users := repository.NewMemoryUserRepository()
+	for _, u := range []*models.User{
+		{ID: "alice", Roles: []string{"role:teamA:editor"}, PasswordHash: hash},
+		{ID: "bob", Roles: []string{"role:teamA:editor"}, PasswordHash: hash},
+		{ID: "carol", Roles: []string{"role:teamA:editor"}, PasswordHash: hash},
+		{ID: "dave", Roles: []string{"role:teamA:editor"}, PasswordHash: hash},
+		{ID: "erin", Roles: []string{"role:teamA:editor"}, PasswordHash: hash},
+		{ID: "admin", Roles: []string{"role:system:admin"}, PasswordHash: hash},
+	} {
+		require.NoError(t, users.Create(ctx, u))
+	}
+
+	cfg := &config.AppConfig{
+		JWTSecretKey:         "secret",
+		JWTIssuer:            "issuer",
+		JWTAudience:          "audience",
+		AuthAccessTokenTTL:   15 * time.Minute,
+		AuthRefreshTokenTTL:  time.Hour,
+		AuthMFAIssuer:        "issuer",
+		AuthMFAChallengeTTL:  5 * time.Minute,
+		AuthMFARequiredRoles: []string{"role:system:admin"},
+	}
+	keyRing := mustKeyRing(t, cfg)
+	totp := services.NewTOTPService(cfg)
+	mfaRepository := repository.NewMemoryMFARepository()
+	target, err := NewAuthUsecase(cfg, logger.NewLogger(&config.AppConfig{}), services.NewTokenService(cfg, keyRing), keyRing, hasher, users,
+		services.NewRefreshTokenService(cfg), repository.NewMemoryRefreshTokenRepository(), repository.NewMemoryTokenRevocationRepository(), mustOIDCVerifier(t), newLoginThrottle(cfg),
+		mfaRepository, totp, mustSecretBox(t))
+	require.NoError(t, err)
+
+	code := func(secret string, offset time.Duration) string {
+		c, err := totp.Code(secret, time.Now().Add(offset))
+		require.NoError(t, err)
+		return c
+	}
+	enroll := func(userID string) *models.MFASetup {
+		setup, err := target.EnrollMFA(ctx, &models.User{ID: userID})
+		require.NoError(t, err)
+		require.NoError(t, target.ConfirmMFA(ctx, &models.User{ID: userID}, code(setup.Secret, 0)))
+		return setup
+	}
+	challenge := func(userID string) string {
+		result, err := target.Login(ctx, userID, "s3cret", "")
+		require.NoError(t, err)
+		require.Nil(t, result.Tokens)
+		require.NotEmpty(t, result.MFAChallenge)
+		return result.MFAChallenge
+	}
+
+	t.Run("正常系: 登録を確認するまではパスワードだけでログインできる", func(t *testing.T) {
+		setup, err := target.EnrollMFA(ctx, &models.User{ID: "alice"})
+		require.NoError(t, err)
+		assert.Contains(t, setup.OTPAuthURI, "otpauth://totp/issuer:alice?")
+		assert.Len(t, setup.RecoveryCodes, 10)
+
+		result, err := target.Login(ctx, "alice", "s3cret", "")
+
+		require.NoError(t, err)
+		assert.NotNil(t, result.Tokens)
+	})
+
+	t.Run("正常系: シークレットは暗号化して保存する", func(t *testing.T) {
+		setup, err := target.EnrollMFA(ctx, &models.User{ID: "alice"})
+		require.NoError(t, err)
+
+		enrollment, err := mfaRepository.Get(ctx, "alice")
+
+		require.NoError(t, err)
+		assert.NotContains(t, enrollment.SecretCiphertext, setup.Secret)
+		assert.NotContains(t, enrollment.RecoveryCodeHashes, setup.RecoveryCodes[0])
+	})
+
+	t.Run("正常系: 登録したユーザーはパスワードの後にコードでトークンを得る", func(t *testing.T) {
+		setup := enroll("alice")
+		mfaToken := challenge("alice")
+
+		// チャレンジはアクセストークンとして使えない
+		_, err := target.Authenticate(ctx, mfaToken)
+		assert.Equal(t, services.TokenFailureWrongPurpose, services.TokenFailureReason(err))
+
+		tokens, err := target.VerifyMFA(ctx, mfaToken, code(setup.Secret, 30*time.Second), "")
+		require.NoError(t, err)
+		user, err := target.Authenticate(ctx, tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.ID)
+	})
+
+	t.Run("異常系: 使用済みのコードとチャレンジは再利用できない", func(t *testing.T) {
+		setup := enroll("bob")
+		mfaToken := challenge("bob")
+		used := code(setup.Secret, 30*time.Second)
+		_, err := target.VerifyMFA(ctx, mfaToken, used, "")
+		require.NoError(t, err)
+
+		_, err = target.VerifyMFA(ctx, mfaToken, used, "")
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+
+		_, err = target.VerifyMFA(ctx, challenge("bob"), used, "")
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+	})
+
+	t.Run("正常系: リカバリーコードは一度だけ使える", func(t *testing.T) {
+		setup := enroll("carol")
+
+		_, err := target.VerifyMFA(ctx, challenge("carol"), setup.RecoveryCodes[0], "")
+		require.NoError(t, err)
+
+		_, err = target.VerifyMFA(ctx, challenge("carol"), setup.RecoveryCodes[0], "")
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+	})
+
+	t.Run("異常系: 同じチャレンジに並行して別々のコードを送っても、トークンを得られるのは 1 つだけ", func(t *testing.T) {
+		setup := enroll("erin")
+		mfaToken := challenge("erin")
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i, c := range []string{code(setup.Secret, 30*time.Second), setup.RecoveryCodes[0]} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = target.VerifyMFA(ctx, mfaToken, c, "")
+			}()
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+			} else {
+				assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("正常系: MFA が必須のロールは未登録でもチャレンジを返し、チャレンジで登録してログインする", func(t *testing.T) {
+		result, err := target.Login(ctx, "admin", "s3cret", "")
+		require.NoError(t, err)
+		assert.Nil(t, result.Tokens)
+		assert.True(t, result.MFAEnrollmentRequired)
+
+		_, err = target.VerifyMFA(ctx, result.MFAChallenge, "123456", "")
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+
+		setup, err := target.EnrollMFAWithChallenge(ctx, result.MFAChallenge)
+		require.NoError(t, err)
+		tokens, err := target.VerifyMFA(ctx, result.MFAChallenge, code(setup.Secret, 0), "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+
+		enrollment, err := mfaRepository.Get(ctx, "admin")
+		require.NoError(t, err)
+		assert.NotNil(t, enrollment.ConfirmedAt)
+		_, err = target.EnrollMFAWithChallenge(ctx, challenge("admin"))
+		assertAppErrorType(t, err, apperrors.ErrorTypeConflict)
+	})
+
+	t.Run("異常系: MFA が必須のロールは登録を削除できない", func(t *testing.T) {
+		err := target.DisableMFA(ctx, &models.User{ID: "admin"}, "123456")
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+	})
+
+	t.Run("正常系: コードを確認して登録を削除するとパスワードだけでログインできる", func(t *testing.T) {
+		setup := enroll("dave")
+		err := target.DisableMFA(ctx, &models.User{ID: "dave"}, "000000")
+		assertAppErrorType(t, err, apperrors.ErrorTypeUnauthorized)
+
+		require.NoError(t, target.DisableMFA(ctx, &models.User{ID: "dave"}, setup.RecoveryCodes[1]))
+
+		result, err := target.Login(ctx, "dave", "s3cret", "")
+		require.NoError(t, err)
+		assert.NotNil(t, result.Tokens)
+	})
+
+	t.Run("異常系: スコープで制限されたプリンシパルは登録できない", func(t *testing.T) {
+		_, err := target.EnrollMFA(ctx, &models.User{ID: "alice", Scopes: []string{"samples:read"}})
+
+		assertAppErrorType(t, err, apperrors.ErrorTypeForbidden)
+	})
+}
+
+func mustKeyRing(t *testing.T, cfg *config.AppConfig) services.KeyRing {
+	t.Helper()
+	keyRing, err := services.NewKeyRing(cfg)
+	require.NoError(t, err)
+	return keyRing
+}
+
+// mustOIDCVerifier は外部 IdP を設定しない Verifier を返します
+func mustOIDCVerifier(t *testing.T) oidc.Verifier {
+	t.Helper()
+	verifier, cleanup, err := oidc.NewVerifier(&config.AppConfig{}, logger.NewLogger(&config.AppConfig{}))
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	return verifier
+}
+
+// stubOIDCVerifier は token だけを IdP のトークンとして受け付けます
+type stubOIDCVerifier struct {
+	token    string
+	identity *oidc.Identity
+}
+
+func (v *stubOIDCVerifier) Verify(_ context.Context, tokenString string) (*oidc.Identity, error) {
+	if tokenString != v.token {
+		return nil, oidc.ErrUnknownIssuer
+	}
+	return v.identity, nil
+}
+
+// mustSecretBox はテスト用の固定の鍵の SecretBox を返します
+func mustSecretBox(t *testing.T) services.SecretBox {
+	t.Helper()
+	box, err := services.NewSecretBox(&config.AppConfig{AuthMFAEncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	require.NoError(t, err)
+	return box
+}
+
+// newLoginThrottle はメモリのストアを使う LoginThrottle を返します
+func newLoginThrottle(cfg *config.AppConfig) LoginThrottle {
+	return NewLoginThrottle(cfg, logger.NewLogger(&config.AppConfig{}), repository.NewMemoryLoginAttemptRepository())
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/resilience"
+)
+
+type HealthcheckUsecase interface {
+	// Readiness は依存先ごとの状態と、トラフィックを受け付け可能かどうかを返します
+	Readiness(ctx context.Context) (map[string]string, bool)
+}
+
+type healthcheckUsecase struct {
+	piyoBreaker *piyographql.CircuitBreaker
+}
+
+func NewHealthcheckUsecase(piyoBreaker *piyographql.CircuitBreaker) HealthcheckUsecase {
+	return &healthcheckUsecase{
+		piyoBreaker: piyoBreaker,
+	}
+}
+
+func (uc *healthcheckUsecase) Readiness(_ context.Context) (map[string]string, bool) {
+	state := uc.piyoBreaker.State()
+	components := map[string]string{
+		uc.piyoBreaker.Name(): state.String(),
+	}
+	return components, state != resilience.StateOpen
+}
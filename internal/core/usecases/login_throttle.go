@@ -0,0 +1,245 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// LoginThrottle はログインの総当たりを防ぎます
+//   - ユーザー ID ごとに auth_login_failure_window 内の失敗を数え、auth_login_delay_after 回目から次の試行までの待機時間を倍々に延ばす
+//   - auth_lockout_threshold 回失敗したユーザー ID は auth_lockout_duration の間ロックする
+//   - IP アドレスごとの失敗が auth_login_ip_max_failures に達した場合は、ウィンドウ内の失敗が減るまで拒否する
+//
+// 並行した試行で制限をすり抜けられないよう、Check は検証の前に試行を失敗として記録（予約）します
+// 予約した試行は RecordFailure か RecordSuccess で確定し、どちらも呼ばなかった場合は Release で取り消します
+// ユーザーの有無を推測されないよう、存在しないユーザー ID も同じように数えます
+type LoginThrottle interface {
+	// Check はログインの試行を予約し、試行できるかを検証します。試行できない場合は予約を取り消し、Retry-After 付きの 429 を返します
+	Check(ctx context.Context, userID, clientIP string) (*LoginAttempt, error)
+	// RecordFailure は予約した試行を失敗として確定し、しきい値に達したユーザー ID をロックします
+	RecordFailure(ctx context.Context, attempt *LoginAttempt) error
+	// RecordSuccess は予約した試行を取り消し、ユーザー ID の失敗の記録を消去します
+	RecordSuccess(ctx context.Context, attempt *LoginAttempt) error
+	// Release は確定していない試行の予約を取り消します。確定した試行や nil では何もしません
+	Release(ctx context.Context, attempt *LoginAttempt) error
+	// Unlock はユーザー ID のロックと失敗の記録を消去します。ロックされていた場合は true を返します
+	Unlock(ctx context.Context, userID string) (bool, error)
+	// CheckRegistration はユーザー登録の試行を記録し、IP アドレスごとの上限を超えた場合は Retry-After 付きの 429 を返します
+	// 登録は既存のユーザーかどうかにかかわらず同じ応答を返すため、成否にかかわらずすべての試行を数えます
+	CheckRegistration(ctx context.Context, clientIP string) error
+}
+
+// LoginAttempt は Check で予約した試行です
+type LoginAttempt struct {
+	userID   string
+	clientIP string
+	at       time.Time
+	// userKey, ipKey は予約したキーです。予約していない場合は空です
+	userKey string
+	ipKey   string
+	settled bool
+}
+
+type loginThrottle struct {
+	cfg        *config.AppConfig
+	logger     logger.Logger
+	repository repository.LoginAttemptRepository
+	now        func() time.Time
+}
+
+func NewLoginThrottle(
+	cfg *config.AppConfig,
+	logger logger.Logger,
+	repository repository.LoginAttemptRepository,
+) LoginThrottle {
+	return &loginThrottle{
+		cfg:        cfg,
+		logger:     logger.With("log_type", "audit"),
+		repository: repository,
+		now:        time.Now,
+	}
+}
+
+func userAttemptKey(userID string) string       { return "user:" + userID }
+func ipAttemptKey(clientIP string) string       { return "ip:" + clientIP }
+func registerAttemptKey(clientIP string) string { return "register:" + clientIP }
+
+func (t *loginThrottle) Check(ctx context.Context, userID, clientIP string) (*LoginAttempt, error) {
+	// SQL ストアはマイクロ秒で保存するため、予約の取り消しで時刻が一致するよう丸める
+	now := t.now().Truncate(time.Microsecond)
+	since := now.Add(-t.cfg.AuthLoginFailureWindow)
+	attempt := &LoginAttempt{userID: userID, clientIP: clientIP, at: now}
+
+	if t.cfg.AuthLockoutThreshold > 0 {
+		until, err := t.repository.LockedUntil(ctx, userAttemptKey(userID))
+		if err != nil {
+			return nil, err
+		}
+		if !until.IsZero() {
+			t.logger.WarnContext(ctx, "Login rejected: account locked", "user_id", userID, "client_ip", clientIP, "locked_until", until)
+			return nil, lockedError(until.Sub(now))
+		}
+	}
+
+	if t.cfg.AuthLoginIPMaxFailures > 0 && clientIP != "" {
+		key := ipAttemptKey(clientIP)
+		failures, err := t.repository.RecordFailure(ctx, key, now, since)
+		if err != nil {
+			return nil, err
+		}
+		attempt.ipKey = key
+		// 末尾はこの試行の予約
+		if n := len(failures) - 1; n >= t.cfg.AuthLoginIPMaxFailures {
+			// 上限未満に戻るのは、超過分のうち最も新しい失敗がウィンドウから外れたとき
+			retryAt := failures[n-t.cfg.AuthLoginIPMaxFailures].Add(t.cfg.AuthLoginFailureWindow)
+			t.logger.WarnContext(ctx, "Login rejected: too many failures from client IP", "user_id", userID, "client_ip", clientIP, "failures", n)
+			return nil, t.reject(ctx, attempt, apperrors.NewRateLimitError("Too many failed login attempts", nil).WithRetryAfter(retryAt.Sub(now)))
+		}
+	}
+
+	if t.cfg.AuthLoginDelayAfter <= 0 && t.cfg.AuthLockoutThreshold <= 0 {
+		return attempt, nil
+	}
+	key := userAttemptKey(userID)
+	failures, err := t.repository.RecordFailure(ctx, key, now, since)
+	if err != nil {
+		return nil, t.reject(ctx, attempt, err)
+	}
+	attempt.userKey = key
+	n := len(failures) - 1
+
+	// 並行した試行がしきい値に達している場合は、それらの結果を待たずにロックする
+	if t.cfg.AuthLockoutThreshold > 0 && n >= t.cfg.AuthLockoutThreshold {
+		attempt.settled = true
+		if err := t.lock(ctx, attempt, n); err != nil {
+			return nil, err
+		}
+		if attempt.ipKey != "" {
+			if err := t.repository.RemoveFailure(ctx, attempt.ipKey, attempt.at); err != nil {
+				return nil, err
+			}
+		}
+		return nil, lockedError(t.cfg.AuthLockoutDuration)
+	}
+	if t.cfg.AuthLoginDelayAfter > 0 && n >= t.cfg.AuthLoginDelayAfter {
+		retryAt := failures[n-1].Add(t.delay(n))
+		if now.Before(retryAt) {
+			t.logger.WarnContext(ctx, "Login rejected: retried too soon", "user_id", userID, "client_ip", clientIP, "failures", n)
+			return nil, t.reject(ctx, attempt, apperrors.NewRateLimitError("Too many failed login attempts", nil).WithRetryAfter(retryAt.Sub(now)))
+		}
+	}
+	return attempt, nil
+}
+
+// reject は予約を取り消して err を返します
+func (t *loginThrottle) reject(ctx context.Context, attempt *LoginAttempt, err error) error {
+	if releaseErr := t.Release(ctx, attempt); releaseErr != nil {
+		return releaseErr
+	}
+	return err
+}
+
+func lockedError(retryAfter time.Duration) error {
+	return apperrors.NewRateLimitError("Account temporarily locked due to repeated login failures", nil).WithRetryAfter(retryAfter)
+}
+
+// delay は failures 回失敗した後に待たせる時間です。auth_login_delay_after 回目の失敗で auth_login_base_delay になり、以降は倍々に延ばします
+func (t *loginThrottle) delay(failures int) time.Duration {
+	d := t.cfg.AuthLoginBaseDelay
+	for i := t.cfg.AuthLoginDelayAfter; i < failures; i++ {
+		d *= 2
+		if t.cfg.AuthLoginMaxDelay > 0 && d >= t.cfg.AuthLoginMaxDelay {
+			return t.cfg.AuthLoginMaxDelay
+		}
+	}
+	return d
+}
+
+func (t *loginThrottle) RecordFailure(ctx context.Context, attempt *LoginAttempt) error {
+	attempt.settled = true
+	if attempt.userKey == "" || t.cfg.AuthLockoutThreshold <= 0 {
+		return nil
+	}
+
+	failures, err := t.repository.Failures(ctx, attempt.userKey, t.now().Add(-t.cfg.AuthLoginFailureWindow))
+	if err != nil {
+		return err
+	}
+	if len(failures) < t.cfg.AuthLockoutThreshold {
+		return nil
+	}
+	return t.lock(ctx, attempt, len(failures))
+}
+
+// lock はユーザー ID をロックします
+func (t *loginThrottle) lock(ctx context.Context, attempt *LoginAttempt, failures int) error {
+	until := t.now().Add(t.cfg.AuthLockoutDuration)
+	if err := t.repository.Lock(ctx, attempt.userKey, until); err != nil {
+		return err
+	}
+	// ロックの解除後は待機時間を最初からやり直す
+	if err := t.repository.ClearFailures(ctx, attempt.userKey); err != nil {
+		return err
+	}
+	t.logger.WarnContext(ctx, "Account locked due to repeated login failures",
+		"event", "account_locked", "user_id", attempt.userID, "client_ip", attempt.clientIP, "failures", failures, "locked_until", until)
+	return nil
+}
+
+func (t *loginThrottle) RecordSuccess(ctx context.Context, attempt *LoginAttempt) error {
+	if err := t.Release(ctx, attempt); err != nil {
+		return err
+	}
+	return t.repository.ClearFailures(ctx, userAttemptKey(attempt.userID))
+}
+
+func (t *loginThrottle) Release(ctx context.Context, attempt *LoginAttempt) error {
+	if attempt == nil || attempt.settled {
+		return nil
+	}
+	attempt.settled = true
+	for _, key := range []string{attempt.ipKey, attempt.userKey} {
+		if key == "" {
+			continue
+		}
+		if err := t.repository.RemoveFailure(ctx, key, attempt.at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *loginThrottle) Unlock(ctx context.Context, userID string) (bool, error) {
+	key := userAttemptKey(userID)
+	unlocked, err := t.repository.Unlock(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if err := t.repository.ClearFailures(ctx, key); err != nil {
+		return false, err
+	}
+	return unlocked, nil
+}
+
+func (t *loginThrottle) CheckRegistration(ctx context.Context, clientIP string) error {
+	if t.cfg.AuthRegisterIPMaxAttempts <= 0 || clientIP == "" {
+		return nil
+	}
+	now := t.now().Truncate(time.Microsecond)
+	attempts, err := t.repository.RecordFailure(ctx, registerAttemptKey(clientIP), now, now.Add(-t.cfg.AuthLoginFailureWindow))
+	if err != nil {
+		return err
+	}
+	if n := len(attempts); n > t.cfg.AuthRegisterIPMaxAttempts {
+		// 上限以下に戻るのは、超過分のうち最も新しい試行がウィンドウから外れたとき
+		retryAt := attempts[n-1-t.cfg.AuthRegisterIPMaxAttempts].Add(t.cfg.AuthLoginFailureWindow)
+		t.logger.WarnContext(ctx, "Registration rejected: too many attempts from client IP", "client_ip", clientIP, "attempts", n)
+		return apperrors.NewRateLimitError("Too many registration attempts", nil).WithRetryAfter(retryAt.Sub(now))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.AppConfig{
+		AuthLoginFailureWindow: 15 * time.Minute,
+		AuthLoginDelayAfter:    2,
+		AuthLoginBaseDelay:     time.Second,
+		AuthLoginMaxDelay:      4 * time.Second,
+		AuthLockoutThreshold:   6,
+		AuthLockoutDuration:    10 * time.Minute,
+		AuthLoginIPMaxFailures: 3,
+	}
+	// ロックの期限はストアが実時間で判定するため、実時間を起点に進める
+	setup := func(cfg *config.AppConfig) (*loginThrottle, *time.Time) {
+		now := time.Now()
+		target := NewLoginThrottle(cfg, logger.NewLogger(&config.AppConfig{}), repository.NewMemoryLoginAttemptRepository()).(*loginThrottle)
+		target.now = func() time.Time { return now }
+		return target, &now
+	}
+	retryAfter := func(t *testing.T, err error) time.Duration {
+		t.Helper()
+		var appErr *apperrors.AppError
+		require.True(t, errors.As(err, &appErr), "expected AppError, got %v", err)
+		assert.Equal(t, apperrors.ErrorTypeRateLimit, appErr.Type)
+		return appErr.RetryAfter
+	}
+
+	// fail は試行を予約して失敗として確定し、check は試行できるかだけを確かめて予約を取り消します
+	fail := func(t *testing.T, target *loginThrottle, userID, clientIP string) {
+		t.Helper()
+		attempt, err := target.Check(ctx, userID, clientIP)
+		require.NoError(t, err)
+		require.NoError(t, target.RecordFailure(ctx, attempt))
+	}
+	check := func(target *loginThrottle, userID, clientIP string) error {
+		attempt, err := target.Check(ctx, userID, clientIP)
+		if err != nil {
+			return err
+		}
+		return target.Release(ctx, attempt)
+	}
+
+	t.Run("正常系: 失敗が続くと次の試行までの待機時間を倍々に延ばし、上限で止める", func(t *testing.T) {
+		target, now := setup(cfg)
+		fail(t, target, "alice", "")
+		require.NoError(t, check(target, "alice", ""), "auth_login_delay_after 未満は待たせない")
+
+		for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+			fail(t, target, "alice", "")
+			assert.Equal(t, want, retryAfter(t, check(target, "alice", "")))
+
+			*now = now.Add(want)
+			require.NoError(t, check(target, "alice", ""))
+		}
+		assert.NoError(t, check(target, "bob", ""), "他のユーザーには影響しない")
+	})
+
+	t.Run("正常系: ウィンドウより前の失敗は数えない", func(t *testing.T) {
+		target, now := setup(cfg)
+		fail(t, target, "alice", "")
+		fail(t, target, "alice", "")
+		require.Error(t, check(target, "alice", ""))
+
+		*now = now.Add(cfg.AuthLoginFailureWindow + time.Second)
+
+		assert.NoError(t, check(target, "alice", ""))
+	})
+
+	t.Run("正常系: 成功すると失敗の記録を消去する", func(t *testing.T) {
+		target, now := setup(cfg)
+		fail(t, target, "alice", "")
+		fail(t, target, "alice", "")
+		*now = now.Add(cfg.AuthLoginBaseDelay)
+		attempt, err := target.Check(ctx, "alice", "")
+		require.NoError(t, err)
+
+		require.NoError(t, target.RecordSuccess(ctx, attempt))
+
+		assert.NoError(t, check(target, "alice", ""))
+	})
+
+	t.Run("正常系: 並行した試行も検証の前に数える", func(t *testing.T) {
+		target, _ := setup(cfg)
+		for range cfg.AuthLoginDelayAfter {
+			_, err := target.Check(ctx, "alice", "")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, cfg.AuthLoginBaseDelay, retryAfter(t, check(target, "alice", "")), "結果の出ていない試行も失敗として数える")
+	})
+
+	t.Run("正常系: 予約を取り消した試行と拒否した試行は数えない", func(t *testing.T) {
+		target, _ := setup(cfg)
+		for _, user := range []string{"alice", "bob", "carol"} {
+			require.NoError(t, check(target, user, "192.0.2.1"))
+		}
+		fail(t, target, "alice", "")
+		fail(t, target, "alice", "")
+		require.Error(t, check(target, "alice", ""))
+
+		failures, err := target.repository.Failures(ctx, userAttemptKey("alice"), time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, failures, 2)
+		failures, err = target.repository.Failures(ctx, ipAttemptKey("192.0.2.1"), time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+
+	t.Run("正常系: しきい値に達したユーザーはロックし、解除するまで試行できない", func(t *testing.T) {
+		target, now := setup(cfg)
+		for range cfg.AuthLockoutThreshold {
+			fail(t, target, "alice", "")
+			*now = now.Add(cfg.AuthLoginMaxDelay)
+		}
+
+		got := retryAfter(t, check(target, "alice", ""))
+		assert.InDelta(t, (cfg.AuthLockoutDuration - cfg.AuthLoginMaxDelay).Seconds(), got.Seconds(), 1)
+
+		unlocked, err := target.Unlock(ctx, "alice")
+		require.NoError(t, err)
+		assert.True(t, unlocked)
+		assert.NoError(t, check(target, "alice", ""), "解除後は待機時間も最初からやり直す")
+	})
+
+	t.Run("正常系: 並行した試行がしきい値を超えた場合はロックする", func(t *testing.T) {
+		target, _ := setup(&config.AppConfig{
+			AuthLoginFailureWindow: 15 * time.Minute,
+			AuthLockoutThreshold:   2,
+			AuthLockoutDuration:    10 * time.Minute,
+		})
+		for range 2 {
+			_, err := target.Check(ctx, "alice", "")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 10*time.Minute, retryAfter(t, check(target, "alice", "")))
+		until, err := target.repository.LockedUntil(ctx, userAttemptKey("alice"))
+		require.NoError(t, err)
+		assert.False(t, until.IsZero())
+	})
+
+	t.Run("正常系: 同じ IP アドレスからの失敗が上限に達すると、最も古い失敗がウィンドウから外れるまで拒否する", func(t *testing.T) {
+		target, now := setup(cfg)
+		start := now.Truncate(time.Microsecond)
+		for _, user := range []string{"alice", "bob", "carol"} {
+			fail(t, target, user, "192.0.2.1")
+			*now = now.Add(time.Minute)
+		}
+
+		got := retryAfter(t, check(target, "dave", "192.0.2.1"))
+		assert.Equal(t, start.Add(cfg.AuthLoginFailureWindow).Sub(now.Truncate(time.Microsecond)), got)
+		assert.NoError(t, check(target, "dave", "198.51.100.1"))
+	})
+
+	t.Run("正常系: 設定が 0 の場合は何も制限しない", func(t *testing.T) {
+		target, _ := setup(&config.AppConfig{})
+		for range 20 {
+			fail(t, target, "alice", "192.0.2.1")
+		}
+
+		assert.NoError(t, check(target, "alice", "192.0.2.1"))
+	})
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+type OAuthUsecase interface {
+	// ClientCredentials はクライアントを認証し、要求されたスコープのトークンを発行します
+	// scope が空の場合はクライアントに許可されたすべてのスコープを付与します
+	// クライアントの誤りは *services.OAuthError で返します
+	ClientCredentials(ctx context.Context, clientID, clientSecret, scope string) (*models.ClientToken, error)
+}
+
+type oauthUsecase struct {
+	cfg                   *config.AppConfig
+	logger                logger.Logger
+	tokenService          services.TokenService
+	passwordHasher        services.PasswordHasher
+	oauthClientRepository repository.OAuthClientRepository
+
+	// dummyHash は存在しないクライアントでも同じ時間をかけて検証するためのハッシュです
+	dummyHashOnce sync.Once
+	dummyHash     string
+}
+
+func NewOAuthUsecase(
+	cfg *config.AppConfig,
+	logger logger.Logger,
+	tokenService services.TokenService,
+	passwordHasher services.PasswordHasher,
+	oauthClientRepository repository.OAuthClientRepository,
+) OAuthUsecase {
+	return &oauthUsecase{
+		cfg:                   cfg,
+		logger:                logger.With("log_type", "audit"),
+		tokenService:          tokenService,
+		passwordHasher:        passwordHasher,
+		oauthClientRepository: oauthClientRepository,
+	}
+}
+
+func (uc *oauthUsecase) ClientCredentials(ctx context.Context, clientID, clientSecret, scope string) (*models.ClientToken, error) {
+	client, err := uc.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := client.Scopes
+	if scope != "" {
+		scopes = strings.Fields(scope)
+		for _, s := range scopes {
+			if !slices.Contains(client.Scopes, s) {
+				uc.logger.WarnContext(ctx, "OAuth client requested a scope it is not allowed", "client_id", clientID, "scope", s)
+				return nil, services.NewOAuthError(services.OAuthErrorInvalidScope, "scope "+s+" is not allowed for this client")
+			}
+		}
+	}
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+
+	accessToken, err := uc.tokenService.GenerateClientToken(ctx, client.ID, client.Roles, scopes)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "OAuth client token issued", "client_id", clientID, "scopes", scopes)
+
+	return &models.ClientToken{
+		AccessToken: accessToken,
+		ExpiresIn:   uc.cfg.AuthAccessTokenTTL,
+		Scopes:      scopes,
+	}, nil
+}
+
+// authenticateClient はクライアントシークレットを検証します
+// クライアントの有無を推測されないよう、失敗理由にかかわらず同じ invalid_client を返します
+func (uc *oauthUsecase) authenticateClient(ctx context.Context, clientID, clientSecret string) (*models.OAuthClient, error) {
+	client, err := uc.oauthClientRepository.Get(ctx, clientID)
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+
+	hash := uc.getDummyHash()
+	if client != nil {
+		hash = client.SecretHash
+	}
+	ok, verifyErr := uc.passwordHasher.Verify(clientSecret, hash)
+	if verifyErr != nil {
+		uc.logger.ErrorContext(ctx, "Failed to verify client secret hash", "client_id", clientID, "error", verifyErr)
+	}
+	if client == nil || !ok {
+		uc.logger.WarnContext(ctx, "OAuth client authentication failed", "client_id", clientID, "client_exists", client != nil)
+		return nil, services.NewOAuthError(services.OAuthErrorInvalidClient, "client authentication failed")
+	}
+	return client, nil
+}
+
+func (uc *oauthUsecase) getDummyHash() string {
+	uc.dummyHashOnce.Do(func() {
+		hash, err := uc.passwordHasher.Hash("dummy-client-secret")
+		if err == nil {
+			uc.dummyHash = hash
+		}
+	})
+	return uc.dummyHash
+}
@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/repository"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/services"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthUsecase_ClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.AppConfig{
+		JWTSecretKey:        "secret",
+		JWTIssuer:           "issuer",
+		JWTAudience:         "audience",
+		AuthAccessTokenTTL:  15 * time.Minute,
+		AuthRolePermissions: map[string][]string{"viewer": {"samples:read"}},
+	}
+	tokenService := services.NewTokenService(cfg, mustKeyRing(t, cfg))
+	hasher := services.NewPasswordHasher()
+
+	hash, err := hasher.Hash("s3cret")
+	require.NoError(t, err)
+	clients := repository.NewMemoryOAuthClientRepository()
+	require.NoError(t, clients.Create(ctx, &models.OAuthClient{
+		ID:         "batch",
+		SecretHash: hash,
+		Scopes:     []string{"samples:read", "api-keys:manage"},
+		Roles:      []string{"role:teamA:viewer"},
+	}))
+	target := NewOAuthUsecase(cfg, logger.NewLogger(&config.AppConfig{}), tokenService, hasher, clients)
+
+	assertOAuthError := func(t *testing.T, err error, code string) {
+		t.Helper()
+		var oauthErr *services.OAuthError
+		require.True(t, errors.As(err, &oauthErr), "expected OAuthError, got %v", err)
+		assert.Equal(t, code, oauthErr.Code)
+	}
+
+	t.Run("正常系: 要求したスコープのトークンを発行する", func(t *testing.T) {
+		token, err := target.ClientCredentials(ctx, "batch", "s3cret", "samples:read")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"samples:read"}, token.Scopes)
+		assert.Equal(t, 15*time.Minute, token.ExpiresIn)
+
+		claims, err := tokenService.ValidateToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "batch", claims.ClientID)
+		assert.Equal(t, "samples:read", claims.Scope)
+	})
+
+	t.Run("正常系: スコープを指定しない場合は許可されたすべてのスコープを付与する", func(t *testing.T) {
+		token, err := target.ClientCredentials(ctx, "batch", "s3cret", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api-keys:manage", "samples:read"}, token.Scopes)
+	})
+
+	t.Run("異常系: 許可されていないスコープは invalid_scope", func(t *testing.T) {
+		_, err := target.ClientCredentials(ctx, "batch", "s3cret", "samples:read samples:write")
+
+		assertOAuthError(t, err, services.OAuthErrorInvalidScope)
+	})
+
+	t.Run("異常系: シークレットの誤りと未登録のクライアントは同じ invalid_client", func(t *testing.T) {
+		_, err := target.ClientCredentials(ctx, "batch", "wrong", "")
+		assertOAuthError(t, err, services.OAuthErrorInvalidClient)
+
+		_, err = target.ClientCredentials(ctx, "unknown", "s3cret", "")
+		assertOAuthError(t, err, services.OAuthErrorInvalidClient)
+	})
+}
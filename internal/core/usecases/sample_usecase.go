@@ -3,33 +3,113 @@ package usecases
 import (
 	"context"
 
-	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/piyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/datasource"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/policy"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
 )
 
+// サンプルに対するポリシーのアクション
+const (
+	ActionSampleRead      = "samples:read"
+	ActionSampleReadEmail = "samples:read_email"
+	ActionSampleUpdate    = "samples:update"
+	ActionSampleDelete    = "samples:delete"
+)
+
+// SampleUsecase はサンプルを操作します。actor は操作するユーザーで、ポリシーの評価に使います
 type SampleUsecase interface {
-	Get(ctx context.Context, ID string) (*models.Sample, error)
-	List(ctx context.Context, offset, limit *int) ([]models.Sample, error)
+	Get(ctx context.Context, actor *models.User, ID string) (*models.Sample, error)
+	// List は actor が参照できるサンプルのみを返します
+	List(ctx context.Context, actor *models.User, offset, limit *int) ([]models.Sample, error)
+	Update(ctx context.Context, actor *models.User, sample *models.Sample) (*models.Sample, error)
+	Delete(ctx context.Context, actor *models.User, ID string) error
 }
 
 type sampleUsecase struct {
-	logger        logger.Logger
-	graphqlClient piyographql.Client
+	logger       logger.Logger
+	dataSource   datasource.SampleDataSource
+	policyEngine policy.Engine
 }
 
-func NewSampleUsecase(logger logger.Logger, client piyographql.Client) SampleUsecase {
+// NewSampleUsecase は設定で選択された取得元を使う SampleUsecase を返します
+func NewSampleUsecase(logger logger.Logger, dataSource datasource.SampleDataSource, policyEngine policy.Engine) SampleUsecase {
 	return &sampleUsecase{
-		logger:        logger,
-		graphqlClient: client,
+		logger:       logger,
+		dataSource:   dataSource,
+		policyEngine: policyEngine,
 	}
 }
 
-func (uc *sampleUsecase) Get(ctx context.Context, ID string) (*models.Sample, error) {
+func (uc *sampleUsecase) Get(ctx context.Context, actor *models.User, ID string) (*models.Sample, error) {
 	// todo trace logger
-	return uc.graphqlClient.GetSample(ctx, ID)
+	sample, err := uc.dataSource.GetSample(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.policyEngine.Enforce(ctx, sampleInput(actor, ActionSampleRead, sample)); err != nil {
+		return nil, err
+	}
+	return uc.redact(ctx, actor, sample), nil
+}
+
+func (uc *sampleUsecase) List(ctx context.Context, actor *models.User, offset, limit *int) ([]models.Sample, error) {
+	samples, err := uc.dataSource.ListSample(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.Sample, 0, len(samples))
+	for i := range samples {
+		if !uc.policyEngine.Evaluate(ctx, sampleInput(actor, ActionSampleRead, &samples[i])).Allowed {
+			continue
+		}
+		visible = append(visible, *uc.redact(ctx, actor, &samples[i]))
+	}
+	return visible, nil
 }
 
-func (uc *sampleUsecase) List(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
-	return uc.graphqlClient.ListSample(ctx, offset, limit)
+func (uc *sampleUsecase) Update(ctx context.Context, actor *models.User, sample *models.Sample) (*models.Sample, error) {
+	// 所有するチームなどの属性は更新前のサンプルで評価する
+	current, err := uc.dataSource.GetSample(ctx, sample.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.policyEngine.Enforce(ctx, sampleInput(actor, ActionSampleUpdate, current)); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.dataSource.UpdateSample(ctx, sample)
+	if err != nil {
+		return nil, err
+	}
+	return uc.redact(ctx, actor, updated), nil
+}
+
+func (uc *sampleUsecase) Delete(ctx context.Context, actor *models.User, ID string) error {
+	current, err := uc.dataSource.GetSample(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if err := uc.policyEngine.Enforce(ctx, sampleInput(actor, ActionSampleDelete, current)); err != nil {
+		return err
+	}
+	return uc.dataSource.DeleteSample(ctx, ID)
+}
+
+// redact は actor が参照できない項目を空にしたコピーを返します。取得元がキャッシュを共有するため元の値は変更しない
+func (uc *sampleUsecase) redact(ctx context.Context, actor *models.User, sample *models.Sample) *models.Sample {
+	redacted := *sample
+	if !uc.policyEngine.Evaluate(ctx, sampleInput(actor, ActionSampleReadEmail, sample)).Allowed {
+		redacted.Email = ""
+	}
+	return &redacted
+}
+
+func sampleInput(actor *models.User, action string, sample *models.Sample) policy.Input {
+	return policy.Input{
+		Subject:  policy.SubjectAttributes(actor),
+		Action:   action,
+		Resource: policy.SampleAttributes(sample),
+	}
 }
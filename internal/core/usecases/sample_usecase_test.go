@@ -2,14 +2,20 @@ package usecases
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/apperrors"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
-	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/mocks/mockpiyographql"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/policy"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/mocks/mockdatasource"
 	"github.com/golang/mock/gomock"
 )
 
@@ -17,17 +23,21 @@ func TestSampleUsecase(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockClient := mockpiyographql.NewMockClient(ctrl)
-	target := NewSampleUsecase(logger.NewLogger(&config.AppConfig{}), mockClient) // fixme test cfg
+	mockDataSource := mockdatasource.NewMockSampleDataSource(ctrl)
+	policyEngine, stop, err := policy.NewEngine(&config.AppConfig{}, logger.NewLogger(&config.AppConfig{}))
+	require.NoError(t, err)
+	defer stop()
+	target := NewSampleUsecase(logger.NewLogger(&config.AppConfig{}), mockDataSource, policyEngine) // fixme test cfg
+	actor := &models.User{ID: "user123", Roles: []string{"role:teamA:editor"}}
 
 	t.Run("get sample", func(t *testing.T) {
 		ID := "123"
 		// モックの振る舞いを設定
-		mockClient.EXPECT().GetSample(context.Background(), ID).
+		mockDataSource.EXPECT().GetSample(context.Background(), ID).
 			Return(&models.Sample{ID: "123", StringVal: "Test Sample"}, nil)
 
 		// テストケースを実行
-		sample, err := target.Get(context.Background(), ID)
+		sample, err := target.Get(context.Background(), actor, ID)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "123", sample.ID)
@@ -36,13 +46,93 @@ func TestSampleUsecase(t *testing.T) {
 	t.Run("get sample 2", func(t *testing.T) {
 		ID := "aaa"
 		// モックの振る舞いを設定
-		mockClient.EXPECT().GetSample(context.Background(), ID).
+		mockDataSource.EXPECT().GetSample(context.Background(), ID).
 			Return(&models.Sample{ID: "aaa", StringVal: "Test Sample"}, nil)
 
 		// テストケースを実行
-		sample, err := target.Get(context.Background(), ID)
+		sample, err := target.Get(context.Background(), actor, ID)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "aaa", sample.ID)
 	})
 }
+
+func TestSampleUsecase_Policy(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	policyFile := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`
+default_effect: deny
+policies:
+  - id: members-read-samples
+    effect: allow
+    actions: [samples:read]
+    conditions:
+      - attribute: subject.permissions
+        operator: exists
+  - id: editors-manage-own-team-samples
+    effect: allow
+    actions: [samples:update, samples:delete, samples:read_email]
+    conditions:
+      - attribute: resource.team
+        operator: in
+        value_from: subject.teams.editor
+`), 0o600))
+
+	newTarget := func(t *testing.T, dryRun bool) (SampleUsecase, *mockdatasource.MockSampleDataSource) {
+		mockDataSource := mockdatasource.NewMockSampleDataSource(ctrl)
+		policyEngine, stop, err := policy.NewEngine(&config.AppConfig{
+			PolicyFile:           policyFile,
+			PolicyDryRun:         dryRun,
+			PolicyReloadInterval: time.Hour,
+		}, logger.NewLogger(&config.AppConfig{}))
+		require.NoError(t, err)
+		t.Cleanup(stop)
+		return NewSampleUsecase(logger.NewLogger(&config.AppConfig{}), mockDataSource, policyEngine), mockDataSource
+	}
+	editor := &models.User{ID: "editor", Roles: []string{"role:teamA:editor"}}
+	viewer := &models.User{ID: "viewer", Roles: []string{"role:teamA:viewer"}}
+	sample := &models.Sample{ID: "123", Email: "owner@example.com", Team: "teamA"}
+
+	t.Run("正常系: 自チームの editor はメールアドレスを参照できる", func(t *testing.T) {
+		target, mockDataSource := newTarget(t, false)
+		mockDataSource.EXPECT().GetSample(ctx, "123").Return(sample, nil)
+
+		got, err := target.Get(ctx, editor, "123")
+
+		require.NoError(t, err)
+		assert.Equal(t, "owner@example.com", got.Email)
+	})
+
+	t.Run("正常系: viewer にはメールアドレスを返さない", func(t *testing.T) {
+		target, mockDataSource := newTarget(t, false)
+		mockDataSource.EXPECT().GetSample(ctx, "123").Return(sample, nil)
+
+		got, err := target.Get(ctx, viewer, "123")
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Email)
+		assert.Equal(t, "owner@example.com", sample.Email, "取得元の値は変更しない")
+	})
+
+	t.Run("異常系: 他チームのサンプルは更新できない", func(t *testing.T) {
+		target, mockDataSource := newTarget(t, false)
+		mockDataSource.EXPECT().GetSample(ctx, "456").Return(&models.Sample{ID: "456", Team: "teamB"}, nil)
+
+		_, err := target.Update(ctx, editor, &models.Sample{ID: "456"})
+
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, apperrors.ErrorTypeForbidden, appErr.Type)
+	})
+
+	t.Run("正常系: dry-run では拒否される操作も実行する", func(t *testing.T) {
+		target, mockDataSource := newTarget(t, true)
+		mockDataSource.EXPECT().GetSample(ctx, "456").Return(&models.Sample{ID: "456", Team: "teamB"}, nil)
+		mockDataSource.EXPECT().DeleteSample(ctx, "456").Return(nil)
+
+		assert.NoError(t, target.Delete(ctx, viewer, "456"))
+	})
+}
@@ -3,6 +3,11 @@ package usecases
 import "github.com/google/wire"
 
 var Set = wire.NewSet(
+	NewAccountUsecase,
 	NewAuthUsecase,
+	NewAPIKeyUsecase,
+	NewHealthcheckUsecase,
+	NewLoginThrottle,
+	NewOAuthUsecase,
 	NewSampleUsecase,
 )
@@ -3,6 +3,7 @@ package apperrors
 import (
 	"net/http"
 	"runtime"
+	"time"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
@@ -17,11 +18,16 @@ const (
 	ErrorTypeConflict           ErrorType = "CONFLICT"
 	ErrorTypeRateLimit          ErrorType = "RATE_LIMIT"
 	ErrorTypeInternal           ErrorType = "INTERNAL_ERROR"
+	ErrorTypeNotImplemented     ErrorType = "NOT_IMPLEMENTED"
 	ErrorTypeExternalService    ErrorType = "EXTERNAL_SERVICE_ERROR"
 	ErrorTypeServiceUnavailable ErrorType = "SERVICE_UNAVAILABLE"
 	ErrorTypeTimeout            ErrorType = "TIMEOUT"
+	ErrorTypeCanceled           ErrorType = "CANCELED"
 )
 
+// StatusClientClosedRequest は呼び出し元が応答を待たずに切断したことを表すステータスコードです（nginx の慣例）
+const StatusClientClosedRequest = 499
+
 // AppError はアプリケーション固有のエラーを表します。
 type AppError struct {
 	Type       ErrorType
@@ -31,6 +37,8 @@ type AppError struct {
 	File       string
 	Line       int
 	Function   string
+	// RetryAfter が設定されている場合は Retry-After ヘッダーとしてクライアントに返されます
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
@@ -48,6 +56,12 @@ func NewAppError(errType ErrorType, rawErr error, statusCode int, message string
 	return appErr
 }
 
+// WithRetryAfter は Retry-After として返す待機時間を設定します
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+	e.RetryAfter = d
+	return e
+}
+
 func (e *AppError) captureStack(skip int) {
 	pc, file, line, ok := runtime.Caller(skip)
 	if !ok {
@@ -114,6 +128,11 @@ func NewInternalError(message string, rawErr error) *AppError {
 	return NewAppError(ErrorTypeInternal, rawErr, http.StatusInternalServerError, message)
 }
 
+// NewNotImplementedError 501 Not Implemented
+func NewNotImplementedError(message string, rawErr error) *AppError {
+	return NewAppError(ErrorTypeNotImplemented, rawErr, http.StatusNotImplemented, message)
+}
+
 // NewExternalServiceError 502 Bad Gateway
 func NewExternalServiceError(message string, rawErr error) *AppError {
 	return NewAppError(ErrorTypeExternalService, rawErr, http.StatusBadGateway, message)
@@ -128,3 +147,9 @@ func NewServiceUnavailableError(message string, rawErr error) *AppError {
 func NewTimeoutError(message string, rawErr error) *AppError {
 	return NewAppError(ErrorTypeTimeout, rawErr, http.StatusGatewayTimeout, message)
 }
+
+// NewCanceledError 499 Client Closed Request
+// 呼び出し元のキャンセルは upstream の障害ではないため、タイムアウトとは区別します
+func NewCanceledError(message string, rawErr error) *AppError {
+	return NewAppError(ErrorTypeCanceled, rawErr, StatusClientClosedRequest, message)
+}
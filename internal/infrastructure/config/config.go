@@ -37,9 +37,22 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("env", "dev")
 	l.v.SetDefault("log_level", "INFO")
 	l.v.SetDefault("server_address", ":8081")
+	l.v.SetDefault("trusted_proxies", []string{})
 	l.v.SetDefault("allowed_origins", []string{"*"})
 	l.v.SetDefault("jwt_secret_key", "jwt-secret")
+	l.v.SetDefault("jwt_keys_file", "")
+	l.v.SetDefault("jwt_key_rotation_overlap", 24*time.Hour)
+	l.v.SetDefault("jwt_issuer", "go-rest-clean-plane-chi")
+	l.v.SetDefault("jwt_audience", "go-rest-clean-plane-chi")
+	l.v.SetDefault("jwt_leeway", 30*time.Second)
+	l.v.SetDefault("jwt_allowed_algorithms", []string{})
 	l.v.SetDefault("request_timeout", 180*time.Second)
+	l.v.SetDefault("tls_cert_file", "")
+	l.v.SetDefault("tls_key_file", "")
+	l.v.SetDefault("tls_reload_interval", time.Minute)
+	l.v.SetDefault("tls_client_auth", "none")
+	l.v.SetDefault("tls_client_ca_file", "")
+	l.v.SetDefault("tls_client_principals_file", "")
 	//v.SetDefault("request_timeout", 1*time.Second) // fixme
 
 	l.v.SetDefault("dd_enabled", true)
@@ -48,6 +61,87 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("dd_agent_trace_port", "8126") // case: datadog SDK
 	l.v.SetDefault("dd_agent_metrics_port", "8125")
 	l.v.SetDefault("dd_sampling_rate", 1.0)
+
+	l.v.SetDefault("database_driver", "pgx")
+	l.v.SetDefault("database_dsn", "")
+	l.v.SetDefault("database_max_open_conns", 10)
+	l.v.SetDefault("database_max_idle_conns", 5)
+	l.v.SetDefault("database_conn_max_lifetime", 30*time.Minute)
+
+	l.v.SetDefault("user_store", "memory")
+	l.v.SetDefault("user_seed_file", "")
+	l.v.SetDefault("auth_access_token_ttl", 15*time.Minute)
+	l.v.SetDefault("auth_refresh_token_ttl", 30*24*time.Hour)
+	l.v.SetDefault("oidc_providers_file", "")
+	l.v.SetDefault("oidc_refresh_interval", 15*time.Minute)
+	l.v.SetDefault("oidc_min_refresh_interval", 30*time.Second)
+	l.v.SetDefault("oidc_http_timeout", 5*time.Second)
+	l.v.SetDefault("auth_role_permissions", map[string][]string{
+		"viewer": {"samples:read"},
+		"editor": {"samples:read", "samples:write"},
+		"admin":  {"*"},
+	})
+	l.v.SetDefault("policy_file", "")
+	l.v.SetDefault("policy_dry_run", false)
+	l.v.SetDefault("policy_reload_interval", 5*time.Second)
+	l.v.SetDefault("oauth_clients_file", "")
+	l.v.SetDefault("auth_login_failure_window", 15*time.Minute)
+	l.v.SetDefault("auth_login_delay_after", 3)
+	l.v.SetDefault("auth_login_base_delay", time.Second)
+	l.v.SetDefault("auth_login_max_delay", 30*time.Second)
+	l.v.SetDefault("auth_lockout_threshold", 10)
+	l.v.SetDefault("auth_lockout_duration", 15*time.Minute)
+	l.v.SetDefault("auth_login_ip_max_failures", 100)
+	l.v.SetDefault("auth_register_ip_max_attempts", 20)
+	l.v.SetDefault("auth_mfa_issuer", "go-rest-clean-plane-chi")
+	l.v.SetDefault("auth_mfa_challenge_ttl", 5*time.Minute)
+	l.v.SetDefault("auth_mfa_required_roles", []string{})
+	l.v.SetDefault("auth_mfa_encryption_key", "")
+	l.v.SetDefault("auth_admin_roles", []string{"role:system:admin"})
+	l.v.SetDefault("auth_default_roles", []string{"role:default:viewer"})
+	l.v.SetDefault("auth_require_verified_email", false)
+	l.v.SetDefault("auth_email_verification_ttl", 24*time.Hour)
+	l.v.SetDefault("auth_password_reset_ttl", 30*time.Minute)
+
+	l.v.SetDefault("app_base_url", "http://localhost:8081")
+	l.v.SetDefault("mailer_type", "log")
+	l.v.SetDefault("mailer_file_path", "tmp/mail.log")
+	l.v.SetDefault("mailer_from", "no-reply@example.com")
+
+	l.v.SetDefault("sample_data_source", "graphql")
+	l.v.SetDefault("sample_read_through_cache_size", 1000)
+	l.v.SetDefault("sample_read_through_cache_ttl", 5*time.Minute)
+
+	l.v.SetDefault("piyo_graphql_endpoint", "http://localhost:8080/graphql")
+	l.v.SetDefault("piyo_graphql_auth_header", "Authorization")
+	l.v.SetDefault("piyo_graphql_auth_token", "")
+	l.v.SetDefault("piyo_graphql_timeout", 10*time.Second)
+	l.v.SetDefault("piyo_graphql_connect_timeout", 3*time.Second)
+	l.v.SetDefault("piyo_graphql_retry_max_attempts", 3)
+	l.v.SetDefault("piyo_graphql_retry_base_delay", 100*time.Millisecond)
+	l.v.SetDefault("piyo_graphql_retry_max_delay", 2*time.Second)
+	l.v.SetDefault("piyo_graphql_breaker_failure_rate", 0.5)
+	l.v.SetDefault("piyo_graphql_breaker_min_requests", 10)
+	l.v.SetDefault("piyo_graphql_breaker_window", 30*time.Second)
+	l.v.SetDefault("piyo_graphql_breaker_open_timeout", 15*time.Second)
+	l.v.SetDefault("piyo_graphql_breaker_half_open_requests", 3)
+	l.v.SetDefault("piyo_graphql_bulkhead_max_concurrent", 20)
+	l.v.SetDefault("piyo_graphql_bulkhead_max_queue", 50)
+	l.v.SetDefault("piyo_graphql_bulkhead_max_wait", time.Second)
+	l.v.SetDefault("piyo_graphql_hedge_enabled", false)
+	l.v.SetDefault("piyo_graphql_hedge_percentile", 0.95)
+	l.v.SetDefault("piyo_graphql_hedge_min_delay", 10*time.Millisecond)
+	l.v.SetDefault("piyo_graphql_hedge_budget", 0.05)
+	l.v.SetDefault("piyo_graphql_hedge_max_burst", 10)
+	l.v.SetDefault("piyo_graphql_cache_size", 1000)
+	l.v.SetDefault("piyo_graphql_cache_ttl", 30*time.Second)
+	l.v.SetDefault("piyo_graphql_cache_negative_ttl", 5*time.Second)
+	l.v.SetDefault("piyo_graphql_cache_stale_grace", 30*time.Second)
+	l.v.SetDefault("piyo_graphql_cache_stale_max_age", 10*time.Minute)
+	l.v.SetDefault("piyo_graphql_loader_wait", 2*time.Millisecond)
+	l.v.SetDefault("piyo_graphql_loader_max_batch", 100)
+	l.v.SetDefault("piyo_graphql_cassette_mode", "off")
+	l.v.SetDefault("piyo_graphql_cassette_dir", "testdata/cassettes")
 }
 
 type AppConfig struct {
@@ -58,12 +152,124 @@ type AppConfig struct {
 	AllowedOrigins []string      `mapstructure:"allowed_origins" validate:"required"`
 	JWTSecretKey   string        `mapstructure:"jwt_secret_key" validate:"required"`
 	RequestTimeout time.Duration `mapstructure:"request_timeout" validate:"required"`
+	// Proxy
+	// TrustedProxies は X-Forwarded-For を信頼するプロキシの CIDR です。空の場合はヘッダーを無視し、接続元をクライアントとします
+	TrustedProxies []string `mapstructure:"trusted_proxies" validate:"dive,cidr"`
+	// TLS
+	// TLSCertFile を指定した場合は TLS で待ち受けます。証明書と鍵は TLSReloadInterval ごとに変更を反映します
+	TLSCertFile       string        `mapstructure:"tls_cert_file"`
+	TLSKeyFile        string        `mapstructure:"tls_key_file" validate:"required_with=TLSCertFile"`
+	TLSReloadInterval time.Duration `mapstructure:"tls_reload_interval" validate:"required,gt=0"`
+	// TLSClientAuth はクライアント証明書の要求方法です（none / optional / require）。optional と require では TLSClientCAFile で検証します
+	TLSClientAuth   string `mapstructure:"tls_client_auth" validate:"oneof=none optional require"`
+	TLSClientCAFile string `mapstructure:"tls_client_ca_file"`
+	// TLSClientPrincipalsFile はクライアント証明書のサブジェクトや SAN とプリンシパル（id, roles, scopes）の対応を並べた JSON ファイルです
+	TLSClientPrincipalsFile string `mapstructure:"tls_client_principals_file"`
 	// DataDog Agent
 	DDEnabled          bool    `mapstructure:"dd_enabled" validate:"required"`
 	DDAgentHost        string  `mapstructure:"dd_agent_host" validate:"required"`
 	DDAgentTracePort   string  `mapstructure:"dd_agent_trace_port" validate:"required"`
 	DDAgentMetricsPort string  `mapstructure:"dd_agent_metrics_port" validate:"required"`
 	DDSamplingRate     float64 `mapstructure:"dd_sampling_rate" validate:"required"`
+	// Database
+	DatabaseDriver          string        `mapstructure:"database_driver" validate:"required"`
+	DatabaseDSN             string        `mapstructure:"database_dsn" validate:"required_if=UserStore sql"`
+	DatabaseMaxOpenConns    int           `mapstructure:"database_max_open_conns" validate:"gte=1"`
+	DatabaseMaxIdleConns    int           `mapstructure:"database_max_idle_conns" validate:"gte=0"`
+	DatabaseConnMaxLifetime time.Duration `mapstructure:"database_conn_max_lifetime"`
+	// Auth
+	UserStore           string        `mapstructure:"user_store" validate:"oneof=memory sql"` // memory / sql
+	UserSeedFile        string        `mapstructure:"user_seed_file"`                         // memory ストアに読み込むユーザーの JSON ファイル
+	AuthAccessTokenTTL  time.Duration `mapstructure:"auth_access_token_ttl" validate:"required"`
+	AuthRefreshTokenTTL time.Duration `mapstructure:"auth_refresh_token_ttl" validate:"gtfield=AuthAccessTokenTTL"`
+	// JWTKeysFile は RS256/ES256 の署名鍵（kid, private_key_file, active_from）を並べた JSON ファイルです
+	// 指定しない場合は JWTSecretKey による HS256 で署名します
+	JWTKeysFile           string        `mapstructure:"jwt_keys_file"`
+	JWTKeyRotationOverlap time.Duration `mapstructure:"jwt_key_rotation_overlap" validate:"gtefield=AuthAccessTokenTTL"` // 鍵の切り替え前後に新旧の鍵を公開する期間
+	JWTIssuer             string        `mapstructure:"jwt_issuer" validate:"required"`
+	JWTAudience           string        `mapstructure:"jwt_audience" validate:"required"`
+	JWTLeeway             time.Duration `mapstructure:"jwt_leeway" validate:"gte=0,lte=5m"` // exp/nbf/iat の時計のずれの許容範囲
+	// JWTAllowedAlgorithms は受け付ける署名アルゴリズムです。空の場合は署名鍵のアルゴリズムのみを受け付けます
+	JWTAllowedAlgorithms []string `mapstructure:"jwt_allowed_algorithms" validate:"dive,oneof=HS256 RS256 ES256"`
+	// OIDCProvidersFile は受け付ける外部 IdP（issuer, audience, role_claim, role_mappings など）を並べた JSON ファイルです
+	OIDCProvidersFile      string        `mapstructure:"oidc_providers_file"`
+	OIDCRefreshInterval    time.Duration `mapstructure:"oidc_refresh_interval" validate:"required"`
+	OIDCMinRefreshInterval time.Duration `mapstructure:"oidc_min_refresh_interval"` // 未知の kid による再取得の最短間隔
+	OIDCHTTPTimeout        time.Duration `mapstructure:"oidc_http_timeout" validate:"required"`
+	// AuthRolePermissions はロール role:<team>:<permission> の <permission> から許可する操作への対応です
+	AuthRolePermissions map[string][]string `mapstructure:"auth_role_permissions"`
+	// PolicyFile は属性ベースのアクセス制御のポリシー（YAML）です。空の場合はすべて許可します
+	PolicyFile           string        `mapstructure:"policy_file"`
+	PolicyDryRun         bool          `mapstructure:"policy_dry_run"` // 判定をログに残すだけで強制しない
+	PolicyReloadInterval time.Duration `mapstructure:"policy_reload_interval" validate:"required,gt=0"`
+	// OAuthClientsFile は client_credentials グラントのクライアント（client_id, client_secret_hash, scopes, roles）を並べた JSON ファイルです
+	// memory ストアの場合のみ読み込みます
+	OAuthClientsFile string `mapstructure:"oauth_clients_file"`
+	// ログインの総当たり対策。いずれも 0 の場合は無効です
+	AuthLoginFailureWindow time.Duration `mapstructure:"auth_login_failure_window"`  // 失敗を数えるスライディングウィンドウ
+	AuthLoginDelayAfter    int           `mapstructure:"auth_login_delay_after"`     // この回数の失敗から次の試行まで待たせる
+	AuthLoginBaseDelay     time.Duration `mapstructure:"auth_login_base_delay"`      // 待機時間は失敗ごとに倍になる
+	AuthLoginMaxDelay      time.Duration `mapstructure:"auth_login_max_delay"`       // 待機時間の上限
+	AuthLockoutThreshold   int           `mapstructure:"auth_lockout_threshold"`     // この回数の失敗でアカウントをロックする
+	AuthLockoutDuration    time.Duration `mapstructure:"auth_lockout_duration"`      // ロックの期間
+	AuthLoginIPMaxFailures int           `mapstructure:"auth_login_ip_max_failures"` // 同じ IP アドレスからの失敗の上限
+	// AuthRegisterIPMaxAttempts は同じ IP アドレスから auth_login_failure_window の間に受け付ける登録の上限です。0 の場合は無効です
+	AuthRegisterIPMaxAttempts int `mapstructure:"auth_register_ip_max_attempts"`
+	// TOTP による多要素認証
+	AuthMFAIssuer        string        `mapstructure:"auth_mfa_issuer" validate:"required"`        // 認証アプリに表示する発行者名
+	AuthMFAChallengeTTL  time.Duration `mapstructure:"auth_mfa_challenge_ttl" validate:"required"` // パスワード確認後にコードを入力できる期間
+	AuthMFARequiredRoles []string      `mapstructure:"auth_mfa_required_roles"`                    // MFA を必須とするロール
+	// AuthMFAEncryptionKey は TOTP のシークレットを暗号化する AES-256 の鍵（base64 の 32 バイト）です
+	// dev 以外の環境では必須です。dev で指定しない場合はプロセスごとのランダムな鍵を使います
+	AuthMFAEncryptionKey string `mapstructure:"auth_mfa_encryption_key" validate:"required_unless=Env dev"`
+	// AuthAdminRoles はユーザーのトークン失効などの管理操作を許可するロールです
+	AuthAdminRoles []string `mapstructure:"auth_admin_roles"`
+	// AuthDefaultRoles は登録したユーザーに付与するロールです
+	AuthDefaultRoles         []string      `mapstructure:"auth_default_roles"`
+	AuthRequireVerifiedEmail bool          `mapstructure:"auth_require_verified_email"` // true の場合はメール確認前のログインを拒否する
+	AuthEmailVerificationTTL time.Duration `mapstructure:"auth_email_verification_ttl" validate:"required"`
+	AuthPasswordResetTTL     time.Duration `mapstructure:"auth_password_reset_ttl" validate:"required"`
+	// Mailer
+	AppBaseURL     string `mapstructure:"app_base_url" validate:"required,url"`  // メール内のリンクの基点
+	MailerType     string `mapstructure:"mailer_type" validate:"oneof=log file"` // log / file
+	MailerFilePath string `mapstructure:"mailer_file_path" validate:"required_if=MailerType file"`
+	MailerFrom     string `mapstructure:"mailer_from" validate:"required"`
+	// SampleDataSource は graphql / local / read_through のいずれかです
+	SampleDataSource string `mapstructure:"sample_data_source" validate:"oneof=graphql local read_through"`
+	// read_through で upstream から書き戻したサンプルを保持する件数と期間
+	SampleReadThroughCacheSize int           `mapstructure:"sample_read_through_cache_size" validate:"gte=0"`
+	SampleReadThroughCacheTTL  time.Duration `mapstructure:"sample_read_through_cache_ttl"`
+	// Piyo GraphQL API
+	PiyoGraphQLEndpoint                string        `mapstructure:"piyo_graphql_endpoint" validate:"required,url"`
+	PiyoGraphQLAuthHeader              string        `mapstructure:"piyo_graphql_auth_header" validate:"required"`
+	PiyoGraphQLAuthToken               string        `mapstructure:"piyo_graphql_auth_token"`
+	PiyoGraphQLTimeout                 time.Duration `mapstructure:"piyo_graphql_timeout" validate:"required"`
+	PiyoGraphQLConnectTimeout          time.Duration `mapstructure:"piyo_graphql_connect_timeout" validate:"required"`
+	PiyoGraphQLRetryMaxAttempts        int           `mapstructure:"piyo_graphql_retry_max_attempts" validate:"gte=1"`
+	PiyoGraphQLRetryBaseDelay          time.Duration `mapstructure:"piyo_graphql_retry_base_delay" validate:"required"`
+	PiyoGraphQLRetryMaxDelay           time.Duration `mapstructure:"piyo_graphql_retry_max_delay" validate:"required"`
+	PiyoGraphQLBreakerFailureRate      float64       `mapstructure:"piyo_graphql_breaker_failure_rate" validate:"gt=0,lte=1"`
+	PiyoGraphQLBreakerMinRequests      int           `mapstructure:"piyo_graphql_breaker_min_requests" validate:"gte=1"`
+	PiyoGraphQLBreakerWindow           time.Duration `mapstructure:"piyo_graphql_breaker_window" validate:"required"`
+	PiyoGraphQLBreakerOpenTimeout      time.Duration `mapstructure:"piyo_graphql_breaker_open_timeout" validate:"required"`
+	PiyoGraphQLBreakerHalfOpenRequests int           `mapstructure:"piyo_graphql_breaker_half_open_requests" validate:"gte=1"`
+	PiyoGraphQLBulkheadMaxConcurrent   int           `mapstructure:"piyo_graphql_bulkhead_max_concurrent" validate:"gte=1"`
+	PiyoGraphQLBulkheadMaxQueue        int           `mapstructure:"piyo_graphql_bulkhead_max_queue" validate:"gte=0"`
+	PiyoGraphQLBulkheadMaxWait         time.Duration `mapstructure:"piyo_graphql_bulkhead_max_wait"`
+	PiyoGraphQLHedgeEnabled            bool          `mapstructure:"piyo_graphql_hedge_enabled"`
+	PiyoGraphQLHedgePercentile         float64       `mapstructure:"piyo_graphql_hedge_percentile" validate:"gt=0,lt=1"`
+	PiyoGraphQLHedgeMinDelay           time.Duration `mapstructure:"piyo_graphql_hedge_min_delay"`
+	PiyoGraphQLHedgeBudget             float64       `mapstructure:"piyo_graphql_hedge_budget" validate:"gte=0,lte=1"`
+	PiyoGraphQLHedgeMaxBurst           float64       `mapstructure:"piyo_graphql_hedge_max_burst" validate:"gte=1"`
+	PiyoGraphQLCacheSize               int           `mapstructure:"piyo_graphql_cache_size" validate:"gte=0"`
+	PiyoGraphQLCacheTTL                time.Duration `mapstructure:"piyo_graphql_cache_ttl"`
+	PiyoGraphQLCacheNegativeTTL        time.Duration `mapstructure:"piyo_graphql_cache_negative_ttl"`
+	PiyoGraphQLCacheStaleGrace         time.Duration `mapstructure:"piyo_graphql_cache_stale_grace"`
+	PiyoGraphQLCacheStaleMaxAge        time.Duration `mapstructure:"piyo_graphql_cache_stale_max_age" validate:"gtefield=PiyoGraphQLCacheStaleGrace"`
+	PiyoGraphQLLoaderWait              time.Duration `mapstructure:"piyo_graphql_loader_wait" validate:"required"`
+	PiyoGraphQLLoaderMaxBatch          int           `mapstructure:"piyo_graphql_loader_max_batch" validate:"gte=1"`
+	PiyoGraphQLCassetteMode            string        `mapstructure:"piyo_graphql_cassette_mode" validate:"oneof=off record replay"` // off / record / replay
+	PiyoGraphQLCassetteDir             string        `mapstructure:"piyo_graphql_cassette_dir" validate:"required_unless=PiyoGraphQLCassetteMode off"`
 }
 
 // Validate validates the config values.
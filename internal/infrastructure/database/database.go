@@ -0,0 +1,30 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	// PostgreSQL ドライバー（pgx）を database/sql に登録する
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// NewDB は設定された DSN への接続プールを返します
+// DSN が未設定の場合は nil を返し、SQL を使うストアは選択できません
+// sql.Open は接続を確立しないため、起動時に DB が利用可能である必要はありません
+// 返す関数はシャットダウン時に接続プールを閉じます
+func NewDB(cfg *config.AppConfig) (*sql.DB, func(), error) {
+	if cfg.DatabaseDSN == "" {
+		return nil, func() {}, nil
+	}
+
+	db, err := sql.Open(cfg.DatabaseDriver, cfg.DatabaseDSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DatabaseConnMaxLifetime)
+	// nolint:errcheck
+	return db, func() { db.Close() }, nil
+}
@@ -0,0 +1,17 @@
+-- メール確認とパスワードリセット
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (lower(email)) WHERE email <> '';
+
+-- token_hash はトークンの SHA-256 で、トークンそのものは保存しない
+-- expires_at を過ぎた行は参照されず、書き込み時に削除する
+CREATE TABLE IF NOT EXISTS account_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id    TEXT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    TEXT        NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_tokens_user_purpose_idx ON account_tokens (user_id, purpose);
+CREATE INDEX IF NOT EXISTS account_tokens_expires_at_idx ON account_tokens (expires_at);
@@ -0,0 +1,15 @@
+-- リフレッシュトークン
+-- token_hash はトークンの SHA-256 で、トークンそのものは保存しない
+-- 使用済みのトークンも再利用の検知のため有効期限まで残す
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash TEXT PRIMARY KEY,
+    family_id  TEXT        NOT NULL,
+    user_id    TEXT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_idx ON refresh_tokens (user_id);
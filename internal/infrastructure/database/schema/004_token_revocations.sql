@@ -0,0 +1,16 @@
+-- アクセストークンの失効
+-- どちらのテーブルも expires_at を過ぎた行は参照されず、書き込み時に削除する
+
+-- ログアウトなどで個別に失効させたトークン（jti）
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti        TEXT PRIMARY KEY,
+    user_id    TEXT        NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- revoked_before 以前に発行されたユーザーのトークンをすべて失効させる
+CREATE TABLE IF NOT EXISTS revoked_users (
+    user_id        TEXT PRIMARY KEY,
+    revoked_before TIMESTAMPTZ NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL
+);
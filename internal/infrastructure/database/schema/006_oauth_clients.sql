@@ -0,0 +1,10 @@
+-- client_credentials グラントでトークンを取得できるクライアント
+-- secret_hash はクライアントシークレットの argon2id の PHC 形式のハッシュで、シークレットそのものは保存しない
+-- scopes は要求できるスコープ、roles はトークンに付与するロールで、いずれも空白区切り
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    client_id   TEXT PRIMARY KEY,
+    secret_hash TEXT        NOT NULL,
+    scopes      TEXT        NOT NULL DEFAULT '',
+    roles       TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,15 @@
+-- ログインの失敗とアカウントのロック
+-- key はユーザー ID や IP アドレスに種類の接頭辞を付けたもの（user:alice, ip:192.0.2.1 など）
+-- 失敗はスライディングウィンドウの集計にのみ使い、ウィンドウより古い記録は記録時に削除する
+CREATE TABLE IF NOT EXISTS login_failures (
+    key       TEXT        NOT NULL,
+    failed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_failures_key_idx ON login_failures (key, failed_at);
+CREATE INDEX IF NOT EXISTS login_failures_failed_at_idx ON login_failures (failed_at);
+
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    key          TEXT PRIMARY KEY,
+    locked_until TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,12 @@
+-- TOTP による多要素認証の登録
+-- secret_ciphertext は AES-256-GCM で暗号化したシークレットで、平文は保存しない
+-- recovery_code_hashes は未使用のリカバリーコードの SHA-256 を空白区切りで並べたもの
+-- last_used_step は最後に受け付けたコードのタイムステップで、同じコードの再利用を防ぐ
+CREATE TABLE IF NOT EXISTS mfa_enrollments (
+    user_id              TEXT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret_ciphertext    TEXT        NOT NULL,
+    recovery_code_hashes TEXT        NOT NULL DEFAULT '',
+    last_used_step       BIGINT      NOT NULL DEFAULT 0,
+    confirmed_at         TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,7 @@
+package database
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(
+	NewDB,
+)
@@ -0,0 +1,40 @@
+package resilience
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff は full jitter 付きの指数バックオフを表します
+// refs: https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type Backoff struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Delay は attempt 回目（1 始まり）の失敗後に待機する時間を返します
+// 待機時間は [0, min(MaxDelay, BaseDelay*2^(attempt-1))) の一様乱数です
+func (b Backoff) Delay(attempt int) time.Duration {
+	ceiling := b.ceiling(attempt)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+func (b Backoff) ceiling(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	ceiling := b.BaseDelay
+	for i := 1; i < attempt; i++ {
+		ceiling *= 2
+		if b.MaxDelay > 0 && ceiling >= b.MaxDelay {
+			return b.MaxDelay
+		}
+	}
+	if b.MaxDelay > 0 && ceiling > b.MaxDelay {
+		return b.MaxDelay
+	}
+	return ceiling
+}
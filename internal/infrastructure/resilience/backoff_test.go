@@ -0,0 +1,33 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	testCases := []struct {
+		name    string
+		attempt int
+		ceiling time.Duration
+	}{
+		{name: "1回目は BaseDelay が上限", attempt: 1, ceiling: 100 * time.Millisecond},
+		{name: "3回目は BaseDelay*4 が上限", attempt: 3, ceiling: 400 * time.Millisecond},
+		{name: "上限は MaxDelay で頭打ち", attempt: 10, ceiling: time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.ceiling, b.ceiling(tc.attempt))
+			for range 100 {
+				d := b.Delay(tc.attempt)
+				assert.GreaterOrEqual(t, d, time.Duration(0))
+				assert.Less(t, d, tc.ceiling)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BulkheadSettings はバルクヘッドの設定です
+type BulkheadSettings struct {
+	Name string
+	// MaxConcurrent は同時に実行できる呼び出し数です
+	MaxConcurrent int
+	// MaxQueue は空きを待つことができる呼び出し数です。超えた場合は即座に拒否します
+	MaxQueue int
+	// MaxWait は空きを待つ最大時間です。0 の場合はコンテキストの終了まで待ちます
+	MaxWait time.Duration
+	// OnChange は実行中・待機中の数が変わるたびに呼ばれます（ロック保持中に呼ばれるため、バルクヘッドを操作しないこと）
+	OnChange func(name string, inFlight, queued int)
+}
+
+// BulkheadFullError はバルクヘッドが呼び出しを拒否したことを表します
+type BulkheadFullError struct {
+	Name string
+	// Queued は待機した後に拒否された場合に true になります
+	Queued bool
+	Cause  error
+}
+
+func (e *BulkheadFullError) Error() string {
+	if e.Queued {
+		return fmt.Sprintf("bulkhead %q is saturated: gave up waiting: %v", e.Name, e.Cause)
+	}
+	return fmt.Sprintf("bulkhead %q is saturated: queue is full", e.Name)
+}
+
+func (e *BulkheadFullError) Unwrap() error {
+	return e.Cause
+}
+
+// Bulkhead は依存先ごとに同時実行数を制限し、あふれた呼び出しを上限付きのキューで待たせます
+type Bulkhead struct {
+	settings BulkheadSettings
+	slots    chan struct{}
+
+	mu       sync.Mutex
+	inFlight int
+	queued   int
+}
+
+func NewBulkhead(settings BulkheadSettings) *Bulkhead {
+	if settings.MaxConcurrent < 1 {
+		settings.MaxConcurrent = 1
+	}
+	if settings.MaxQueue < 0 {
+		settings.MaxQueue = 0
+	}
+	return &Bulkhead{
+		settings: settings,
+		slots:    make(chan struct{}, settings.MaxConcurrent),
+	}
+}
+
+// Name はバルクヘッド名を返します
+func (b *Bulkhead) Name() string {
+	return b.settings.Name
+}
+
+// Acquire は実行枠を確保します
+// 確保できた場合は、呼び出し終了時に release を必ず呼び出してください
+// 待機中の呼び出しがある間は、空きがあっても新しい呼び出しを割り込ませずキューに並ばせます
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	b.mu.Lock()
+	if b.queued == 0 {
+		select {
+		case b.slots <- struct{}{}:
+			b.inFlight++
+			b.notify()
+			b.mu.Unlock()
+			return b.releaseOnce(), nil
+		default:
+		}
+	}
+	if b.queued >= b.settings.MaxQueue {
+		b.mu.Unlock()
+		return nil, &BulkheadFullError{Name: b.settings.Name}
+	}
+	b.queued++
+	b.notify()
+	b.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if b.settings.MaxWait > 0 {
+		timer := time.NewTimer(b.settings.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		b.update(1, -1)
+		return b.releaseOnce(), nil
+	case <-ctx.Done():
+		b.update(0, -1)
+		return nil, &BulkheadFullError{Name: b.settings.Name, Queued: true, Cause: ctx.Err()}
+	case <-timeout:
+		b.update(0, -1)
+		return nil, &BulkheadFullError{Name: b.settings.Name, Queued: true, Cause: fmt.Errorf("waited %s", b.settings.MaxWait)}
+	}
+}
+
+// Stats は実行中と待機中の呼び出し数を返します
+func (b *Bulkhead) Stats() (inFlight, queued int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight, b.queued
+}
+
+// releaseOnce は複数回呼ばれても 1 度だけ枠を解放する関数を返します
+func (b *Bulkhead) releaseOnce() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-b.slots
+			b.update(-1, 0)
+		})
+	}
+}
+
+func (b *Bulkhead) update(inFlight, queued int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight += inFlight
+	b.queued += queued
+	b.notify()
+}
+
+func (b *Bulkhead) notify() {
+	if b.settings.OnChange != nil {
+		b.settings.OnChange(b.settings.Name, b.inFlight, b.queued)
+	}
+}
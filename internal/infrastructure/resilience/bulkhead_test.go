@@ -0,0 +1,111 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkhead(t *testing.T) {
+	t.Run("正常系: 上限までは即座に実行枠を確保できる", func(t *testing.T) {
+		b := NewBulkhead(BulkheadSettings{Name: "test", MaxConcurrent: 2})
+
+		r1, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+		r2, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+
+		inFlight, queued := b.Stats()
+		assert.Equal(t, 2, inFlight)
+		assert.Equal(t, 0, queued)
+
+		r1()
+		r1() // 2 回目の解放は無視される
+		r2()
+		inFlight, _ = b.Stats()
+		assert.Equal(t, 0, inFlight)
+	})
+
+	t.Run("異常系: キューが満杯の場合は即座に拒否する", func(t *testing.T) {
+		b := NewBulkhead(BulkheadSettings{Name: "test", MaxConcurrent: 1, MaxQueue: 0})
+		release, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		_, err = b.Acquire(context.Background())
+
+		var fullErr *BulkheadFullError
+		require.True(t, errors.As(err, &fullErr))
+		assert.False(t, fullErr.Queued)
+	})
+
+	t.Run("異常系: 待機中の呼び出しがある場合は空きがあっても割り込まない", func(t *testing.T) {
+		b := NewBulkhead(BulkheadSettings{Name: "test", MaxConcurrent: 1, MaxQueue: 1})
+		// 空きを取りに行く直前の待機者がいる状態
+		b.queued = 1
+
+		_, err := b.Acquire(context.Background())
+
+		var fullErr *BulkheadFullError
+		require.True(t, errors.As(err, &fullErr))
+		assert.False(t, fullErr.Queued)
+		inFlight, _ := b.Stats()
+		assert.Equal(t, 0, inFlight)
+	})
+
+	t.Run("正常系: 待機中の呼び出しは空きができると実行される", func(t *testing.T) {
+		var mu sync.Mutex
+		var maxQueued int
+		b := NewBulkhead(BulkheadSettings{
+			Name:          "test",
+			MaxConcurrent: 1,
+			MaxQueue:      1,
+			OnChange: func(_ string, _, queued int) {
+				mu.Lock()
+				defer mu.Unlock()
+				maxQueued = max(maxQueued, queued)
+			},
+		})
+		release, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			r, err := b.Acquire(context.Background())
+			if err == nil {
+				r()
+			}
+			done <- err
+		}()
+		assert.Eventually(t, func() bool {
+			_, queued := b.Stats()
+			return queued == 1
+		}, time.Second, time.Millisecond)
+
+		release()
+		assert.NoError(t, <-done)
+		mu.Lock()
+		assert.Equal(t, 1, maxQueued)
+		mu.Unlock()
+	})
+
+	t.Run("異常系: 最大待機時間を超えると拒否する", func(t *testing.T) {
+		b := NewBulkhead(BulkheadSettings{Name: "test", MaxConcurrent: 1, MaxQueue: 1, MaxWait: 10 * time.Millisecond})
+		release, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		_, err = b.Acquire(context.Background())
+
+		var fullErr *BulkheadFullError
+		require.True(t, errors.As(err, &fullErr))
+		assert.True(t, fullErr.Queued)
+		_, queued := b.Stats()
+		assert.Equal(t, 0, queued)
+	})
+}
@@ -0,0 +1,203 @@
+package resilience
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State はサーキットブレーカーの状態を表します
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// CircuitBreakerSettings はサーキットブレーカーの設定です
+type CircuitBreakerSettings struct {
+	Name string
+	// FailureRateThreshold 以上の失敗率（0〜1）で open に遷移します
+	FailureRateThreshold float64
+	// MinimumRequests 未満のリクエスト数では失敗率を評価しません
+	MinimumRequests int
+	// Window は closed 状態で失敗率を集計する期間です
+	Window time.Duration
+	// OpenTimeout は open から half-open に遷移するまでの時間です
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests は half-open 状態で許可する試行数です
+	// この数だけ連続で成功すると closed に戻ります
+	HalfOpenMaxRequests int
+	// OnStateChange は状態遷移時に呼ばれます（ロック保持中に呼ばれるため、ブレーカーを操作しないこと）
+	OnStateChange func(name string, from, to State)
+}
+
+// OpenError はブレーカーが呼び出しを遮断したことを表します
+type OpenError struct {
+	Name       string
+	State      State
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %q is %s", e.Name, e.State)
+}
+
+// CircuitBreaker は失敗率に基づいて呼び出しを遮断します
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu                   sync.Mutex
+	state                State
+	generation           uint64
+	requests             int
+	failures             int
+	consecutiveSuccesses int
+	halfOpenInFlight     int
+	windowStart          time.Time
+	openedAt             time.Time
+}
+
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.HalfOpenMaxRequests < 1 {
+		settings.HalfOpenMaxRequests = 1
+	}
+	cb := &CircuitBreaker{
+		settings: settings,
+		now:      time.Now,
+		state:    StateClosed,
+	}
+	cb.windowStart = cb.now()
+	return cb
+}
+
+// Name はブレーカー名を返します
+func (cb *CircuitBreaker) Name() string {
+	return cb.settings.Name
+}
+
+// State は現在の状態を返します
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.currentState(cb.now())
+}
+
+// Allow は呼び出し可否を判定します
+// 許可された場合は、呼び出し結果を報告する done を必ず呼び出してください
+func (cb *CircuitBreaker) Allow() (done func(success bool), err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	switch cb.currentState(now) {
+	case StateOpen:
+		return nil, &OpenError{
+			Name:       cb.settings.Name,
+			State:      StateOpen,
+			RetryAfter: cb.openedAt.Add(cb.settings.OpenTimeout).Sub(now),
+		}
+	case StateHalfOpen:
+		if cb.halfOpenInFlight >= cb.settings.HalfOpenMaxRequests {
+			return nil, &OpenError{
+				Name:       cb.settings.Name,
+				State:      StateHalfOpen,
+				RetryAfter: time.Second,
+			}
+		}
+		cb.halfOpenInFlight++
+	}
+
+	generation := cb.generation
+	return func(success bool) {
+		cb.onResult(generation, success)
+	}, nil
+}
+
+func (cb *CircuitBreaker) onResult(generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	state := cb.currentState(now)
+	// 呼び出し中に状態が変わった場合は結果を集計しない
+	if generation != cb.generation {
+		return
+	}
+
+	switch state {
+	case StateClosed:
+		cb.requests++
+		if !success {
+			cb.failures++
+		}
+		if cb.requests >= cb.settings.MinimumRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.settings.FailureRateThreshold {
+			cb.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		cb.halfOpenInFlight--
+		if !success {
+			cb.setState(StateOpen, now)
+			return
+		}
+		cb.consecutiveSuccesses++
+		if cb.consecutiveSuccesses >= cb.settings.HalfOpenMaxRequests {
+			cb.setState(StateClosed, now)
+		}
+	}
+}
+
+// currentState は時間経過による遷移を反映した状態を返します
+func (cb *CircuitBreaker) currentState(now time.Time) State {
+	switch cb.state {
+	case StateClosed:
+		if cb.settings.Window > 0 && now.Sub(cb.windowStart) >= cb.settings.Window {
+			cb.resetCounts(now)
+		}
+	case StateOpen:
+		if now.Sub(cb.openedAt) >= cb.settings.OpenTimeout {
+			cb.setState(StateHalfOpen, now)
+		}
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) setState(to State, now time.Time) {
+	from := cb.state
+	if from == to {
+		return
+	}
+	cb.state = to
+	cb.generation++
+	cb.resetCounts(now)
+	cb.halfOpenInFlight = 0
+	if to == StateOpen {
+		cb.openedAt = now
+	}
+	if cb.settings.OnStateChange != nil {
+		cb.settings.OnStateChange(cb.settings.Name, from, to)
+	}
+}
+
+func (cb *CircuitBreaker) resetCounts(now time.Time) {
+	cb.requests = 0
+	cb.failures = 0
+	cb.consecutiveSuccesses = 0
+	cb.windowStart = now
+}
@@ -0,0 +1,124 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCircuitBreaker(now *time.Time, transitions *[]string) *CircuitBreaker {
+	cb := NewCircuitBreaker(CircuitBreakerSettings{
+		Name:                 "test",
+		FailureRateThreshold: 0.5,
+		MinimumRequests:      4,
+		Window:               time.Minute,
+		OpenTimeout:          10 * time.Second,
+		HalfOpenMaxRequests:  2,
+		OnStateChange: func(_ string, from, to State) {
+			*transitions = append(*transitions, from.String()+"->"+to.String())
+		},
+	})
+	cb.now = func() time.Time { return *now }
+	cb.windowStart = *now
+	return cb
+}
+
+func call(t *testing.T, cb *CircuitBreaker, success bool) error {
+	t.Helper()
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+	done(success)
+	return nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("失敗率が閾値を超えると open になり即座に失敗する", func(t *testing.T) {
+		now := time.Now()
+		var transitions []string
+		cb := newTestCircuitBreaker(&now, &transitions)
+
+		assert.NoError(t, call(t, cb, true))
+		assert.NoError(t, call(t, cb, false))
+		assert.NoError(t, call(t, cb, true))
+		assert.Equal(t, StateClosed, cb.State())
+		assert.NoError(t, call(t, cb, false))
+
+		assert.Equal(t, StateOpen, cb.State())
+		now = now.Add(4 * time.Second)
+		err := call(t, cb, true)
+		var openErr *OpenError
+		if assert.True(t, errors.As(err, &openErr)) {
+			assert.Equal(t, 6*time.Second, openErr.RetryAfter)
+		}
+		assert.Equal(t, []string{"closed->open"}, transitions)
+	})
+
+	t.Run("最小リクエスト数未満では open にならない", func(t *testing.T) {
+		now := time.Now()
+		var transitions []string
+		cb := newTestCircuitBreaker(&now, &transitions)
+
+		for range 3 {
+			assert.NoError(t, call(t, cb, false))
+		}
+
+		assert.Equal(t, StateClosed, cb.State())
+	})
+
+	t.Run("集計期間を過ぎるとカウントがリセットされる", func(t *testing.T) {
+		now := time.Now()
+		var transitions []string
+		cb := newTestCircuitBreaker(&now, &transitions)
+
+		for range 3 {
+			assert.NoError(t, call(t, cb, false))
+		}
+		now = now.Add(time.Minute)
+		assert.NoError(t, call(t, cb, false))
+
+		assert.Equal(t, StateClosed, cb.State())
+	})
+
+	t.Run("half-open で連続成功すると closed に戻る", func(t *testing.T) {
+		now := time.Now()
+		var transitions []string
+		cb := newTestCircuitBreaker(&now, &transitions)
+		for range 4 {
+			_ = call(t, cb, false)
+		}
+
+		now = now.Add(10 * time.Second)
+		assert.Equal(t, StateHalfOpen, cb.State())
+
+		done1, err := cb.Allow()
+		assert.NoError(t, err)
+		done2, err := cb.Allow()
+		assert.NoError(t, err)
+		_, err = cb.Allow()
+		assert.Error(t, err, "half-open の試行数を超えた呼び出しは遮断される")
+		done1(true)
+		done2(true)
+
+		assert.Equal(t, StateClosed, cb.State())
+		assert.Equal(t, []string{"closed->open", "open->half_open", "half_open->closed"}, transitions)
+	})
+
+	t.Run("half-open で失敗すると再び open になる", func(t *testing.T) {
+		now := time.Now()
+		var transitions []string
+		cb := newTestCircuitBreaker(&now, &transitions)
+		for range 4 {
+			_ = call(t, cb, false)
+		}
+		now = now.Add(10 * time.Second)
+
+		assert.NoError(t, call(t, cb, false))
+
+		assert.Equal(t, StateOpen, cb.State())
+		assert.Equal(t, []string{"closed->open", "open->half_open", "half_open->open"}, transitions)
+	})
+}
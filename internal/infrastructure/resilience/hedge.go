@@ -0,0 +1,197 @@
+package resilience
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HedgeSettings はヘッジリクエストの設定です
+type HedgeSettings struct {
+	// Percentile は 2 本目を送るまでの待機時間に使うレイテンシのパーセンタイル（0〜1）です
+	Percentile float64
+	// MinDelay は待機時間の下限です
+	MinDelay time.Duration
+	// MinSamples 未満の計測数ではパーセンタイルが不安定なためヘッジしません
+	MinSamples int
+	// Budget はリクエスト数に対して追加で送ってよいヘッジの割合です（0.05 で 5%）
+	Budget float64
+	// MaxBurst は貯めておけるヘッジ数の上限です
+	MaxBurst float64
+}
+
+// HedgeOutcome は 1 回の呼び出しでヘッジが行われたかを表します
+type HedgeOutcome struct {
+	// Issued は 2 本目のリクエストを送ったことを表します
+	Issued bool
+	// Won は 2 本目のリクエストの結果を採用したことを表します
+	Won bool
+}
+
+// Hedger は遅い応答に備えて 2 本目のリクエストを送るかを判断します
+// 各リクエストで Budget 分のトークンが貯まり、ヘッジ 1 回でトークンを 1 消費するため、
+// 追加の負荷はリクエスト数の Budget 倍を超えません
+type Hedger struct {
+	settings HedgeSettings
+	latency  *LatencyTracker
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+func NewHedger(settings HedgeSettings) *Hedger {
+	if settings.MaxBurst < 1 {
+		settings.MaxBurst = 1
+	}
+	return &Hedger{
+		settings: settings,
+		latency:  NewLatencyTracker(256),
+	}
+}
+
+// Delay は 2 本目を送るまでの待機時間を返します。計測数が足りない場合は false を返します
+func (h *Hedger) Delay() (time.Duration, bool) {
+	if h.latency.Count() < h.settings.MinSamples {
+		return 0, false
+	}
+	return max(h.latency.Quantile(h.settings.Percentile), h.settings.MinDelay), true
+}
+
+func (h *Hedger) deposit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = math.Min(h.tokens+h.settings.Budget, h.settings.MaxBurst)
+}
+
+func (h *Hedger) withdraw() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+type hedgeResult[T any] struct {
+	value  T
+	err    error
+	hedged bool
+}
+
+// Hedge は fn を実行し、待機時間内に応答がなければ 2 本目を送って先に成功した結果を返します
+// 採用しなかった呼び出しはコンテキストをキャンセルします。fn は冪等である必要があります
+func Hedge[T any](ctx context.Context, h *Hedger, fn func(ctx context.Context) (T, error)) (T, HedgeOutcome, error) {
+	h.deposit()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult[T], 2)
+	launch := func(hedged bool) context.CancelFunc {
+		attemptCtx, attemptCancel := context.WithCancel(ctx)
+		go func() {
+			start := time.Now()
+			value, err := fn(attemptCtx)
+			if err == nil {
+				h.latency.Record(time.Since(start))
+			}
+			results <- hedgeResult[T]{value: value, err: err, hedged: hedged}
+		}()
+		return attemptCancel
+	}
+
+	var outcome HedgeOutcome
+	cancels := []context.CancelFunc{launch(false)}
+	defer func() {
+		for _, c := range cancels {
+			c()
+		}
+	}()
+
+	var timer <-chan time.Time
+	if delay, ok := h.Delay(); ok {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	pending := 1
+	for {
+		select {
+		case <-timer:
+			timer = nil
+			if h.withdraw() {
+				outcome.Issued = true
+				cancels = append(cancels, launch(true))
+				pending++
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				outcome.Won = res.hedged
+				return res.value, outcome, nil
+			}
+			// 実行中の呼び出しが残っていればその結果を待つ
+			// すべて失敗した場合は、まだヘッジしていなくても最後のエラーを返す（再試行は呼び出し元に任せる）
+			if pending == 0 {
+				return res.value, outcome, res.err
+			}
+		}
+	}
+}
+
+// LatencyTracker は直近の応答時間を保持し、パーセンタイルを計算します
+type LatencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func NewLatencyTracker(size int) *LatencyTracker {
+	return &LatencyTracker{
+		samples: make([]time.Duration, size),
+	}
+}
+
+// Record は応答時間を記録します。保持数を超えた場合は古いものから置き換えます
+func (t *LatencyTracker) Record(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples[t.next] = d
+	t.next = (t.next + 1) % len(t.samples)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// Count は保持している計測数を返します
+func (t *LatencyTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.full {
+		return len(t.samples)
+	}
+	return t.next
+}
+
+// Quantile は q（0〜1）パーセンタイルの応答時間を返します
+func (t *LatencyTracker) Quantile(q float64) time.Duration {
+	t.mu.Lock()
+	n := t.next
+	if t.full {
+		n = len(t.samples)
+	}
+	sorted := slices.Clone(t.samples[:n])
+	t.mu.Unlock()
+
+	if n == 0 {
+		return 0
+	}
+	slices.Sort(sorted)
+	i := int(math.Ceil(q*float64(n))) - 1
+	return sorted[min(max(i, 0), n-1)]
+}
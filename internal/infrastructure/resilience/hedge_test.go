@@ -0,0 +1,116 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWarmHedger(t *testing.T, budget float64, latency time.Duration) *Hedger {
+	t.Helper()
+	h := NewHedger(HedgeSettings{Percentile: 0.95, MinSamples: 10, Budget: budget, MaxBurst: 10})
+	for range 10 {
+		h.latency.Record(latency)
+	}
+	return h
+}
+
+func TestHedge(t *testing.T) {
+	t.Run("正常系: 計測数が足りない場合はヘッジしない", func(t *testing.T) {
+		h := NewHedger(HedgeSettings{Percentile: 0.95, MinSamples: 10, Budget: 1})
+		var calls atomic.Int32
+
+		v, outcome, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+			calls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			return 1, nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+		assert.False(t, outcome.Issued)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("正常系: 遅い場合は 2 本目を送り先に返った結果を使う", func(t *testing.T) {
+		h := newWarmHedger(t, 1, 5*time.Millisecond)
+		var calls atomic.Int32
+		cancelled := make(chan struct{})
+
+		v, outcome, err := Hedge(context.Background(), h, func(ctx context.Context) (int, error) {
+			if calls.Add(1) == 1 {
+				<-ctx.Done()
+				close(cancelled)
+				return 0, ctx.Err()
+			}
+			return 2, nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, v)
+		assert.True(t, outcome.Issued)
+		assert.True(t, outcome.Won)
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("primary request was not cancelled")
+		}
+	})
+
+	t.Run("正常系: 予算を使い切るとヘッジしない", func(t *testing.T) {
+		h := newWarmHedger(t, 0.05, time.Millisecond)
+		issued := 0
+
+		for range 40 {
+			_, outcome, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+				time.Sleep(3 * time.Millisecond)
+				return 1, nil
+			})
+			require.NoError(t, err)
+			if outcome.Issued {
+				issued++
+			}
+		}
+
+		assert.LessOrEqual(t, issued, 2)
+	})
+
+	t.Run("異常系: 失敗した場合はヘッジせずにエラーを返す", func(t *testing.T) {
+		h := newWarmHedger(t, 1, time.Second)
+		want := errors.New("failed")
+
+		_, outcome, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+			return 0, want
+		})
+
+		assert.ErrorIs(t, err, want)
+		assert.False(t, outcome.Issued)
+	})
+}
+
+func TestLatencyTracker(t *testing.T) {
+	t.Run("正常系: パーセンタイルを計算する", func(t *testing.T) {
+		tracker := NewLatencyTracker(100)
+		for i := 1; i <= 100; i++ {
+			tracker.Record(time.Duration(i) * time.Millisecond)
+		}
+
+		assert.Equal(t, 95*time.Millisecond, tracker.Quantile(0.95))
+		assert.Equal(t, 50*time.Millisecond, tracker.Quantile(0.5))
+	})
+
+	t.Run("正常系: 保持数を超えると古い計測から置き換える", func(t *testing.T) {
+		tracker := NewLatencyTracker(2)
+		tracker.Record(100 * time.Millisecond)
+		tracker.Record(time.Millisecond)
+		tracker.Record(time.Millisecond)
+
+		assert.Equal(t, 2, tracker.Count())
+		assert.Equal(t, time.Millisecond, tracker.Quantile(1))
+	})
+}
@@ -0,0 +1,172 @@
+package servertls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+)
+
+// クライアント証明書の要求方法です
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional" // 提示された場合のみ検証する
+	ClientAuthRequire  = "require"  // 検証できる証明書のない接続は拒否する
+)
+
+// NewConfig は tls_cert_file / tls_key_file のサーバー証明書で待ち受ける tls.Config を返します
+// tls_cert_file が空の場合は TLS を使わないため nil を返します
+// 証明書・鍵と CA バンドルは tls_reload_interval ごとに変更を確認し、読み込めた場合のみ新しい接続から反映します
+// 返す関数は変更の確認を停止し、終了を待ちます
+func NewConfig(cfg *config.AppConfig, logger logger.Logger) (*tls.Config, func(), error) {
+	if cfg.TLSCertFile == "" {
+		if cfg.TLSClientAuth != "" && cfg.TLSClientAuth != ClientAuthNone {
+			return nil, nil, errors.New("tls_client_auth requires tls_cert_file")
+		}
+		return nil, func() {}, nil
+	}
+	// time.NewTicker は 0 以下の間隔で panic するため、起動時に拒否する
+	if cfg.TLSReloadInterval <= 0 {
+		return nil, nil, fmt.Errorf("tls_reload_interval must be positive: %s", cfg.TLSReloadInterval)
+	}
+
+	clientAuth := tls.NoClientCert
+	switch cfg.TLSClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, nil, fmt.Errorf("unknown tls_client_auth: %q", cfg.TLSClientAuth)
+	}
+	caFile := ""
+	if clientAuth != tls.NoClientCert {
+		if cfg.TLSClientCAFile == "" {
+			return nil, nil, errors.New("tls_client_auth requires tls_client_ca_file")
+		}
+		caFile = cfg.TLSClientCAFile
+	}
+
+	r := &reloader{
+		logger:   logger,
+		certFile: cfg.TLSCertFile,
+		keyFile:  cfg.TLSKeyFile,
+		caFile:   caFile,
+	}
+	// 読み込み中の変更を見逃さないよう、読み込む前の状態を基準にする
+	last := r.fingerprint()
+	if err := r.load(); err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.watch(ctx, last, cfg.TLSReloadInterval)
+	}()
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// 接続ごとに最新の証明書と CA バンドルを使う
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := base.Clone()
+			c.Certificates = []tls.Certificate{*r.cert.Load()}
+			c.ClientCAs = r.clientCAs.Load()
+			return c, nil
+		},
+	}, stop, nil
+}
+
+type reloader struct {
+	logger   logger.Logger
+	certFile string
+	keyFile  string
+	caFile   string
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+}
+
+// load は証明書・鍵と CA バンドルを読み込みます。いずれかに失敗した場合は何も置き換えません
+func (r *reloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read tls client ca file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return errors.New("tls client ca file has no certificates")
+		}
+	}
+
+	r.cert.Store(&cert)
+	r.clientCAs.Store(pool)
+	return nil
+}
+
+// watch はファイルの更新時刻とサイズの変化を検知して読み込み直し、ctx が終了すると戻ります
+// 証明書と鍵を順に置き換える途中で読み込みに失敗した場合は、直前の証明書を使い続けて次の確認で再び試します
+func (r *reloader) watch(ctx context.Context, last string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := r.fingerprint()
+		if current == last {
+			continue
+		}
+		if err := r.load(); err != nil {
+			r.logger.Error("Failed to reload tls certificate, keeping previous one", "cert_file", r.certFile, "error", err)
+			continue
+		}
+		last = current
+		r.logger.Info("TLS certificate reloaded", "cert_file", r.certFile, "client_ca_file", r.caFile)
+	}
+}
+
+// fingerprint は監視するファイルの更新時刻とサイズを連結したものです
+func (r *reloader) fingerprint() string {
+	var s string
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			s += path + ":missing;"
+			continue
+		}
+		s += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return s
+}
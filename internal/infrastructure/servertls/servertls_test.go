@@ -0,0 +1,249 @@
+package servertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/config"
+	"github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert はテスト用の証明書と鍵です
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, isCA bool, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              []string{cn},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	if keyFile != "" {
+		der, err := x509.MarshalECPrivateKey(c.key)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// handshake は TLS で接続し、サーバーの証明書とサーバーが検証したクライアント証明書のチェーンの数を返します
+func handshake(t *testing.T, serverConfig *tls.Config, clientCert *testCert) (*x509.Certificate, int, error) {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	verified := make(chan int, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			verified <- -1
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			verified <- -1
+			return
+		}
+		verified <- len(tlsConn.ConnectionState().VerifiedChains)
+		b := make([]byte, 1)
+		if _, err := tlsConn.Read(b); err == nil {
+			_, _ = tlsConn.Write(b)
+		}
+	}()
+
+	clientConfig := &tls.Config{InsecureSkipVerify: true} // nolint:gosec
+	if clientCert != nil {
+		clientConfig.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", ln.Addr().String(), clientConfig)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+	// TLS 1.3 ではクライアント証明書の拒否はハンドシェイク後の最初の読み込みで分かるため、1 バイトを往復させる
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write([]byte{0}); err != nil {
+		return nil, 0, err
+	}
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return nil, 0, err
+	}
+	return conn.ConnectionState().PeerCertificates[0], <-verified, nil
+}
+
+func TestNewConfig(t *testing.T) {
+	log := logger.NewLogger(&config.AppConfig{})
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+
+	ca := newTestCert(t, "test-ca", nil, true, x509.ExtKeyUsageAny)
+	ca.write(t, caFile, "")
+	server := newTestCert(t, "localhost", ca, false, x509.ExtKeyUsageServerAuth)
+	server.write(t, certFile, keyFile)
+	client := newTestCert(t, "batch", ca, false, x509.ExtKeyUsageClientAuth)
+	stranger := newTestCert(t, "stranger", nil, false, x509.ExtKeyUsageClientAuth)
+
+	cfg := &config.AppConfig{
+		TLSCertFile:       certFile,
+		TLSKeyFile:        keyFile,
+		TLSReloadInterval: 20 * time.Millisecond,
+		TLSClientAuth:     ClientAuthNone,
+	}
+
+	t.Run("正常系: tls_cert_file が空の場合は TLS を使わない", func(t *testing.T) {
+		tlsConfig, stop, err := NewConfig(&config.AppConfig{TLSClientAuth: ClientAuthNone}, log)
+
+		require.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+		stop()
+	})
+
+	t.Run("異常系: クライアント証明書を要求する場合は CA バンドルが必要", func(t *testing.T) {
+		c := *cfg
+		c.TLSClientAuth = ClientAuthRequire
+
+		_, _, err := NewConfig(&c, log)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系: tls_reload_interval が 0 以下の場合はエラー", func(t *testing.T) {
+		c := *cfg
+		c.TLSReloadInterval = -time.Second
+
+		_, _, err := NewConfig(&c, log)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("正常系: require では CA が署名したクライアント証明書のみ受け付ける", func(t *testing.T) {
+		c := *cfg
+		c.TLSClientAuth, c.TLSClientCAFile = ClientAuthRequire, caFile
+		tlsConfig, stop, err := NewConfig(&c, log)
+		require.NoError(t, err)
+		defer stop()
+
+		_, chains, err := handshake(t, tlsConfig, client)
+		require.NoError(t, err)
+		assert.Equal(t, 1, chains)
+
+		_, _, err = handshake(t, tlsConfig, stranger)
+		assert.Error(t, err)
+		_, _, err = handshake(t, tlsConfig, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("正常系: optional では証明書のない接続も受け付ける", func(t *testing.T) {
+		c := *cfg
+		c.TLSClientAuth, c.TLSClientCAFile = ClientAuthOptional, caFile
+		tlsConfig, stop, err := NewConfig(&c, log)
+		require.NoError(t, err)
+		defer stop()
+
+		_, chains, err := handshake(t, tlsConfig, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, chains)
+	})
+
+	t.Run("正常系: 証明書ファイルの変更を新しい接続から反映する", func(t *testing.T) {
+		dir := t.TempDir()
+		c := *cfg
+		c.TLSCertFile, c.TLSKeyFile = filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+		server.write(t, c.TLSCertFile, c.TLSKeyFile)
+		tlsConfig, stop, err := NewConfig(&c, log)
+		require.NoError(t, err)
+		defer stop()
+
+		got, _, err := handshake(t, tlsConfig, nil)
+		require.NoError(t, err)
+		assert.Equal(t, server.cert.SerialNumber, got.SerialNumber)
+
+		renewed := newTestCert(t, "localhost", ca, false, x509.ExtKeyUsageServerAuth)
+		renewed.write(t, c.TLSCertFile, c.TLSKeyFile)
+
+		assert.Eventually(t, func() bool {
+			got, _, err := handshake(t, tlsConfig, nil)
+			return err == nil && got.SerialNumber.Cmp(renewed.cert.SerialNumber) == 0
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("異常系: 読み込めない証明書に置き換えられた場合は直前の証明書を使い続ける", func(t *testing.T) {
+		dir := t.TempDir()
+		c := *cfg
+		c.TLSCertFile, c.TLSKeyFile = filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+		server.write(t, c.TLSCertFile, c.TLSKeyFile)
+		tlsConfig, stop, err := NewConfig(&c, log)
+		require.NoError(t, err)
+		defer stop()
+
+		require.NoError(t, os.WriteFile(c.TLSCertFile, []byte("broken"), 0o600))
+		time.Sleep(100 * time.Millisecond)
+
+		got, _, err := handshake(t, tlsConfig, nil)
+		require.NoError(t, err)
+		assert.Equal(t, server.cert.SerialNumber, got.SerialNumber)
+	})
+
+	t.Run("正常系: 停止した後は証明書ファイルの変更を反映しない", func(t *testing.T) {
+		dir := t.TempDir()
+		c := *cfg
+		c.TLSCertFile, c.TLSKeyFile = filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+		server.write(t, c.TLSCertFile, c.TLSKeyFile)
+		tlsConfig, stop, err := NewConfig(&c, log)
+		require.NoError(t, err)
+
+		stop()
+		newTestCert(t, "localhost", ca, false, x509.ExtKeyUsageServerAuth).write(t, c.TLSCertFile, c.TLSKeyFile)
+		time.Sleep(100 * time.Millisecond)
+
+		got, _, err := handshake(t, tlsConfig, nil)
+		require.NoError(t, err)
+		assert.Equal(t, server.cert.SerialNumber, got.SerialNumber)
+	})
+}
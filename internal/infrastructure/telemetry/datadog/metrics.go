@@ -209,7 +209,39 @@ func (m *MetricsManager) RecordHTTPMetrics(method, path string, statusCode int,
 		})
 	}
 
-	// メトリクスをバッファに追加
+	m.push(metrics...)
+}
+
+// Count はカウンターメトリクスを記録します
+func (m *MetricsManager) Count(name string, value float64, tags []string) {
+	m.record("count", name, value, tags)
+}
+
+// Gauge はゲージメトリクスを記録します
+func (m *MetricsManager) Gauge(name string, value float64, tags []string) {
+	m.record("gauge", name, value, tags)
+}
+
+// Histogram はヒストグラムメトリクスを記録します
+func (m *MetricsManager) Histogram(name string, value float64, tags []string) {
+	m.record("histogram", name, value, tags)
+}
+
+func (m *MetricsManager) record(metricType, name string, value float64, tags []string) {
+	if m.client == nil {
+		return
+	}
+	m.push(metricEvent{
+		metricType: metricType,
+		name:       name,
+		value:      value,
+		tags:       tags,
+		rate:       1.0,
+	})
+}
+
+// push メトリクスをバッファに追加
+func (m *MetricsManager) push(metrics ...metricEvent) {
 	for _, metric := range metrics {
 		select {
 		case m.metricsBuffer <- metric:
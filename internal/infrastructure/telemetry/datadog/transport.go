@@ -0,0 +1,130 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type spanOptionsContextKey struct{}
+
+// WithOutboundSpanOptions は Transport が作成するクライアント span に追加するオプションをコンテキストに設定します
+// GraphQL のオペレーション名など、リクエストからは分からない情報を span に載せるために利用します
+func WithOutboundSpanOptions(ctx context.Context, opts ...tracer.StartSpanOption) context.Context {
+	if existing, ok := ctx.Value(spanOptionsContextKey{}).([]tracer.StartSpanOption); ok {
+		opts = append(append([]tracer.StartSpanOption{}, existing...), opts...)
+	}
+	return context.WithValue(ctx, spanOptionsContextKey{}, opts)
+}
+
+// Transport は外部呼び出しごとにクライアント span を作成し、トレースコンテキストをヘッダーに伝播する RoundTripper です
+// 伝播形式はトレーサーの設定に従います（デフォルトは Datadog と W3C traceparent/tracestate）
+// span はレスポンスボディを読み終えるか閉じた時点で終了し、転送時間も含めて記録します
+type Transport struct {
+	base      http.RoundTripper
+	component string
+}
+
+// NewTransport は component（piyographql など呼び出し元のクライアント名）を付けた span を記録する Transport を返します
+// peer.service には接続先のホスト名を設定します
+func NewTransport(base http.RoundTripper, component string) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:      base,
+		component: component,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	ctx := req.Context()
+
+	opts := []tracer.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeHTTP),
+		tracer.ResourceName(fmt.Sprintf("%s %s", req.Method, req.URL.Path)),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.Component, t.component),
+		tracer.Tag(ext.HTTPMethod, req.Method),
+		tracer.Tag(ext.HTTPURL, req.URL.String()),
+		tracer.Tag(ext.PeerService, req.URL.Hostname()),
+		tracer.Tag(ext.PeerHostname, req.URL.Hostname()),
+	}
+	if extra, ok := ctx.Value(spanOptionsContextKey{}).([]tracer.StartSpanOption); ok {
+		opts = append(opts, extra...)
+	}
+
+	span, ctx := tracer.StartSpanFromContext(ctx, "http.client.request", opts...)
+
+	// RoundTripper はリクエストを変更してはならないため、複製してからヘッダーを注入する
+	outReq := req.Clone(ctx)
+	if err := tracer.Inject(span.Context(), tracer.HTTPHeadersCarrier(outReq.Header)); err != nil {
+		span.SetTag("propagation.error", err.Error())
+	}
+
+	resp, err := t.base.RoundTrip(outReq)
+	if err != nil {
+		finishSpan(span, start, err)
+		return nil, err
+	}
+
+	span.SetTag(ext.HTTPCode, resp.StatusCode)
+	if resp.StatusCode >= 500 {
+		span.SetTag("error", true)
+		span.SetTag("error.message", fmt.Sprintf("HTTP %d", resp.StatusCode))
+		span.SetTag("error.type", "http_error")
+	}
+	if resp.Body == nil {
+		finishSpan(span, start, nil)
+		return resp, nil
+	}
+	resp.Body = &spanBody{ReadCloser: resp.Body, span: span, start: start}
+	return resp, nil
+}
+
+// finishSpan は http.duration（ナノ秒）を記録して span を終了します
+func finishSpan(span tracer.Span, start time.Time, err error) {
+	span.SetTag("http.duration", time.Since(start).Nanoseconds())
+	if err != nil {
+		span.Finish(tracer.WithError(err))
+		return
+	}
+	span.Finish()
+}
+
+// spanBody はボディを最後まで読むか閉じた時点で span を終了します
+type spanBody struct {
+	io.ReadCloser
+	span  tracer.Span
+	start time.Time
+	once  sync.Once
+}
+
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	switch {
+	case errors.Is(err, io.EOF):
+		b.finish(nil)
+	case err != nil:
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *spanBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish(nil)
+	return err
+}
+
+func (b *spanBody) finish(err error) {
+	b.once.Do(func() { finishSpan(b.span, b.start, err) })
+}
@@ -0,0 +1,131 @@
+package datadog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func TestTransport(t *testing.T) {
+	t.Run("正常系: トレースヘッダーを注入しクライアント span を記録する", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		var got http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+		ctx = WithOutboundSpanOptions(ctx, tracer.ResourceName("GetSample"), tracer.Tag("graphql.operation", "GetSample"))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/graphql", nil)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: NewTransport(nil, "piyographql")}).Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		parent.Finish()
+
+		// 元のリクエストは変更しない
+		assert.Empty(t, req.Header.Get(tracer.DefaultTraceIDHeader))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		span := spans[0]
+		assert.Equal(t, "http.client.request", span.OperationName())
+		assert.Equal(t, parent.Context().SpanID(), span.ParentID())
+		assert.Equal(t, "GetSample", span.Tag(ext.ResourceName))
+		assert.Equal(t, "GetSample", span.Tag("graphql.operation"))
+		assert.Equal(t, ext.SpanKindClient, span.Tag(ext.SpanKind))
+		assert.Equal(t, "piyographql", span.Tag(ext.Component))
+		assert.Equal(t, "127.0.0.1", span.Tag(ext.PeerService), "peer.service は接続先のホスト")
+		assert.Equal(t, http.StatusOK, span.Tag(ext.HTTPCode))
+		assert.IsType(t, int64(0), span.Tag("http.duration"))
+		assert.Nil(t, span.Tag(ext.Error))
+
+		assert.NotEmpty(t, got.Get(tracer.DefaultTraceIDHeader))
+		assert.NotEmpty(t, got.Get(tracer.DefaultParentIDHeader))
+	})
+
+	t.Run("正常系: span はレスポンスボディの転送時間を含む", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+			_, _ = w.Write([]byte("body"))
+		}))
+		defer server.Close()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/graphql", nil)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: NewTransport(nil, "piyographql")}).Do(req)
+		require.NoError(t, err)
+		assert.Empty(t, mt.FinishedSpans(), "ボディを読み終えるまで span は終了しない")
+
+		_, err = io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.GreaterOrEqual(t, spans[0].Tag("http.duration"), (50 * time.Millisecond).Nanoseconds())
+	})
+
+	t.Run("異常系: 5xx の場合は span をエラーにする", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/graphql", nil)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: NewTransport(nil, "piyographql")}).Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /graphql", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, http.StatusBadGateway, spans[0].Tag(ext.HTTPCode))
+		assert.Equal(t, true, spans[0].Tag(ext.Error))
+	})
+
+	t.Run("正常系: デフォルトのトレーサーでは W3C traceparent も注入する", func(t *testing.T) {
+		t.Setenv("DD_INSTRUMENTATION_TELEMETRY_ENABLED", "false")
+		tracer.Start(tracer.WithAgentAddr("127.0.0.1:1"), tracer.WithLogStartup(false))
+		defer tracer.Stop()
+
+		var got http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+		}))
+		defer server.Close()
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/graphql", nil)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: NewTransport(nil, "piyographql")}).Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.NotEmpty(t, got.Get(tracer.DefaultTraceIDHeader))
+		assert.NotEmpty(t, got.Get("traceparent"))
+		assert.NotEmpty(t, got.Get("tracestate"))
+	})
+}
@@ -0,0 +1,3 @@
+package mockdatasource
+
+//go:generate mockgen -package=mockdatasource -destination=./mock_datasource.go github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/datasource SampleDataSource
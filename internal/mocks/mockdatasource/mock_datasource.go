@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/adapters/secondary/datasource (interfaces: SampleDataSource)
+
+// Package mockdatasource is a generated GoMock package.
+package mockdatasource
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/NishimuraTakuya-nt/go-rest-clean-plane-chi/internal/core/domain/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSampleDataSource is a mock of SampleDataSource interface.
+type MockSampleDataSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockSampleDataSourceMockRecorder
+}
+
+// MockSampleDataSourceMockRecorder is the mock recorder for MockSampleDataSource.
+type MockSampleDataSourceMockRecorder struct {
+	mock *MockSampleDataSource
+}
+
+// NewMockSampleDataSource creates a new mock instance.
+func NewMockSampleDataSource(ctrl *gomock.Controller) *MockSampleDataSource {
+	mock := &MockSampleDataSource{ctrl: ctrl}
+	mock.recorder = &MockSampleDataSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSampleDataSource) EXPECT() *MockSampleDataSourceMockRecorder {
+	return m.recorder
+}
+
+// DeleteSample mocks base method.
+func (m *MockSampleDataSource) DeleteSample(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSample", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSample indicates an expected call of DeleteSample.
+func (mr *MockSampleDataSourceMockRecorder) DeleteSample(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSample", reflect.TypeOf((*MockSampleDataSource)(nil).DeleteSample), arg0, arg1)
+}
+
+// GetSample mocks base method.
+func (m *MockSampleDataSource) GetSample(arg0 context.Context, arg1 string) (*models.Sample, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSample", arg0, arg1)
+	ret0, _ := ret[0].(*models.Sample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSample indicates an expected call of GetSample.
+func (mr *MockSampleDataSourceMockRecorder) GetSample(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSample", reflect.TypeOf((*MockSampleDataSource)(nil).GetSample), arg0, arg1)
+}
+
+// ListSample mocks base method.
+func (m *MockSampleDataSource) ListSample(arg0 context.Context, arg1, arg2 *int) ([]models.Sample, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSample", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Sample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSample indicates an expected call of ListSample.
+func (mr *MockSampleDataSourceMockRecorder) ListSample(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSample", reflect.TypeOf((*MockSampleDataSource)(nil).ListSample), arg0, arg1, arg2)
+}
+
+// UpdateSample mocks base method.
+func (m *MockSampleDataSource) UpdateSample(arg0 context.Context, arg1 *models.Sample) (*models.Sample, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSample", arg0, arg1)
+	ret0, _ := ret[0].(*models.Sample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSample indicates an expected call of UpdateSample.
+func (mr *MockSampleDataSourceMockRecorder) UpdateSample(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSample", reflect.TypeOf((*MockSampleDataSource)(nil).UpdateSample), arg0, arg1)
+}
@@ -50,6 +50,33 @@ func (mr *MockClientMockRecorder) GetSample(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSample", reflect.TypeOf((*MockClient)(nil).GetSample), ctx, id)
 }
 
+// GetSamples mocks base method.
+func (m *MockClient) GetSamples(ctx context.Context, ids []string) ([]*models.Sample, []error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSamples", ctx, ids)
+	ret0, _ := ret[0].([]*models.Sample)
+	ret1, _ := ret[1].([]error)
+	return ret0, ret1
+}
+
+// GetSamples indicates an expected call of GetSamples.
+func (mr *MockClientMockRecorder) GetSamples(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSamples", reflect.TypeOf((*MockClient)(nil).GetSamples), ctx, ids)
+}
+
+// InvalidateSample mocks base method.
+func (m *MockClient) InvalidateSample(id string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateSample", id)
+}
+
+// InvalidateSample indicates an expected call of InvalidateSample.
+func (mr *MockClientMockRecorder) InvalidateSample(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateSample", reflect.TypeOf((*MockClient)(nil).InvalidateSample), id)
+}
+
 // ListSample mocks base method.
 func (m *MockClient) ListSample(ctx context.Context, offset, limit *int) ([]models.Sample, error) {
 	m.ctrl.T.Helper()
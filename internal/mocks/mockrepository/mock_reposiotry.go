@@ -35,6 +35,20 @@ func (m *MockSampleRepository) EXPECT() *MockSampleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockSampleRepository) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSampleRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSampleRepository)(nil).Delete), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockSampleRepository) Get(arg0 context.Context, arg1 string) (*models.Sample, error) {
 	m.ctrl.T.Helper()
@@ -64,3 +78,17 @@ func (mr *MockSampleRepositoryMockRecorder) List(arg0, arg1, arg2 interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSampleRepository)(nil).List), arg0, arg1, arg2)
 }
+
+// Save mocks base method.
+func (m *MockSampleRepository) Save(arg0 context.Context, arg1 *models.Sample) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockSampleRepositoryMockRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSampleRepository)(nil).Save), arg0, arg1)
+}
@@ -35,6 +35,36 @@ func (m *MockTokenService) EXPECT() *MockTokenServiceMockRecorder {
 	return m.recorder
 }
 
+// GenerateClientToken mocks base method.
+func (m *MockTokenService) GenerateClientToken(arg0 context.Context, arg1 string, arg2, arg3 []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateClientToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateClientToken indicates an expected call of GenerateClientToken.
+func (mr *MockTokenServiceMockRecorder) GenerateClientToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateClientToken", reflect.TypeOf((*MockTokenService)(nil).GenerateClientToken), arg0, arg1, arg2, arg3)
+}
+
+// GenerateMFAChallenge mocks base method.
+func (m *MockTokenService) GenerateMFAChallenge(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateMFAChallenge", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateMFAChallenge indicates an expected call of GenerateMFAChallenge.
+func (mr *MockTokenServiceMockRecorder) GenerateMFAChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateMFAChallenge", reflect.TypeOf((*MockTokenService)(nil).GenerateMFAChallenge), arg0, arg1)
+}
+
 // GenerateToken mocks base method.
 func (m *MockTokenService) GenerateToken(arg0 context.Context, arg1 string, arg2 []string) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +80,21 @@ func (mr *MockTokenServiceMockRecorder) GenerateToken(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockTokenService)(nil).GenerateToken), arg0, arg1, arg2)
 }
 
+// ValidateMFAChallenge mocks base method.
+func (m *MockTokenService) ValidateMFAChallenge(arg0 context.Context, arg1 string) (*models.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateMFAChallenge", arg0, arg1)
+	ret0, _ := ret[0].(*models.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateMFAChallenge indicates an expected call of ValidateMFAChallenge.
+func (mr *MockTokenServiceMockRecorder) ValidateMFAChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateMFAChallenge", reflect.TypeOf((*MockTokenService)(nil).ValidateMFAChallenge), arg0, arg1)
+}
+
 // ValidateToken mocks base method.
 func (m *MockTokenService) ValidateToken(arg0 context.Context, arg1 string) (*models.Claims, error) {
 	m.ctrl.T.Helper()